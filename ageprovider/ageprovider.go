@@ -0,0 +1,150 @@
+// Package ageprovider reads configstore items from age encrypted files (see https://age-encryption.org).
+//
+// The files contain the usual YAML item list, encrypted with the age CLI, e.g.:
+//
+//	age -r age1... -o config.yaml.age config.yaml
+//
+// Importing this package also registers the "age" and "age+refresh" provider factories, for InitFromEnvironment:
+//
+//	CONFIGURATION_FROM=age:/etc/config.yaml.age
+//
+// in which case the identities are read from the AGE_SECRET_KEY environment variable,
+// or from the file named by AGE_IDENTITY_FILE.
+package ageprovider
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/ovh/configstore"
+)
+
+const (
+	// SecretKeyEnv is the environment variable holding the identities, see IdentitiesFromEnv.
+	SecretKeyEnv = "AGE_SECRET_KEY"
+	// IdentityFileEnv is the environment variable holding the path of an identity file, see IdentitiesFromEnv.
+	IdentityFileEnv = "AGE_IDENTITY_FILE"
+)
+
+func init() {
+	configstore.RegisterProviderFactory("age", factory("age", File))
+	configstore.RegisterProviderFactory("age+refresh", factory("age+refresh", FileRefresh))
+}
+
+func factory(name string, f func(*configstore.Store, string, ...age.Identity)) func(*configstore.Store, string) {
+	return func(s *configstore.Store, filename string) {
+		identities, err := IdentitiesFromEnv()
+		if err != nil {
+			s.ErrorProvider(fmt.Sprintf("%s:%s", name, filename), err)
+			return
+		}
+		f(s, filename, identities...)
+	}
+}
+
+// File registers on the store a provider reading the age encrypted file given in parameter (static content),
+// decrypted with the given identities. Both binary and armored files are supported.
+// If the file cannot be decrypted, the provider returns an error, which lists the types of the recipients
+// the file was encrypted to.
+//
+// A file can be encrypted to several recipients, e.g. both a developer key and an ops key, in which case any of the
+// matching identities decrypts it.
+func File(s *configstore.Store, filename string, identities ...age.Identity) {
+	s.FileCustom(filename, decoder(identities))
+}
+
+// FileRefresh is similar to File, but the file is watched for modifications (see Store.FileRefresh):
+// the file is decrypted again on every change, and the watchers of the store get notified.
+func FileRefresh(s *configstore.Store, filename string, identities ...age.Identity) {
+	s.FileCustomRefresh(filename, decoder(identities))
+}
+
+func decoder(identities []age.Identity) func([]byte) ([]configstore.Item, error) {
+	decode := configstore.DecodeItems(configstore.DefaultFilePriority)
+	return func(b []byte) ([]configstore.Item, error) {
+		plain, err := decrypt(b, identities)
+		if err != nil {
+			return nil, err
+		}
+		return decode(plain)
+	}
+}
+
+// IdentitiesFromEnv parses the identities held by the AGE_SECRET_KEY environment variable,
+// or by the file named by AGE_IDENTITY_FILE.
+func IdentitiesFromEnv() ([]age.Identity, error) {
+	if key := os.Getenv(SecretKeyEnv); key != "" {
+		return ParseIdentities(strings.NewReader(key))
+	}
+	if filename := os.Getenv(IdentityFileEnv); filename != "" {
+		return IdentitiesFromFile(filename)
+	}
+	return nil, fmt.Errorf("ageprovider: no identity: neither %s nor %s is set", SecretKeyEnv, IdentityFileEnv)
+}
+
+// IdentitiesFromFile parses the identities held by a key file, as generated by age-keygen.
+func IdentitiesFromFile(filename string) ([]age.Identity, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ageprovider: %v", err)
+	}
+	defer f.Close()
+	return ParseIdentities(f)
+}
+
+// ParseIdentities parses age identities, one per line. Empty lines and comments (#) are ignored.
+func ParseIdentities(r io.Reader) ([]age.Identity, error) {
+	identities, err := age.ParseIdentities(r)
+	if err != nil {
+		return nil, fmt.Errorf("ageprovider: %v", err)
+	}
+	return identities, nil
+}
+
+func decrypt(b []byte, identities []age.Identity) ([]byte, error) {
+	var r io.Reader = bytes.NewReader(b)
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte(armor.Header)) {
+		r = armor.NewReader(r)
+	}
+	d, err := age.Decrypt(r, identities...)
+	if err != nil {
+		if recipients := recipientTypes(b); len(recipients) > 0 {
+			return nil, fmt.Errorf("ageprovider: decrypt: %v (file encrypted to: %s)", err, strings.Join(recipients, ", "))
+		}
+		return nil, fmt.Errorf("ageprovider: decrypt: %v", err)
+	}
+	plain, err := io.ReadAll(d)
+	if err != nil {
+		return nil, fmt.Errorf("ageprovider: decrypt: %v", err)
+	}
+	return plain, nil
+}
+
+// Returns the types of the recipient stanzas of the file header, e.g. X25519 or scrypt.
+// age does not record the recipients themselves, only how the file key was wrapped for each of them.
+func recipientTypes(b []byte) []string {
+	var r io.Reader = bytes.NewReader(b)
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte(armor.Header)) {
+		r = armor.NewReader(r)
+	}
+	var types []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "---") {
+			break
+		}
+		if strings.HasPrefix(line, "-> ") {
+			if fields := strings.Fields(line[3:]); len(fields) > 0 {
+				types = append(types, fields[0])
+			}
+		}
+	}
+	return types
+}
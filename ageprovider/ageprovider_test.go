@@ -0,0 +1,128 @@
+package ageprovider
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const config = "- key: password\n  value: s3cr3t\n  priority: 1\n"
+
+func encrypt(t *testing.T, armored bool, recipients ...age.Recipient) []byte {
+	buf := &bytes.Buffer{}
+	var out io.Writer = buf
+	var a io.WriteCloser
+	if armored {
+		a = armor.NewWriter(buf)
+		out = a
+	}
+	w, err := age.Encrypt(out, recipients...)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(config))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	if a != nil {
+		require.NoError(t, a.Close())
+	}
+	return buf.Bytes()
+}
+
+func TestFile(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	for _, armored := range []bool{false, true} {
+		filename := filepath.Join(t.TempDir(), "config.yaml.age")
+		require.NoError(t, os.WriteFile(filename, encrypt(t, armored, id.Recipient()), 0600))
+
+		s := configstore.NewStore()
+		File(s, filename, id)
+		v, err := s.GetItemValue("password")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", v)
+
+		s = configstore.NewStore()
+		File(s, filename, other)
+		_, err = s.GetItemList()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "file encrypted to: X25519")
+	}
+}
+
+func TestIdentitiesFromEnv(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	t.Setenv(SecretKeyEnv, "")
+	t.Setenv(IdentityFileEnv, "")
+	_, err = IdentitiesFromEnv()
+	assert.Error(t, err)
+
+	keyfile := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(keyfile, []byte("# created: now\n"+id.String()+"\n"), 0600))
+	t.Setenv(IdentityFileEnv, keyfile)
+	ids, err := IdentitiesFromEnv()
+	require.NoError(t, err)
+	assert.Len(t, ids, 1)
+
+	t.Setenv(SecretKeyEnv, id.String())
+	ids, err = IdentitiesFromEnv()
+	require.NoError(t, err)
+	assert.Len(t, ids, 1)
+}
+
+func TestFileMultipleRecipients(t *testing.T) {
+	dev, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	ops, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "config.yaml.age")
+	require.NoError(t, os.WriteFile(filename, encrypt(t, false, dev.Recipient(), ops.Recipient()), 0600))
+
+	for _, id := range []age.Identity{dev, ops} {
+		s := configstore.NewStore()
+		File(s, filename, id)
+		v, err := s.GetItemValue("password")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", v)
+	}
+}
+
+func TestFileRefresh(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	filename := filepath.Join(t.TempDir(), "config.yaml.age")
+	require.NoError(t, os.WriteFile(filename, encrypt(t, false, id.Recipient()), 0600))
+
+	s := configstore.NewStore()
+	defer s.Close()
+	FileRefresh(s, filename, id)
+	v, err := s.GetItemValue("password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", v)
+
+	buf := &bytes.Buffer{}
+	w, err := age.Encrypt(buf, id.Recipient())
+	require.NoError(t, err)
+	_, err = w.Write([]byte("- key: password\n  value: rotated\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, os.WriteFile(filename, buf.Bytes(), 0600))
+
+	require.Eventually(t, func() bool {
+		v, err := s.GetItemValue("password")
+		return err == nil && v == "rotated"
+	}, 5*time.Second, 20*time.Millisecond)
+}
@@ -0,0 +1,157 @@
+package configstore
+
+import (
+	"time"
+)
+
+type alias struct {
+	from     string
+	to       string
+	warn     bool
+	active   bool
+	deadline time.Time
+}
+
+// DeprecationEntry describes a key rename, see RenameKey.
+type DeprecationEntry struct {
+	From     string
+	To       string
+	Deadline time.Time
+	// InUse reports whether the old key is currently resolved to the new one, i.e. whether it was not provided
+	// by any provider at the last load of the configuration.
+	InUse bool
+}
+
+type deprecation struct {
+	message string
+	warned  bool
+}
+
+// Alias makes the key "from" resolve to the items of the key "to", when "from" is not provided by any provider.
+// If both keys exist, the real "from" items take precedence.
+// This is useful to keep an old key name working after a rename.
+func (s *Store) Alias(from, to string) {
+	s.addAlias(from, to, false)
+}
+
+// AliasWithWarning is similar to Alias, but logs an informational message every time the alias becomes
+// in use, so that operators know that a deprecated key name is being relied on.
+func (s *Store) AliasWithWarning(from, to string) {
+	s.addAlias(from, to, true)
+}
+
+// AddAlias makes oldKey and newKey equivalent during a rename: each of them resolves to the items of the other one
+// when it is not provided by any provider, so that both the old call sites and the old configuration files keep working.
+// If both keys exist, each one keeps its own items. See also Deprecate.
+func (s *Store) AddAlias(oldKey, newKey string) {
+	s.addAlias(oldKey, newKey, false)
+	s.addAlias(newKey, oldKey, false)
+}
+
+// RenameKey keeps the old key "from" working after it was renamed to "to", until the deprecation deadline:
+// similarly to AliasWithWarning, "from" resolves to the items of "to" when it is not provided by any provider,
+// and a warning is logged when the old key is being relied on. Past the deadline, "from" is no longer resolved,
+// and looking it up fails as for any missing key, unless it is still provided as a real item.
+// See DeprecationReport to list the renames still in their compatibility period.
+func (s *Store) RenameKey(from, to string, deprecationDeadline time.Time) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.aliases = append(s.aliases, &alias{from: transformKey(from), to: transformKey(to), warn: true, deadline: deprecationDeadline})
+	s.NotifyWatchers()
+}
+
+// DeprecationReport lists the renames whose deadline has not passed yet, in registration order. See RenameKey.
+func (s *Store) DeprecationReport() []DeprecationEntry {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	var ret []DeprecationEntry
+	for _, a := range s.aliases {
+		if a.deadline.IsZero() || a.expired(s.clock()) {
+			continue
+		}
+		ret = append(ret, DeprecationEntry{From: a.from, To: a.to, Deadline: a.deadline, InUse: a.active})
+	}
+	return ret
+}
+
+func (a *alias) expired(now time.Time) bool {
+	return !a.deadline.IsZero() && !now.Before(a.deadline)
+}
+
+// Returns the current time, which can be mocked in the tests.
+func (s *Store) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// Deprecate logs a warning with the given message, through the logger of the store (see SetLogger and LogInfoFunc),
+// the first time the key is provided by a provider. The warning is logged at most once.
+func (s *Store) Deprecate(key, message string) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	if s.deprecations == nil {
+		s.deprecations = map[string]*deprecation{}
+	}
+	s.deprecations[transformKey(key)] = &deprecation{message: message}
+	s.NotifyWatchers()
+}
+
+// Warns about the deprecated keys present in the item list, once per key.
+// Must be called with s.pMut held.
+func (s *Store) warnDeprecated(l *ItemList) {
+	if len(s.deprecations) == 0 {
+		return
+	}
+	for _, it := range l.Items {
+		d, ok := s.deprecations[it.key]
+		if !ok || d.warned {
+			continue
+		}
+		d.warned = true
+		s.logInfo("configstore: deprecated key: "+d.message, "key", it.key, "provider", it.source)
+	}
+}
+
+func (s *Store) addAlias(from, to string, warn bool) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.aliases = append(s.aliases, &alias{from: transformKey(from), to: transformKey(to), warn: warn})
+	s.NotifyWatchers()
+}
+
+// Adds the items of the aliased keys to the (non-indexed) item list.
+// Aliases are resolved in registration order, so an alias can target another alias' key.
+// Must be called with s.pMut held.
+func (s *Store) resolveAliases(l *ItemList) {
+	if len(s.aliases) == 0 {
+		return
+	}
+	present := map[string]bool{}
+	for _, it := range l.Items {
+		present[it.key] = true
+	}
+	now := s.clock()
+	for _, a := range s.aliases {
+		if present[a.from] || !present[a.to] || a.expired(now) {
+			a.active = false
+			continue
+		}
+		for _, it := range l.Items {
+			if it.key == a.to {
+				it.key = a.from
+				l.Items = append(l.Items, it)
+			}
+		}
+		present[a.from] = true
+		if a.warn && !a.active {
+			if a.deadline.IsZero() {
+				s.logInfo("configstore: key is not defined, using its alias instead", "key", a.from, "alias", a.to)
+			} else {
+				s.logInfo("configstore: deprecated key: renamed, use the new key instead", "key", a.from, "renamed_to", a.to, "deadline", a.deadline.Format(time.RFC3339))
+			}
+		}
+		a.active = true
+	}
+}
@@ -0,0 +1,150 @@
+package configstore
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlias(t *testing.T) {
+	var logged []string
+	defer func(f func(string, ...interface{})) { LogInfoFunc = f }(LogInfoFunc)
+	LogInfoFunc = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	s := NewStore()
+	s.InMemory("new").Add(NewItem("database-host", "new-host", 1))
+	s.AliasWithWarning("db-host", "database-host")
+
+	// the alias resolves to the target key
+	v, err := s.GetItemValue("db_host")
+	require.NoError(t, err)
+	assert.Equal(t, "new-host", v)
+	_, err = s.GetItemValue("db_host")
+	require.NoError(t, err)
+	assert.Len(t, logged, 1, "warning must fire once per activation")
+
+	// the direct key takes precedence
+	s.InMemory("old").Add(NewItem("db-host", "old-host", 1))
+	v, err = s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "old-host", v)
+	assert.Len(t, logged, 1)
+
+	// the alias gets activated again once the direct key disappears
+	s.UnregisterProvider("old")
+	v, err = s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "new-host", v)
+	assert.Len(t, logged, 2)
+}
+
+func TestAliasMissingTarget(t *testing.T) {
+	s := NewStore()
+	s.Alias("from", "to")
+	_, err := s.GetItem("from")
+	assert.IsType(t, ErrItemNotFound(""), err)
+}
+
+func TestAddAlias(t *testing.T) {
+	s := NewStore()
+	s.AddAlias("db-host", "database-host")
+
+	// old configuration file, new call site
+	old := s.InMemory("old").Add(NewItem("DB_HOST", "old-host", 1))
+	v, err := s.GetItemValue("database-host")
+	require.NoError(t, err)
+	assert.Equal(t, "old-host", v)
+
+	// new configuration file, old call site
+	s.UnregisterProvider("old")
+	s.InMemory("new").Add(NewItem("database-host", "new-host", 1))
+	v, err = s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "new-host", v)
+
+	old.Add(NewItem("other", "x", 1))
+	_, err = s.GetItemValue("other")
+	assert.Error(t, err)
+}
+
+func TestDeprecate(t *testing.T) {
+	var logged []string
+	defer func(f func(string, ...interface{})) { LogInfoFunc = f }(LogInfoFunc)
+	LogInfoFunc = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	s := NewStore()
+	s.AddAlias("db-host", "database-host")
+	s.Deprecate("db-host", "use database-host instead")
+
+	s.InMemory("new").Add(NewItem("database-host", "new-host", 1))
+	_, err := s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Empty(t, logged, "a key resolved through an alias is not present in the configuration")
+
+	s.InMemory("old").Add(NewItem("db-host", "old-host", 1))
+	for i := 0; i < 3; i++ {
+		_, err = s.GetItemList()
+		require.NoError(t, err)
+	}
+	require.Len(t, logged, 1, "the warning must fire at most once")
+	assert.Contains(t, logged[0], "use database-host instead")
+	assert.Contains(t, logged[0], "key=db-host")
+}
+
+func TestRenameKey(t *testing.T) {
+	var logged []string
+	defer func(f func(string, ...interface{})) { LogInfoFunc = f }(LogInfoFunc)
+	LogInfoFunc = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deadline := now.Add(24 * time.Hour)
+	s := NewStore()
+	s.now = func() time.Time { return now }
+	s.InMemory("new").Add(NewItem("database-host", "new-host", 1))
+	s.RenameKey("db_host", "database_host", deadline)
+	s.Alias("other", "database-host")
+
+	report := s.DeprecationReport()
+	require.Len(t, report, 1, "plain aliases are not renames")
+	assert.Equal(t, DeprecationEntry{From: "db-host", To: "database-host", Deadline: deadline}, report[0])
+
+	v, err := s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "new-host", v)
+	require.Len(t, logged, 1)
+	assert.Contains(t, logged[0], "deprecated key")
+	assert.True(t, s.DeprecationReport()[0].InUse)
+
+	// the old key takes precedence while it still exists
+	s.InMemory("old").Add(NewItem("db-host", "old-host", 1))
+	v, err = s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "old-host", v)
+	assert.False(t, s.DeprecationReport()[0].InUse)
+	s.UnregisterProvider("old")
+
+	// past the deadline, the old key fails as any missing key
+	now = deadline
+	_, err = s.GetItemValue("db-host")
+	var notFound ErrItemNotFound
+	assert.True(t, errors.As(err, &notFound), "unexpected error: %v", err)
+	assert.Empty(t, s.DeprecationReport())
+	v, err = s.GetItemValue("other")
+	require.NoError(t, err)
+	assert.Equal(t, "new-host", v, "plain aliases do not expire")
+
+	s.InMemory("old").Add(NewItem("db-host", "old-host", 1))
+	v, err = s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "old-host", v)
+}
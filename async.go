@@ -0,0 +1,103 @@
+package configstore
+
+import (
+	"context"
+	"sync"
+)
+
+type asyncLoading struct {
+	wg      sync.WaitGroup
+	mut     sync.Mutex
+	pending int
+	errs    map[string]error
+}
+
+// RegisterProviderAsync registers a provider whose items are loaded once, in its own goroutine,
+// so that several slow providers (e.g. remote sources) are loaded concurrently rather than one after the other.
+// Until all the asynchronous providers are loaded, reading the configuration blocks and watchers are not notified;
+// they are notified once when the last one completes. See WaitReady.
+func (s *Store) RegisterProviderAsync(name string, fn Provider) {
+	a := &s.async
+	a.mut.Lock()
+	a.pending++
+	a.mut.Unlock()
+	a.wg.Add(1)
+
+	r := &asyncProvider{done: make(chan struct{})}
+	s.RegisterProvider(name, r.Items)
+
+	go func() {
+		defer a.wg.Done()
+		l, err := fn()
+		r.items, r.err = l, err
+		close(r.done)
+		if err != nil {
+			s.logError(err, "provider", name)
+			s.observeError(name, err)
+		} else {
+			s.observeReload(name, len(l.Items))
+		}
+
+		a.mut.Lock()
+		if err != nil {
+			if a.errs == nil {
+				a.errs = map[string]error{}
+			}
+			a.errs[name] = err
+		}
+		a.pending--
+		ready := a.pending == 0
+		a.mut.Unlock()
+		if ready {
+			s.NotifyWatchers()
+		}
+	}()
+}
+
+// WaitReady blocks until all the providers registered with RegisterProviderAsync are loaded, or the context is done.
+// If some providers failed, it returns an *AsyncLoadError holding the error of each of them.
+func (s *Store) WaitReady(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.async.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.async.mut.Lock()
+	defer s.async.mut.Unlock()
+	if len(s.async.errs) == 0 {
+		return nil
+	}
+	errs := make(map[string]error, len(s.async.errs))
+	for n, err := range s.async.errs {
+		errs[n] = err
+	}
+	return &AsyncLoadError{Errors: errs}
+}
+
+// Reports whether asynchronous providers are still loading, in which case watchers should not be notified.
+func (s *Store) asyncLoading() bool {
+	s.async.mut.Lock()
+	defer s.async.mut.Unlock()
+	return s.async.pending > 0
+}
+
+// The provider registered by RegisterProviderAsync, blocking until its items are loaded.
+type asyncProvider struct {
+	items ItemList
+	err   error
+	done  chan struct{}
+}
+
+func (r *asyncProvider) Items() (ItemList, error) {
+	<-r.done
+	if r.err != nil {
+		return ItemList{}, r.err
+	}
+	return ItemList{Items: append([]Item(nil), r.items.Items...)}, nil
+}
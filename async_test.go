@@ -0,0 +1,66 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func delayedProvider(delay time.Duration, err error, items ...Item) Provider {
+	return func() (ItemList, error) {
+		time.Sleep(delay)
+		return ItemList{Items: items}, err
+	}
+}
+
+func TestStoreRegisterProviderAsync(t *testing.T) {
+	s := NewStore()
+	ch := s.Watch()
+
+	start := time.Now()
+	s.RegisterProviderAsync("a", delayedProvider(100*time.Millisecond, nil, NewItem("a", "1", 1)))
+	s.RegisterProviderAsync("b", delayedProvider(150*time.Millisecond, nil, NewItem("b", "2", 1)))
+	s.RegisterProviderAsync("c", delayedProvider(200*time.Millisecond, nil, NewItem("c", "3", 1)))
+
+	select {
+	case <-ch:
+		t.Fatal("watchers must not be notified while providers are loading")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, s.WaitReady(ctx))
+
+	require.NoError(t, s.WaitReady(context.Background()))
+	elapsed := time.Since(start)
+	assert.True(t, elapsed < 400*time.Millisecond, "startup took %s, providers should load concurrently", elapsed)
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("watchers must be notified once providers are loaded")
+	}
+	for k, v := range map[string]string{"a": "1", "b": "2", "c": "3"} {
+		assert.Equal(t, v, mustValue(must(s.GetItem(k)).(Item)))
+	}
+}
+
+func TestStoreRegisterProviderAsyncError(t *testing.T) {
+	s := NewStore()
+	s.RegisterProviderAsync("ok", delayedProvider(10*time.Millisecond, nil, NewItem("a", "1", 1)))
+	s.RegisterProviderAsync("vault", delayedProvider(20*time.Millisecond, errors.New("connection refused")))
+
+	err := s.WaitReady(context.Background())
+	var loadErr *AsyncLoadError
+	require.True(t, errors.As(err, &loadErr))
+	assert.Len(t, loadErr.Errors, 1)
+	assert.EqualError(t, err, "configstore: 1 provider(s) failed to load: vault: connection refused")
+
+	_, err = s.GetItemList()
+	assert.EqualError(t, err, "configstore: provider 'vault': connection refused")
+}
@@ -0,0 +1,128 @@
+package configstore
+
+import (
+	"encoding/json"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An AuditLogger records the reads of configuration items made through Get and GetFirst, along with the code
+// which made them, see EnableAuditLog. The methods are called synchronously, they should not block.
+type AuditLogger interface {
+	// LogRead is called on every read of key, with the package, file and line of the caller, and whether the item read
+	// is sensitive (see NewSecretItem). It is also called when the item cannot be retrieved.
+	LogRead(key, callerPackage, callerFile string, line int, isSensitive bool)
+}
+
+// A SourceAuditLogger is an AuditLogger also given the source of the items read (see Item.Source), e.g.
+// ContextOverrideSource for the values overridden in a context, see GetFromContext.
+type SourceAuditLogger interface {
+	AuditLogger
+	// LogReadSource is called in place of LogRead, with the source of the item read, empty if it cannot be retrieved.
+	LogReadSource(key, source, callerPackage, callerFile string, line int, isSensitive bool)
+}
+
+// EnableAuditLog sets the logger recording the calls of Get, GetFirst and GetFromContext, e.g. to keep track of the
+// code reading the secrets. The other getters are not recorded. Passing nil disables it.
+func (s *Store) EnableAuditLog(logger AuditLogger) {
+	s.auditMut.Lock()
+	defer s.auditMut.Unlock()
+	s.auditLogger = logger
+}
+
+// Records a read of key with the audit logger, if any. skip is the number of stack frames between the caller of
+// auditRead and the code to record as the caller.
+func (s *Store) auditRead(key string, i Item, skip int) {
+	s.auditMut.RLock()
+	logger := s.auditLogger
+	s.auditMut.RUnlock()
+	if logger == nil {
+		return
+	}
+
+	var pkg, file string
+	var line int
+	pcs := make([]uintptr, 1)
+	// skip runtime.Callers and auditRead itself
+	if runtime.Callers(skip+2, pcs) > 0 {
+		frame, _ := runtime.CallersFrames(pcs).Next()
+		pkg, file, line = funcPackage(frame.Function), frame.File, frame.Line
+	}
+	if l, ok := logger.(SourceAuditLogger); ok {
+		l.LogReadSource(key, i.Source(), pkg, file, line, i.IsSensitive())
+		return
+	}
+	logger.LogRead(key, pkg, file, line, i.IsSensitive())
+}
+
+// Returns the import path of the package of a fully qualified function name,
+// e.g. "github.com/ovh/configstore" for "github.com/ovh/configstore.(*Store).Get".
+// The dots of the last path element are escaped in the function names, e.g. "gopkg.in/yaml%2ev2.Unmarshal".
+func funcPackage(name string) string {
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		name = name[:slash+1+dot]
+	}
+	return strings.Replace(name, "%2e", ".", -1)
+}
+
+type fileAuditLogger struct {
+	path string
+	mut  sync.Mutex
+}
+
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	Key       string    `json:"key"`
+	Source    string    `json:"source,omitempty"`
+	Package   string    `json:"package"`
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	Sensitive bool      `json:"sensitive"`
+}
+
+// FileAuditLogger returns an AuditLogger appending the reads to the file at path, one JSON object per line,
+// with the time, key, source, package, file, line and sensitive fields. The file is created if needed, and opened for every
+// read, so that it can be rotated. The write errors are logged with LogErrorFunc.
+func FileAuditLogger(path string) AuditLogger {
+	return &fileAuditLogger{path: path}
+}
+
+func (l *fileAuditLogger) LogRead(key, callerPackage, callerFile string, line int, isSensitive bool) {
+	l.LogReadSource(key, "", callerPackage, callerFile, line, isSensitive)
+}
+
+func (l *fileAuditLogger) LogReadSource(key, source, callerPackage, callerFile string, line int, isSensitive bool) {
+	b, err := json.Marshal(auditEntry{
+		Time:      time.Now(),
+		Key:       key,
+		Source:    source,
+		Package:   callerPackage,
+		File:      callerFile,
+		Line:      line,
+		Sensitive: isSensitive,
+	})
+	if err == nil {
+		err = l.append(append(b, '\n'))
+	}
+	if err != nil && LogErrorFunc != nil {
+		LogErrorFunc("error: audit log: %v", err)
+	}
+}
+
+func (l *fileAuditLogger) append(b []byte) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,89 @@
+package configstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s := NewStore()
+	s.InMemory("test").Add(NewSecretItem("password", "hunter2", 0), NewItem("user", "admin", 0))
+	s.EnableAuditLog(FileAuditLogger(path))
+
+	_, err := s.Get("password")
+	_, file, line, _ := runtime.Caller(0)
+	require.NoError(t, err)
+	_, err = s.GetFirst("user")
+	require.NoError(t, err)
+	_, err = s.Get("missing")
+	assert.Error(t, err)
+	// the other getters are not recorded
+	_, err = s.GetItemValue("user")
+	require.NoError(t, err)
+
+	s.EnableAuditLog(nil)
+	_, err = s.Get("password")
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e auditEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "password", entries[0].Key)
+	assert.Equal(t, "test", entries[0].Source)
+	assert.Equal(t, file, entries[0].File)
+	assert.Equal(t, line-1, entries[0].Line)
+	assert.Equal(t, "github.com/ovh/configstore", entries[0].Package)
+	assert.True(t, entries[0].Sensitive)
+
+	assert.Equal(t, "user", entries[1].Key)
+	assert.Equal(t, line+2, entries[1].Line)
+	assert.False(t, entries[1].Sensitive)
+	assert.Equal(t, "missing", entries[2].Key)
+}
+
+type auditRecorder struct {
+	files []string
+	lines []int
+}
+
+func (r *auditRecorder) LogRead(key, callerPackage, callerFile string, line int, isSensitive bool) {
+	r.files = append(r.files, callerFile)
+	r.lines = append(r.lines, line)
+}
+
+func TestAuditLogDefaultStore(t *testing.T) {
+	r := &auditRecorder{}
+	EnableAuditLog(r)
+	defer EnableAuditLog(nil)
+
+	Get("configstore-audit-test")
+	_, file, line, _ := runtime.Caller(0)
+	GetFirst("configstore-audit-test")
+	assert.Equal(t, []string{file, file}, r.files)
+	assert.Equal(t, []int{line - 1, line + 1}, r.lines)
+}
+
+func TestFuncPackage(t *testing.T) {
+	assert.Equal(t, "github.com/ovh/configstore", funcPackage("github.com/ovh/configstore.(*Store).Get"))
+	assert.Equal(t, "github.com/ovh/configstore", funcPackage("github.com/ovh/configstore.Get"))
+	assert.Equal(t, "main", funcPackage("main.main"))
+	assert.Equal(t, "gopkg.in/yaml.v2", funcPackage("gopkg.in/yaml%2ev2.Unmarshal"))
+}
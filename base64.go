@@ -0,0 +1,70 @@
+package configstore
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// EnableBase64Decoding activates the transparent decoding of base64 item values, for the keys matching the given glob
+// pattern (see path.Match), e.g. "*_key" or "*_cert". It is meant for binary values, such as TLS private keys, which
+// YAML cannot hold as is. The pattern is normalized as the keys are, so "*_key" matches "tls-key".
+// The standard and URL-safe encodings are detected, with or without padding; whitespace (e.g. line breaks) is ignored.
+// The decoded bytes are returned by Item.Value (Item.ValueBytes decodes the value once more, and is not meant for these keys).
+// If a value cannot be decoded, accessing it returns an error instead of the encoded value.
+// It can be called several times, to decode the keys matching any of the patterns.
+func (s *Store) EnableBase64Decoding(keyPattern string) {
+	keyPattern = transformKey(keyPattern)
+	if _, err := path.Match(keyPattern, ""); err != nil {
+		s.logError(fmt.Errorf("configstore: base64 decoding: invalid key pattern '%s': %v", keyPattern, err))
+		return
+	}
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.base64Patterns = append(s.base64Patterns, keyPattern)
+	s.NotifyWatchers()
+}
+
+// Decodes the base64 values of the (non-indexed) item list, see EnableBase64Decoding.
+// Must be called with s.pMut held.
+func (s *Store) decodeBase64(l *ItemList) {
+	if len(s.base64Patterns) == 0 {
+		return
+	}
+	for i := range l.Items {
+		it := &l.Items[i]
+		if it.unmarshalErr != nil || !s.base64Key(it.key) {
+			continue
+		}
+		b, err := decodeBase64Value(it.value)
+		if err != nil {
+			it.value = ""
+			it.unmarshalErr = fmt.Errorf("configstore: item '%s': invalid base64 value: %v", it.key, err)
+			continue
+		}
+		it.value = string(b)
+	}
+}
+
+func (s *Store) base64Key(key string) bool {
+	for _, p := range s.base64Patterns {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Decodes a base64 value, detecting the standard or URL-safe alphabet, with or without padding.
+func decodeBase64Value(v string) ([]byte, error) {
+	v = strings.Join(strings.Fields(v), "")
+	enc := base64.StdEncoding
+	if strings.ContainsAny(v, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(v, "=") && len(v)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	return enc.DecodeString(v)
+}
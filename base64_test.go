@@ -0,0 +1,39 @@
+package configstore
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreBase64Decoding(t *testing.T) {
+	raw := []byte{0x00, 0xff, 0xfe, 'k', 'e', 'y', 0x3f}
+	s := NewStore()
+	s.InMemory("inmem").
+		Set("tls_key", base64.StdEncoding.EncodeToString(raw), 0).
+		Set("url-key", base64.RawURLEncoding.EncodeToString(raw), 0).
+		Set("wrapped-key", "AP/+\n a2V5Pw==\n", 0).
+		Set("bad-key", "not base64!", 0).
+		Set("other", base64.StdEncoding.EncodeToString(raw), 0)
+
+	v, err := s.GetItemValue("tls_key")
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(raw), v, "decoding is disabled by default")
+
+	s.EnableBase64Decoding("*_key")
+	for _, key := range []string{"tls-key", "url-key", "wrapped-key"} {
+		v, err := s.GetItemValue(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, raw, []byte(v), key)
+	}
+
+	_, err = s.GetItemValue("bad-key")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "configstore: item 'bad-key': invalid base64 value")
+
+	v, err = s.GetItemValue("other")
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(raw), v, "non-matching keys are left as is")
+}
@@ -2,6 +2,8 @@ package configstore
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 )
 
@@ -13,6 +15,9 @@ const (
 var (
 	providerFactories = map[string]ProviderFactory{}
 	pFactMut          sync.Mutex
+
+	schemeFactories = map[string]SchemeFactory{}
+	schemeMut       sync.Mutex
 )
 
 func init() {
@@ -23,6 +28,14 @@ func init() {
 	RegisterProviderFactory("filetree", fileTreeProvider)
 	RegisterProviderFactory("filetree+refresh", fileTreeRefreshProvider)
 	RegisterProviderFactory("env", envProvider)
+
+	RegisterScheme("file", pathScheme(fileProvider))
+	RegisterScheme("file+refresh", pathScheme(fileRefreshProvider))
+	RegisterScheme("filelist", pathScheme(fileListProvider))
+	RegisterScheme("filelist+refresh", pathScheme(fileListRefreshProvider))
+	RegisterScheme("filetree", pathScheme(fileTreeProvider))
+	RegisterScheme("filetree+refresh", pathScheme(fileTreeRefreshProvider))
+	RegisterScheme("env", pathScheme(envProvider))
 }
 
 // A Provider retrieves config items and makes them available to the configstore,
@@ -46,3 +59,61 @@ func RegisterProviderFactory(name string, f ProviderFactory) {
 	}
 	providerFactories[name] = f
 }
+
+// A SchemeFactory is a function that instantiates a provider from a URL and registers it
+// to a store instance, see RegisterFromURL.
+type SchemeFactory func(*Store, *url.URL) error
+
+// RegisterScheme registers a factory function so that RegisterFromURL can properly
+// instantiate configuration providers via URLs using that scheme (e.g. "consul" for consul://host/prefix).
+func RegisterScheme(scheme string, factory func(*Store, *url.URL) error) {
+	scheme = strings.ToLower(scheme)
+	schemeMut.Lock()
+	defer schemeMut.Unlock()
+	_, ok := schemeFactories[scheme]
+	if ok {
+		panic(fmt.Sprintf("conflict on configuration provider scheme: %s", scheme))
+	}
+	schemeFactories[scheme] = factory
+}
+
+// RegisterFromURL instantiates a configuration provider described by a URL, and registers it to the store.
+// The provider is selected by the URL scheme, see RegisterScheme.
+// It returns a *StoreFrozenError if the store is frozen, see Store.Freeze.
+// Built-in providers are registered by default, their argument being the URL host and path:
+//
+//	file:///etc/myfile.conf, file+refresh:///etc/myfile.conf
+//	filelist:///home/foobar/configs, filelist+refresh:///home/foobar/configs
+//	filetree:///etc/configs, filetree+refresh:///etc/configs
+//	env://CONFIG_
+func RegisterFromURL(s *Store, rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return fmt.Errorf("configstore: invalid provider URL '%s': %v", rawurl, err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("configstore: missing scheme in provider URL '%s'", rawurl)
+	}
+	schemeMut.Lock()
+	f := schemeFactories[u.Scheme]
+	schemeMut.Unlock()
+	if f == nil {
+		return &ProviderNotFoundError{Name: u.Scheme}
+	}
+	if s.Frozen() {
+		return &StoreFrozenError{Name: rawurl}
+	}
+	return f(s, u)
+}
+
+// Adapts a built-in provider factory taking a path-like argument to a scheme factory.
+func pathScheme(f func(*Store, string)) SchemeFactory {
+	return func(s *Store, u *url.URL) error {
+		arg := u.Opaque
+		if arg == "" {
+			arg = u.Host + u.Path
+		}
+		f(s, arg)
+		return nil
+	}
+}
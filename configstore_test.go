@@ -1,12 +1,17 @@
 package configstore
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func ProviderTest() (ItemList, error) {
@@ -172,6 +177,12 @@ func TestStore(t *testing.T) {
 	_, err = items.GetItem("duration")
 	assert.Equal(mustType(err, ErrUninitializedItemList("")), false)
 
+	// Check multi-valued key
+	assert.Equal([]string{"higher", "mid", "low"}, must(items.GetItemValueList("other")))
+	values, err := items.GetItemValueList("notfound")
+	assert.Equal([]string{}, values)
+	assert.Equal(mustType(err, ErrItemNotFound("")), true)
+
 	// Check ambigous item
 	_, err = items.GetItem("sql")
 	assert.Equal(mustType(err, ErrAmbiguousItem("")), true)
@@ -217,3 +228,217 @@ func must(i interface{}, err error) interface{} {
 func mustType(a interface{}, b interface{}) bool {
 	return reflect.TypeOf(a) == reflect.TypeOf(b)
 }
+
+func TestStoreStrict(t *testing.T) {
+	s := NewStore()
+	s.InMemory("a").Add(NewItem("db_host", "host-a", 5), NewItem("port", "1", 5), NewItem("port", "2", 5))
+	s.InMemory("b").Add(NewItem("db_host", "host-b", 5))
+	s.InMemory("c").Add(NewItem("port", "3", 10))
+	assert := assert.New(t)
+
+	// duplicates are kept when not in strict mode
+	_, err := s.GetItemList()
+	assert.NoError(err)
+
+	s.SetStrict(true)
+	_, err = s.GetItemList()
+	assert.True(mustType(err, ErrDuplicateItem("")))
+	assert.EqualError(err, "configstore: strict mode: key 'db-host' defined at priority 5 by multiple providers: a, b")
+
+	// overriding with a different priority, or duplicates within a single provider, are allowed
+	s.UnregisterProvider("b")
+	s.InMemory("b").Add(NewItem("db_host", "host-b", 10))
+	_, err = s.GetItemList()
+	assert.NoError(err)
+}
+
+func TestStoreConflictResolver(t *testing.T) {
+	s := NewStore()
+	s.InMemory("a").Add(NewItem("db_host", "host-a", 5), NewItem("port", "1", 5), NewItem("port", "2", 5))
+	s.InMemory("b").Add(NewItem("db_host", "host-b", 5), NewItem("db_host", "host-b2", 1))
+	assert := assert.New(t)
+
+	// without a resolver, the conflicting items are kept
+	_, err := s.GetItem("db_host")
+	assert.True(mustType(err, ErrAmbiguousItem("")))
+
+	var calls []string
+	s.SetConflictResolver(func(key string, candidates []Item) Item {
+		sources := []string{}
+		for _, c := range candidates {
+			sources = append(sources, c.Source()+"="+mustValue(c))
+		}
+		calls = append(calls, key+": "+strings.Join(sources, ", "))
+		return candidates[len(candidates)-1]
+	})
+	s.SetStrict(true)
+
+	l, err := s.GetItemList()
+	assert.NoError(err)
+	assert.Equal([]string{"db-host: a=host-a, b=host-b"}, calls, "duplicates within a single provider are not conflicts")
+	i, err := s.GetFirst("db_host")
+	assert.NoError(err)
+	assert.Equal("host-b", mustValue(i))
+	assert.Equal("b", i.Source())
+	assert.Len(l.indexed["port"], 2)
+	assert.Len(l.indexed["db-host"], 2)
+}
+
+func TestStoreGettersWithDefault(t *testing.T) {
+	var logged []string
+	defer func(f func(string, ...interface{})) { LogErrorFunc = f }(LogErrorFunc)
+	LogErrorFunc = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	s := NewStore()
+	s.InMemory("test").Add(
+		NewItem("str", "value", 1),
+		NewItem("bool", "true", 1),
+		NewItem("int", "42", 1),
+		NewItem("duration", "42s", 1),
+		NewItem("typo", "fasle", 1),
+	)
+	assert := assert.New(t)
+
+	// present
+	assert.Equal("value", s.GetItemValueOr("str", "def"))
+	assert.Equal(true, s.GetItemValueBoolOr("bool", false))
+	assert.Equal(int64(42), s.GetItemValueIntOr("int", 1))
+	assert.Equal(42*time.Second, s.GetItemValueDurationOr("duration", time.Second))
+	assert.Empty(logged)
+
+	// absent: default value, nothing logged
+	assert.Equal("def", s.GetItemValueOr("missing", "def"))
+	assert.Equal(true, s.GetItemValueBoolOr("missing", true))
+	assert.Equal(int64(1), s.GetItemValueIntOr("missing", 1))
+	assert.Equal(float64(1.5), s.GetItemValueFloatOr("missing", 1.5))
+	assert.Equal(time.Second, s.GetItemValueDurationOr("missing", time.Second))
+	assert.Empty(logged)
+
+	// present but unparseable: default value, error logged
+	assert.Equal(true, s.GetItemValueBoolOr("typo", true))
+	assert.Equal(uint64(7), s.GetItemValueUintOr("typo", 7))
+	if assert.Len(logged, 2) {
+		assert.Contains(logged[0], "get 'typo': using default value")
+	}
+}
+
+func TestStoreKeyNormalization(t *testing.T) {
+	t.Setenv("CONFIGSTORE_NORM_DB_HOST", "env-host")
+
+	f, err := os.CreateTemp(t.TempDir(), "config*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString("- key: DB_Port\n  value: \"5432\"\n")
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore()
+	s.Env("CONFIGSTORE_NORM")
+	s.File(f.Name())
+	assert := assert.New(t)
+
+	for _, k := range []string{"db-host", "db_host", "DB_HOST", "Db-Host"} {
+		v, err := s.GetItemValue(k)
+		assert.NoError(err)
+		assert.Equal("env-host", v)
+	}
+	for _, k := range []string{"db-port", "db_port", "DB_PORT"} {
+		v, err := s.GetItemValue(k)
+		assert.NoError(err)
+		assert.Equal("5432", v)
+	}
+	items, err := s.GetItemList()
+	assert.NoError(err)
+	assert.ElementsMatch([]string{"db-host", "db-port"}, items.Keys())
+}
+
+func TestStoreRegisterProviderWithPriority(t *testing.T) {
+	s := NewStore()
+	s.RegisterProvider("low", ProviderTest2)
+	s.RegisterProviderWithPriority("vault", func() (ItemList, error) {
+		return ItemList{Items: []Item{NewItem("foo", "from vault", 0), NewItem("other", "x", 3)}}, nil
+	}, 100)
+	s.RegisterProviderWithPriority("kept", func() (ItemList, error) {
+		return ItemList{Items: []Item{NewItem("kept", "x", 3)}}, nil
+	}, KeepItemPriority)
+	assert := assert.New(t)
+
+	i, err := s.GetFirst("foo")
+	assert.NoError(err)
+	assert.Equal("from vault", mustValue(i))
+	assert.Equal(int64(100), i.Priority())
+
+	i, err = s.Get("other")
+	assert.NoError(err)
+	assert.Equal(int64(100), i.Priority())
+
+	i, err = s.Get("kept")
+	assert.NoError(err)
+	assert.Equal(int64(3), i.Priority())
+}
+
+func TestRegisterFromURL(t *testing.T) {
+	t.Setenv("CONFIGSTORE_URL_TEST_FOO", "from env")
+	assert := assert.New(t)
+
+	s := NewStore()
+	assert.NoError(RegisterFromURL(s, "filetree://tests/fixtures/filetreeprovider2"))
+	assert.NoError(RegisterFromURL(s, "env://CONFIGSTORE_URL_TEST_"))
+
+	v, err := s.GetItemValue("database/prod/foo")
+	assert.NoError(err)
+	assert.Equal("prod foo value", v)
+
+	i, err := s.GetFirst("foo")
+	assert.NoError(err)
+	assert.Equal("from env", mustValue(i))
+
+	RegisterScheme("test", func(s *Store, u *url.URL) error {
+		s.InMemory("test:" + u.Path).Add(NewItem("path", u.Path, 1))
+		return nil
+	})
+	assert.NoError(RegisterFromURL(s, "test://host/some/path"))
+	i, err = s.Get("path")
+	assert.NoError(err)
+	assert.Equal("/some/path", mustValue(i))
+
+	assert.EqualError(RegisterFromURL(s, "consul://host/prefix"), "configstore: provider 'consul': no such provider factory")
+	assert.EqualError(RegisterFromURL(s, "/etc/app.yaml"), "configstore: missing scheme in provider URL '/etc/app.yaml'")
+}
+
+func TestItemSource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: db-host\n  value: localhost\n"), 0600))
+	t.Setenv("SOURCETEST_PORT", "5432")
+
+	s := NewStore()
+	s.File(filename)
+	s.Env("SOURCETEST")
+	s.InMemory("defaults").Add(NewItem("debug", "false", 0))
+	s.Alias("db-port", "port")
+	assert := assert.New(t)
+
+	i, err := s.GetItem("db-host")
+	assert.NoError(err)
+	assert.Equal("file:"+filename, i.Source())
+	assert.Equal("db-host=localhost (priority 5, from file:"+filename+")", i.String())
+
+	i, err = s.GetItem("port")
+	assert.NoError(err)
+	assert.Equal("env:SOURCETEST_", i.Source())
+
+	i, err = s.GetItem("db-port")
+	assert.NoError(err)
+	assert.Equal("env:SOURCETEST_", i.Source(), "aliased items keep the source of their target")
+
+	i, err = s.Filter().Squash().GetItem("debug")
+	assert.NoError(err)
+	assert.Equal("defaults", i.Source())
+
+	assert.Equal("", NewItem("debug", "false", 0).Source())
+}
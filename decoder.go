@@ -0,0 +1,40 @@
+package configstore
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	decoders   = map[string]func([]byte) ([]Item, error){}
+	decoderMut sync.RWMutex
+)
+
+// RegisterDecoder registers the function decoding the files with the given extension (".hcl" or "hcl"), so that
+// the file providers (File, FileList, ...) pick it automatically, e.g. to mix .yaml and .toml files in a directory.
+// It is also used by LoadFromReader for the format of the same name. The YAML item list, which JSON files also follow,
+// is built in: it is the decoder of the .yaml, .yml and .json files, and of the files whose extension has no decoder.
+// Registering a decoder for one of these extensions replaces the built-in one for it.
+// Registering two decoders for the same extension panics, as RegisterProviderFactory does.
+func RegisterDecoder(ext string, fn func([]byte) ([]Item, error)) {
+	ext = normalizeExt(ext)
+	decoderMut.Lock()
+	defer decoderMut.Unlock()
+	if _, ok := decoders[ext]; ok {
+		panic(fmt.Sprintf("conflict on configuration decoder: %s", ext))
+	}
+	decoders[ext] = fn
+}
+
+// Returns the decoder registered for the extension of the file, nil for the built-in one.
+func lookupDecoder(filename string) func([]byte) ([]Item, error) {
+	decoderMut.RLock()
+	defer decoderMut.RUnlock()
+	return decoders[normalizeExt(filepath.Ext(filename))]
+}
+
+func normalizeExt(ext string) string {
+	return "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+}
@@ -0,0 +1,49 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Decodes key=value lines, standing for a third-party format.
+func decodeTestProperties(b []byte) ([]Item, error) {
+	var vals []Item
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		kv := strings.SplitN(line, "=", 2)
+		vals = append(vals, NewItem(kv[0], kv[1], DefaultFilePriority))
+	}
+	return vals, nil
+}
+
+func init() {
+	RegisterDecoder(".TestProperties", decodeTestProperties)
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("- key: a\n  value: yaml\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[{"key": "b", "value": "json"}]`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.testproperties"), []byte("c=properties\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "d.conf"), []byte("- key: d\n  value: default\n"), 0600))
+
+	s := NewStore()
+	s.FileList(dir)
+	for key, expected := range map[string]string{"a": "yaml", "b": "json", "c": "properties", "d": "default"} {
+		v, err := s.GetItemValue(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, expected, v, key)
+	}
+
+	s = NewStore()
+	require.NoError(t, s.LoadFromReader("stdin", strings.NewReader("e=reader\n"), "testproperties"))
+	v, err := s.GetItemValue("e")
+	require.NoError(t, err)
+	assert.Equal(t, "reader", v)
+
+	assert.Panics(t, func() { RegisterDecoder("testproperties", decodeTestProperties) })
+}
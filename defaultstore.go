@@ -1,6 +1,9 @@
 package configstore
 
 import (
+	"context"
+	"database/sql"
+	"io"
 	"time"
 )
 
@@ -27,11 +30,102 @@ func RegisterProvider(name string, f Provider) {
 	DefaultStore.RegisterProvider(name, f)
 }
 
+// RegisterProviderWithPriority registers a provider, overriding the priority of every item it returns
+// with the given value. If priority is KeepItemPriority, the items keep their own priorities.
+func RegisterProviderWithPriority(name string, f Provider, priority int64) {
+	DefaultStore.RegisterProviderWithPriority(name, f, priority)
+}
+
+// RegisterProviderWithRetry registers a provider whose items are loaded once. If the initial load fails,
+// the error is served while the load is retried in the background, with an exponential backoff described by the policy.
+func RegisterProviderWithRetry(name string, load Provider, policy RetryPolicy) {
+	DefaultStore.RegisterProviderWithRetry(name, load, policy)
+}
+
+// SetProviderTimeout sets the maximum duration of a provider call while evaluating the configuration of the default store.
+// Zero disables the timeout. See Store.SetProviderTimeout.
+func SetProviderTimeout(timeout time.Duration) {
+	DefaultStore.SetProviderTimeout(timeout)
+}
+
+// SetProviderTimeoutFor overrides the provider timeout of the default store for the provider with the given name.
+func SetProviderTimeoutFor(name string, timeout time.Duration) {
+	DefaultStore.SetProviderTimeoutFor(name, timeout)
+}
+
+// RegisterProviderAsync registers a provider whose items are loaded once, in its own goroutine. See Store.RegisterProviderAsync.
+func RegisterProviderAsync(name string, fn Provider) {
+	DefaultStore.RegisterProviderAsync(name, fn)
+}
+
+// WaitReady blocks until all the asynchronous providers of the default store are loaded, or the context is done.
+func WaitReady(ctx context.Context) error {
+	return DefaultStore.WaitReady(ctx)
+}
+
+// Wait blocks until the key is provided to the default store, or the context is done, see Store.Wait.
+func Wait(ctx context.Context, key string) (Item, error) {
+	return DefaultStore.Wait(ctx, key)
+}
+
+// RegisterProviderWithTimeout registers a provider whose every call is abandoned after the given timeout,
+// in which case it returns a *ProviderTimeoutError.
+func RegisterProviderWithTimeout(name string, fn Provider, timeout time.Duration) {
+	DefaultStore.RegisterProviderWithTimeout(name, fn, timeout)
+}
+
 // UnregisterProvider unregisters a provider
 func UnregisterProvider(name string) {
 	DefaultStore.UnregisterProvider(name)
 }
 
+// SetMergeStrategy installs the strategy used to merge the items of a key defined by several providers
+// of the default store. See Store.SetMergeStrategy.
+func SetMergeStrategy(ms MergeStrategy) {
+	DefaultStore.SetMergeStrategy(ms)
+}
+
+// Require checks that every given key is present in the default store, see Store.Require.
+func Require(keys ...string) error {
+	return DefaultStore.Require(keys...)
+}
+
+// RegisterSchema adds a schema to be checked by Validate on the default store. See Store.RegisterSchema.
+func RegisterSchema(schema Schema) {
+	DefaultStore.RegisterSchema(schema)
+}
+
+// Validate checks the configuration of the default store against all the registered schemas. See Store.Validate.
+func Validate() error {
+	return DefaultStore.Validate()
+}
+
+// RenameKey keeps the old key "from" of the default store working until the deprecation deadline. See Store.RenameKey.
+func RenameKey(from, to string, deprecationDeadline time.Time) {
+	DefaultStore.RenameKey(from, to, deprecationDeadline)
+}
+
+// DeprecationReport lists the renames of the default store still in their compatibility period. See Store.DeprecationReport.
+func DeprecationReport() []DeprecationEntry {
+	return DefaultStore.DeprecationReport()
+}
+
+// BindLogLevel makes the verbosity of the default store logs follow the value of the given key. See Store.BindLogLevel.
+func BindLogLevel(key string) {
+	DefaultStore.BindLogLevel(key)
+}
+
+// EnableBase64Decoding activates the transparent decoding of base64 item values of the default store,
+// for the keys matching the given glob pattern. See Store.EnableBase64Decoding.
+func EnableBase64Decoding(keyPattern string) {
+	DefaultStore.EnableBase64Decoding(keyPattern)
+}
+
+// Freeze makes the set of providers of the default store final. See Store.Freeze.
+func Freeze() {
+	DefaultStore.Freeze()
+}
+
 // AllowProviderOverride allows multiple calls to RegisterProvider() with the same provider name.
 // This is useful for controlled test cases, but is not recommended in the context of a real
 // application.
@@ -39,6 +133,61 @@ func AllowProviderOverride() {
 	DefaultStore.AllowProviderOverride()
 }
 
+// SetStrict enables or disables the strict mode on the default store.
+// In strict mode, GetItemList returns an error when a key is defined by several providers at the same priority,
+// instead of silently keeping all the conflicting items. Overriding a key with a different priority is still allowed.
+func SetStrict(strict bool) {
+	DefaultStore.SetStrict(strict)
+}
+
+// SetMetricsObserver sets the observer notified of the provider loads and errors of the default store. Passing nil disables it.
+func SetMetricsObserver(o MetricsObserver) {
+	DefaultStore.SetMetricsObserver(o)
+}
+
+// SetMetricsHandler sets the handler recording the provider calls and the watchers notifications of the default store.
+// Passing nil disables it.
+func SetMetricsHandler(h MetricsHandler) {
+	DefaultStore.SetMetricsHandler(h)
+}
+
+// EnableAuditLog sets the logger recording the calls of Get and GetFirst on the default store. Passing nil disables it.
+func EnableAuditLog(logger AuditLogger) {
+	DefaultStore.EnableAuditLog(logger)
+}
+
+// AddValidator adds a function checking invariants on the whole configuration of the default store.
+// If a validator returns an error, the new configuration is rejected and the last valid one keeps being served.
+func AddValidator(fn func(ItemList) error) {
+	DefaultStore.AddValidator(fn)
+}
+
+// ValidationError returns the error of the last validation of the default store configuration, or nil if it was valid.
+func ValidationError() error {
+	return DefaultStore.ValidationError()
+}
+
+// SetConflictResolver installs on the default store a function choosing the item to keep
+// when several providers define a key at the same priority. See Store.SetConflictResolver.
+func SetConflictResolver(resolver func(key string, candidates []Item) Item) {
+	DefaultStore.SetConflictResolver(resolver)
+}
+
+// SetProfile sets the active profile of the default store, see Store.SetProfile.
+func SetProfile(profile string) {
+	DefaultStore.SetProfile(profile)
+}
+
+// ActiveProfile returns the active profile of the default store, see Store.SetProfile.
+func ActiveProfile() string {
+	return DefaultStore.ActiveProfile()
+}
+
+// SetStrictDecoding enables or disables the strict decoding of the item files of the default store. See Store.SetStrictDecoding.
+func SetStrictDecoding(strict bool) {
+	DefaultStore.SetStrictDecoding(strict)
+}
+
 // ErrorProvider registers a configstore provider which always returns an error.
 func ErrorProvider(name string, err error) {
 	DefaultStore.ErrorProvider(name, err)
@@ -50,6 +199,7 @@ func File(filename string) {
 }
 
 // FileRefresh registers a configstore provider which readfs from the file given in parameter (provider watches file stat for auto refresh, watchers get notified).
+// The file is also picked up when it is replaced, e.g. renamed over, or swapped by Kubernetes through the ..data link of a configmap mount.
 func FileRefresh(filename string) {
 	DefaultStore.FileRefresh(filename)
 }
@@ -64,6 +214,12 @@ func FileCustomRefresh(filename string, fn func([]byte) ([]Item, error)) {
 	DefaultStore.FileCustomRefresh(filename, fn)
 }
 
+// FileEncrypted registers a configstore provider which reads from the file given in parameter (static content),
+// decrypting it with AES-GCM and the given key before decoding it. See EncryptConfig to produce such a file.
+func FileEncrypted(filename string, key []byte) {
+	DefaultStore.FileEncrypted(filename, key)
+}
+
 // FileTree registers a configstore provider which reads from the files contained in the directory given in parameter.
 // A limited hierarchy is supported: files can either be top level (in which case the file name will be used as the item key),
 // or nested in a single sub-directory (in which case the sub-directory name will be used as item key for all the files contained in it).
@@ -80,16 +236,47 @@ func FileTreeRefresh(dirname string) {
 	DefaultStore.FileTreeRefresh(dirname)
 }
 
+// FileJSON registers a configstore provider which reads an arbitrary JSON document (static content).
+// See Store.FileJSON.
+func FileJSON(filename string, opts ...JSONFileOption) {
+	DefaultStore.FileJSON(filename, opts...)
+}
+
 // FileList registers a configstore provider which reads from the files contained in the directory given in parameter.
 // The content of the files should be JSON/YAML similar to the File provider.
-func FileList(dirname string) {
-	DefaultStore.FileList(dirname)
+// Symbolic links to files are followed, dangling ones are skipped; see WithDirectorySymlinks for links to directories.
+func FileList(dirname string, opts ...FileListOption) {
+	DefaultStore.FileList(dirname, opts...)
 }
 
 // FileListRefresh is similar to the FileList provider with the refresh feature enabled.
+// The directory is watched as well: the files created in it are loaded, and the items of the removed files are dropped.
 // Updates can be handled with the `Watch()` function.
-func FileListRefresh(dirname string) {
-	DefaultStore.FileListRefresh(dirname)
+func FileListRefresh(dirname string, opts ...FileListOption) {
+	DefaultStore.FileListRefresh(dirname, opts...)
+}
+
+// SQL registers a configstore provider which runs the given query on the database (static content).
+// The query must return 'key' and 'value' columns, and optionally a 'priority' column.
+func SQL(db *sql.DB, query string) {
+	DefaultStore.SQL(db, query)
+}
+
+// SQLRefresh is similar to the SQL provider, re-running the query at the given interval.
+func SQLRefresh(db *sql.DB, query string, interval time.Duration) {
+	DefaultStore.SQLRefresh(db, query, interval)
+}
+
+// LoadFromReader reads all the data from r, decodes it according to the format ("yaml" or "json"),
+// and registers the resulting items as an in-memory provider of the default store. See Store.LoadFromReader.
+func LoadFromReader(name string, r io.Reader, format string) error {
+	return DefaultStore.LoadFromReader(name, r, format)
+}
+
+// LoadFromArgs registers an in-memory provider of the default store holding an item for each "KEY=VALUE" argument.
+// See Store.LoadFromArgs.
+func LoadFromArgs(name string, priority int64, args []string) error {
+	return DefaultStore.LoadFromArgs(name, priority, args)
 }
 
 // InMemory registers an InMemoryProvider with a given arbitrary name and returns it.
@@ -98,6 +285,12 @@ func InMemory(name string) *InMemoryProvider {
 	return DefaultStore.InMemory(name)
 }
 
+// Transaction applies a batch of mutations to the in-memory provider of the default store with the given name atomically.
+// See Store.Transaction.
+func Transaction(name string, fn func(p *InMemoryProvider) error) error {
+	return DefaultStore.Transaction(name, fn)
+}
+
 // Env registers a provider reading from the environment.
 // Only variables beginning with "PREFIX_" will be considered.
 // Trimmed variable names are used as keys. Keys are not case-sensitive.
@@ -106,6 +299,41 @@ func Env(prefix string) {
 	DefaultStore.Env(prefix)
 }
 
+// Alias makes the key "from" resolve to the items of the key "to" in the default store,
+// when "from" is not provided by any provider. If both keys exist, the real "from" items take precedence.
+func Alias(from, to string) {
+	DefaultStore.Alias(from, to)
+}
+
+// AliasWithWarning is similar to Alias, but logs an informational message every time the alias becomes
+// in use, so that operators know that a deprecated key name is being relied on.
+func AliasWithWarning(from, to string) {
+	DefaultStore.AliasWithWarning(from, to)
+}
+
+// AddAlias makes oldKey and newKey equivalent in the default store during a rename. See Store.AddAlias.
+func AddAlias(oldKey, newKey string) {
+	DefaultStore.AddAlias(oldKey, newKey)
+}
+
+// Deprecate logs a warning with the given message the first time the key is provided to the default store.
+// See Store.Deprecate.
+func Deprecate(key, message string) {
+	DefaultStore.Deprecate(key, message)
+}
+
+// EnableEnvExpansion activates the expansion of environment variables in the item values of the default store,
+// see Store.EnableEnvExpansion.
+func EnableEnvExpansion(opts ...EnvExpansionOption) {
+	DefaultStore.EnableEnvExpansion(opts...)
+}
+
+// EnableTemplateSubstitution activates the substitution of ${config:OTHER_KEY} references in the item values of the default store,
+// see Store.EnableTemplateSubstitution.
+func EnableTemplateSubstitution(opts ...TemplateOption) {
+	DefaultStore.EnableTemplateSubstitution(opts...)
+}
+
 /*
 ** WATCH / NOTIFY
  */
@@ -116,6 +344,43 @@ func Watch() chan struct{} {
 	return DefaultStore.Watch()
 }
 
+// WatchDiff returns a channel receiving the structured changes of the default store configuration, see Store.WatchDiff.
+func WatchDiff() chan ConfigDiff {
+	return DefaultStore.WatchDiff()
+}
+
+// UnwatchDiff stops sending the changes of the default store configuration to a channel returned by WatchDiff.
+func UnwatchDiff(ch chan ConfigDiff) {
+	DefaultStore.UnwatchDiff(ch)
+}
+
+// NotifyWatchersWithDiff notifies the watchers of the default store of configuration changes, along with the changes themselves.
+func NotifyWatchersWithDiff(diff ConfigDiff) {
+	DefaultStore.NotifyWatchersWithDiff(diff)
+}
+
+// TakeSnapshot captures the current items of all the providers of the default store. See Store.Snapshot.
+func TakeSnapshot() Snapshot {
+	return DefaultStore.Snapshot()
+}
+
+// Restore atomically replaces the state of the default store with the one captured by the snapshot. See Store.Restore.
+func Restore(sn Snapshot) error {
+	return DefaultStore.Restore(sn)
+}
+
+// WatchEvents returns a channel receiving one event per changed key of the default store configuration,
+// see Store.WatchEvents.
+func WatchEvents(ctx context.Context) <-chan ConfigEvent {
+	return DefaultStore.WatchEvents(ctx)
+}
+
+// WatchKey returns a channel receiving the new value of the key of the default store every time it changes,
+// and a function to stop watching. See Store.WatchKey.
+func WatchKey(key string) (<-chan string, func()) {
+	return DefaultStore.WatchKey(key)
+}
+
 // NotifyWatchers is used by providers to notify of configuration changes.
 // It unblocks all the watchers which are ranging over a watch channel.
 func NotifyWatchers() {
@@ -161,6 +426,22 @@ func GetItemValue(key string) (string, error) {
 	return DefaultStore.GetItemValue(key)
 }
 
+// Keys returns the sorted list of the keys present in the default store.
+func Keys() ([]string, error) {
+	return DefaultStore.Keys()
+}
+
+// ReadOnly returns a read-only view of the default store, see Store.ReadOnly.
+func ReadOnly() ReadOnlyStore {
+	return DefaultStore.ReadOnly()
+}
+
+// GetItemValueList fetches the full item list, merging the results from all providers, then returns the values of all the items
+// sharing that key, ordered by descending priority.
+func GetItemValueList(key string) ([]string, error) {
+	return DefaultStore.GetItemValueList(key)
+}
+
 // GetItemValueBool fetches the full item list, merging the results from all providers, then returns a single item's value by key.
 func GetItemValueBool(key string) (bool, error) {
 	return DefaultStore.GetItemValueBool(key)
@@ -185,3 +466,124 @@ func GetItemValueUint(key string) (uint64, error) {
 func GetItemValueDuration(key string) (time.Duration, error) {
 	return DefaultStore.GetItemValueDuration(key)
 }
+
+// Get retrieves the full item list, merging the results from all providers, then returns a single item by key.
+// It is equivalent to GetItem, and the read is recorded by the audit logger, see EnableAuditLog.
+func Get(key string) (Item, error) {
+	return DefaultStore.get(key, 1)
+}
+
+// GetFirst retrieves the full item list, merging the results from all providers, then returns the item
+// with the highest priority for that key. The read is recorded by the audit logger, see EnableAuditLog.
+func GetFirst(key string) (Item, error) {
+	return DefaultStore.getFirst(key, 1)
+}
+
+// GetFromContext returns the item of key overridden in ctx, if any (see WithConfigOverride),
+// or the item retrieved from the default store otherwise, as Get does.
+func GetFromContext(ctx context.Context, key string) (Item, error) {
+	return DefaultStore.getFromContext(ctx, key, 1)
+}
+
+// Unmarshal retrieves a single item by key, then unmarshals its value (from JSON or YAML) into v.
+func Unmarshal(key string, v interface{}) error {
+	return DefaultStore.Unmarshal(key, v)
+}
+
+/*
+** SCOPES
+ */
+
+// Namespace returns a view of the default store scoped to the items whose key begins with prefix, used as is.
+// See Store.Namespace.
+func Namespace(prefix string) *NamespacedStore {
+	return DefaultStore.Namespace(prefix)
+}
+
+// WithPrefix returns a view of the default store scoped to the items whose key begins with "prefix.".
+func WithPrefix(prefix string) *ScopedStore {
+	return DefaultStore.WithPrefix(prefix)
+}
+
+/*
+** GETTERS WITH DEFAULT VALUE
+ */
+
+// GetItemValueOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved for any other reason, the error is logged and def is returned.
+func GetItemValueOr(key string, def string) string {
+	return DefaultStore.GetItemValueOr(key, def)
+}
+
+// GetItemValueBoolOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func GetItemValueBoolOr(key string, def bool) bool {
+	return DefaultStore.GetItemValueBoolOr(key, def)
+}
+
+// GetItemValueFloatOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func GetItemValueFloatOr(key string, def float64) float64 {
+	return DefaultStore.GetItemValueFloatOr(key, def)
+}
+
+// GetItemValueIntOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func GetItemValueIntOr(key string, def int64) int64 {
+	return DefaultStore.GetItemValueIntOr(key, def)
+}
+
+// GetItemValueUintOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func GetItemValueUintOr(key string, def uint64) uint64 {
+	return DefaultStore.GetItemValueUintOr(key, def)
+}
+
+// GetItemValueDurationOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func GetItemValueDurationOr(key string, def time.Duration) time.Duration {
+	return DefaultStore.GetItemValueDurationOr(key, def)
+}
+
+/*
+** EXPORT
+ */
+
+// Patch temporarily overrides the value of a key of the default store, see Store.Patch.
+func Patch(key, value string, ttl time.Duration) (revert func()) {
+	return DefaultStore.Patch(key, value, ttl)
+}
+
+// Dump writes the merged configuration of the default store as a table, with the source of each value and the items
+// it shadows, see Store.Dump.
+func Dump(w io.Writer) error {
+	return DefaultStore.Dump(w)
+}
+
+// Export returns the merged configuration of the default store in the given format, "yaml" or "json",
+// with the values of sensitive items redacted, see Store.Export.
+func Export(format string) ([]byte, error) {
+	return DefaultStore.Export(format)
+}
+
+// ExportYAML returns the merged configuration of the default store as a YAML item list,
+// with the values of sensitive items redacted, see Store.ExportYAML.
+func ExportYAML() ([]byte, error) {
+	return DefaultStore.ExportYAML()
+}
+
+// ExportYAMLWithSecrets is similar to ExportYAML, but the values of sensitive items are exported as is.
+func ExportYAMLWithSecrets() ([]byte, error) {
+	return DefaultStore.ExportYAMLWithSecrets()
+}
+
+// ExportEnv returns the merged configuration of the default store as KEY=value lines,
+// with the values of sensitive items redacted, see Store.ExportEnv.
+func ExportEnv(opts ...EnvExportOption) ([]byte, error) {
+	return DefaultStore.ExportEnv(opts...)
+}
+
+// ExportEnvWithSecrets is similar to ExportEnv, but the values of sensitive items are exported as is.
+func ExportEnvWithSecrets(opts ...EnvExportOption) ([]byte, error) {
+	return DefaultStore.ExportEnvWithSecrets(opts...)
+}
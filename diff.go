@@ -0,0 +1,117 @@
+package configstore
+
+import (
+	"sort"
+)
+
+// ConfigDiff describes the changes between two item lists, see Diff.
+type ConfigDiff struct {
+	Added    []Item
+	Removed  []Item
+	Modified []ItemChange
+}
+
+// ItemChange describes the change of value of a key. The values of sensitive items are redacted.
+type ItemChange struct {
+	Key      string
+	OldValue string
+	NewValue string
+}
+
+// Empty reports whether the diff contains no change at all.
+func (d ConfigDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Diff compares two item lists key by key, considering the item with the highest priority for each key.
+// Keys only present in after are Added, keys only present in before are Removed,
+// and keys whose value changed are Modified. Each list is sorted by key.
+func Diff(before, after ItemList) ConfigDiff {
+	b := winningItems(before)
+	a := winningItems(after)
+
+	d := ConfigDiff{}
+	for k, it := range a {
+		old, ok := b[k]
+		if !ok {
+			d.Added = append(d.Added, it)
+		} else if old.value != it.value {
+			d.Modified = append(d.Modified, ItemChange{Key: k, OldValue: old.printableValue(), NewValue: it.printableValue()})
+		}
+	}
+	for k, it := range b {
+		if _, ok := a[k]; !ok {
+			d.Removed = append(d.Removed, it)
+		}
+	}
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].key < d.Added[j].key })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].key < d.Removed[j].key })
+	sort.Slice(d.Modified, func(i, j int) bool { return d.Modified[i].Key < d.Modified[j].Key })
+	return d
+}
+
+// Returns the item with the highest priority for each key. On a tie, the first item is kept.
+func winningItems(l ItemList) map[string]Item {
+	ret := map[string]Item{}
+	for _, it := range l.Items {
+		if cur, ok := ret[it.key]; !ok || it.priority > cur.priority {
+			ret[it.key] = it
+		}
+	}
+	return ret
+}
+
+// Notifies the watchers of a change of the items of a provider, with its diff. An empty diff still notifies the
+// watchers (see Watch), as a change of the priorities or of the metadata of the items can change the configuration,
+// e.g. which provider's value wins for a key.
+func (s *Store) notifyChange(diff ConfigDiff) {
+	if diff.Empty() {
+		s.NotifyWatchers()
+		return
+	}
+	s.NotifyWatchersWithDiff(diff)
+}
+
+// WatchDiff returns a channel receiving the structured changes notified through NotifyWatchersWithDiff,
+// e.g. by the refresh of a file provider.
+// Notifications never block: if the channel buffer is full, the diff is dropped for this subscriber.
+// Call UnwatchDiff once done with the channel.
+func (s *Store) WatchDiff() chan ConfigDiff {
+	newCh := make(chan ConfigDiff, 16)
+	s.watchersMut.Lock()
+	s.diffWatchers = append(s.diffWatchers, newCh)
+	s.watchersMut.Unlock()
+	return newCh
+}
+
+// UnwatchDiff stops sending the changes to a channel returned by WatchDiff. The channel is not closed.
+func (s *Store) UnwatchDiff(ch chan ConfigDiff) {
+	s.watchersMut.Lock()
+	defer s.watchersMut.Unlock()
+	for i, w := range s.diffWatchers {
+		if w == ch {
+			s.diffWatchers = append(s.diffWatchers[:i], s.diffWatchers[i+1:]...)
+			return
+		}
+	}
+}
+
+// NotifyWatchersWithDiff is used by providers to notify of configuration changes, along with the changes themselves.
+// It notifies the watchers (see Watch) and sends the diff to the diff watchers (see WatchDiff).
+// Nothing is notified if the diff is empty.
+func (s *Store) NotifyWatchersWithDiff(diff ConfigDiff) {
+	if diff.Empty() {
+		return
+	}
+	s.watchersMut.Lock()
+	if s.watchersNotif {
+		for _, ch := range s.diffWatchers {
+			select {
+			case ch <- diff:
+			default:
+			}
+		}
+	}
+	s.watchersMut.Unlock()
+	s.NotifyWatchers()
+}
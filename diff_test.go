@@ -0,0 +1,128 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := ItemList{Items: []Item{
+		NewItem("kept", "same", 1),
+		NewItem("changed", "old", 1),
+		NewItem("changed", "ignored", 0),
+		NewItem("removed", "gone", 1),
+		NewSecretItem("password", "old-secret", 1),
+	}}
+	after := ItemList{Items: []Item{
+		NewItem("kept", "same", 1),
+		NewItem("changed", "new", 1),
+		NewItem("added", "here", 1),
+		NewSecretItem("password", "new-secret", 1),
+	}}
+
+	d := Diff(before, after)
+	assert.False(t, d.Empty())
+	require.Len(t, d.Added, 1)
+	assert.Equal(t, "added", d.Added[0].Key())
+	require.Len(t, d.Removed, 1)
+	assert.Equal(t, "removed", d.Removed[0].Key())
+	assert.Equal(t, []ItemChange{
+		{Key: "changed", OldValue: "old", NewValue: "new"},
+		{Key: "password", OldValue: RedactedValue, NewValue: RedactedValue},
+	}, d.Modified)
+
+	assert.True(t, Diff(before, before).Empty())
+	assert.True(t, Diff(ItemList{}, ItemList{}).Empty())
+}
+
+func TestStoreNotifyWatchersWithDiff(t *testing.T) {
+	s := NewStore()
+	ch := s.Watch()
+	diffs := s.WatchDiff()
+
+	s.NotifyWatchersWithDiff(ConfigDiff{})
+	select {
+	case <-ch:
+		t.Fatal("watchers must not be notified of an empty diff")
+	case <-diffs:
+		t.Fatal("diff watchers must not be notified of an empty diff")
+	default:
+	}
+
+	d := ConfigDiff{Added: []Item{NewItem("foo", "bar", 1)}}
+	s.NotifyWatchersWithDiff(d)
+	assert.Equal(t, struct{}{}, <-ch)
+	assert.Equal(t, d, <-diffs)
+
+	s.UnwatchDiff(diffs)
+	s.UnwatchDiff(diffs)
+	s.NotifyWatchersWithDiff(d)
+	select {
+	case <-diffs:
+		t.Fatal("unwatched channels must not be notified")
+	default:
+	}
+	assert.Empty(t, s.diffWatchers)
+}
+
+func TestStoreFileRefreshDiff(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: a\n  value: \"1\"\n- key: b\n  value: \"2\"\n"), 0600))
+	s.FileRefresh(filename)
+	diffs := s.WatchDiff()
+
+	rewriteFile(t, filename, "- key: a\n  value: \"10\"\n- key: c\n  value: \"3\"\n")
+	select {
+	case d := <-diffs:
+		require.Len(t, d.Added, 1)
+		assert.Equal(t, "c", d.Added[0].Key())
+		require.Len(t, d.Removed, 1)
+		assert.Equal(t, "b", d.Removed[0].Key())
+		assert.Equal(t, []ItemChange{{Key: "a", OldValue: "1", NewValue: "10"}}, d.Modified)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no diff after file refresh")
+	}
+}
+
+func TestStoreFileRefreshPriorityChange(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+	s.InMemory("mem").Add(NewItem("a", "mem", 10))
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: a\n  value: file\n  priority: 1\n"), 0600))
+	s.FileRefresh(filename)
+	i, err := s.GetFirst("a")
+	require.NoError(t, err)
+	assert.Equal(t, "mem", i.value)
+
+	ch := s.Watch()
+	// only the priority changes, but the file value now wins
+	rewriteFile(t, filename, "- key: a\n  value: file\n  priority: 20\n")
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification after a priority change")
+	}
+	i, err = s.GetFirst("a")
+	require.NoError(t, err)
+	assert.Equal(t, "file", i.value)
+}
+
+// Rewrites a file with a single write, so that a refreshing provider cannot observe it truncated.
+// The new content must not be shorter than the current one.
+func rewriteFile(t *testing.T, filename, content string) {
+	f, err := os.OpenFile(filename, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+}
@@ -0,0 +1,33 @@
+package configstore
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Dump writes the merged configuration as a table, for debugging: for each key, sorted by key, the winning value
+// with its priority and source provider, followed by the items it shadows, by decreasing priority. The values are
+// quoted, so that the whitespace is visible, and the values of sensitive items are replaced with RedactedValue.
+// The output only depends on the configuration, so that two dumps can be diffed.
+func (s *Store) Dump(w io.Writer) error {
+	l, err := s.GetItemList()
+	if err != nil {
+		return err
+	}
+	keys := l.Keys()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tPRIORITY\tSOURCE")
+	for _, k := range keys {
+		for i, it := range l.indexed[k] {
+			key := k
+			if i > 0 {
+				key = "  (shadowed)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", key, strconv.Quote(it.printableValue()), it.priority, it.source)
+		}
+	}
+	return tw.Flush()
+}
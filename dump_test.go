@@ -0,0 +1,28 @@
+package configstore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	s := NewStore()
+	s.InMemory("defaults").Add(NewItem("foo", "low", 1), NewItem("bar", "bar value\n", 1))
+	s.InMemory("overrides").Add(NewItem("foo", "high", 10)).AddSecret("password", "s3cr3t", 1)
+
+	buf := &strings.Builder{}
+	require.NoError(t, s.Dump(buf))
+	assert.Equal(t, `KEY           VALUE          PRIORITY  SOURCE
+bar           "bar value\n"  1         defaults
+foo           "high"         10        overrides
+  (shadowed)  "low"          1         defaults
+password      "[REDACTED]"   1         overrides
+`, buf.String())
+
+	buf2 := &strings.Builder{}
+	require.NoError(t, s.Dump(buf2))
+	assert.Equal(t, buf.String(), buf2.String())
+}
@@ -1,14 +1,44 @@
 package configstore
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is matched by the errors returned by all the lookups (GetItem, GetItemValue, GetFirst, ...)
+	// when the key is absent, so that errors.Is(err, ErrNotFound) tells a missing optional key from any other failure.
+	// The errors themselves are of type ErrItemNotFound, and name the key.
+	ErrNotFound = errors.New("configstore: item not found")
+	// ErrAmbiguous is matched by the errors returned by the single value lookups when several items share the key.
+	// The errors themselves are of type ErrAmbiguousItem.
+	ErrAmbiguous = errors.New("configstore: ambiguous item")
+)
+
 type ErrItemNotFound string
 type ErrUninitializedItemList string
 type ErrAmbiguousItem string
-type ErrProvider string
+type ErrDuplicateItem string
+
+// ErrProvider is returned by GetItemList and the getters when a provider fails.
+// It unwraps to the error returned by the provider, e.g. a *ProviderNetworkError, see errors.As.
+type ErrProvider struct {
+	msg string
+	err error
+}
 
 func (e ErrItemNotFound) Error() string {
 	return string(e)
 }
 
+// Is makes the error match ErrNotFound, see errors.Is.
+func (e ErrItemNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 func (e ErrUninitializedItemList) Error() string {
 	return string(e)
 }
@@ -17,6 +47,172 @@ func (e ErrAmbiguousItem) Error() string {
 	return string(e)
 }
 
+// Is makes the error match ErrAmbiguous, see errors.Is.
+func (e ErrAmbiguousItem) Is(target error) bool {
+	return target == ErrAmbiguous
+}
+
 func (e ErrProvider) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the error returned by the provider.
+func (e ErrProvider) Unwrap() error {
+	return e.err
+}
+
+func (e ErrDuplicateItem) Error() string {
 	return string(e)
 }
+
+// ProviderNotFoundError is returned when a provider cannot be instantiated because its factory is unknown.
+type ProviderNotFoundError struct {
+	Name string
+}
+
+func (e *ProviderNotFoundError) Error() string {
+	return fmt.Sprintf("configstore: provider '%s': no such provider factory", e.Name)
+}
+
+// ProviderParseError is returned when the data read by a provider cannot be decoded. This is a permanent failure.
+type ProviderParseError struct {
+	Name     string
+	Filename string
+	Cause    error
+}
+
+func (e *ProviderParseError) Error() string {
+	return fmt.Sprintf("configstore: provider '%s': failed to parse '%s': %v", e.Name, e.Filename, e.Cause)
+}
+
+func (e *ProviderParseError) Unwrap() error {
+	return e.Cause
+}
+
+// ProviderNetworkError is returned when a provider fails to reach a remote source. This is a transient failure.
+type ProviderNetworkError struct {
+	Name  string
+	URL   string
+	Cause error
+}
+
+func (e *ProviderNetworkError) Error() string {
+	return fmt.Sprintf("configstore: provider '%s': request to '%s' failed: %v", e.Name, e.URL, e.Cause)
+}
+
+func (e *ProviderNetworkError) Unwrap() error {
+	return e.Cause
+}
+
+// Temporary reports that the failure is transient, and that retrying may succeed.
+func (e *ProviderNetworkError) Temporary() bool {
+	return true
+}
+
+// ProviderTimeoutError is returned when a provider does not answer in time. This is a transient failure.
+type ProviderTimeoutError struct {
+	Name    string
+	Elapsed time.Duration
+}
+
+func (e *ProviderTimeoutError) Error() string {
+	return fmt.Sprintf("configstore: provider '%s': timed out after %s", e.Name, e.Elapsed)
+}
+
+// Temporary reports that the failure is transient, and that retrying may succeed.
+func (e *ProviderTimeoutError) Temporary() bool {
+	return true
+}
+
+// Timeout reports that the failure is a timeout.
+func (e *ProviderTimeoutError) Timeout() bool {
+	return true
+}
+
+// ValueParseError is returned by the Item.As* accessors when the item value cannot be parsed.
+// The value of sensitive items is redacted.
+type ValueParseError struct {
+	Key   string
+	Value string
+	Type  string
+	Err   error
+}
+
+func (e *ValueParseError) Error() string {
+	return fmt.Sprintf("configstore: item '%s': cannot parse %q as %s: %v", e.Key, e.Value, e.Type, e.Err)
+}
+
+func (e *ValueParseError) Unwrap() error {
+	return e.Err
+}
+
+// AsyncLoadError is returned by WaitReady when some asynchronous providers failed to load, with the error of each of them.
+type AsyncLoadError struct {
+	Errors map[string]error
+}
+
+func (e *AsyncLoadError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for n := range e.Errors {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	msgs := make([]string, 0, len(names))
+	for _, n := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", n, e.Errors[n]))
+	}
+	return fmt.Sprintf("configstore: %d provider(s) failed to load: %s", len(names), strings.Join(msgs, "; "))
+}
+
+// StoreFrozenError is returned (or logged) when a provider is registered or unregistered after the store was frozen,
+// or when a snapshot is restored on it, see Store.Freeze. Name is the provider, if any.
+type StoreFrozenError struct {
+	Name string
+}
+
+func (e *StoreFrozenError) Error() string {
+	if e.Name == "" {
+		return "configstore: store is frozen"
+	}
+	return fmt.Sprintf("configstore: provider '%s': store is frozen", e.Name)
+}
+
+// MissingKeysError is returned by Store.Require, listing the required keys missing from the configuration, in the order they were required.
+type MissingKeysError struct {
+	Keys []string
+}
+
+func (e *MissingKeysError) Error() string {
+	return fmt.Sprintf("configstore: missing required config: %s", strings.Join(e.Keys, ", "))
+}
+
+// SchemaError is returned by Store.Validate, listing every violation of the registered schemas.
+type SchemaError struct {
+	Violations []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("configstore: schema validation: %d violation(s): %s", len(e.Violations), strings.Join(e.Violations, "; "))
+}
+
+// ChecksumMismatchError is returned by the FileWithChecksum provider when the digest of the file does not match the expected one.
+type ChecksumMismatchError struct {
+	Filename  string
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("configstore: file '%s': %s checksum mismatch: expected %s, got %s", e.Filename, e.Algorithm, e.Expected, e.Actual)
+}
+
+// FileTooLargeError is returned when a file, or a downloaded document, exceeds the maximum size set with Store.SetMaxFileSize.
+type FileTooLargeError struct {
+	Name  string
+	Limit int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("configstore: '%s' is too large: exceeds the maximum size of %d bytes", e.Name, e.Limit)
+}
@@ -0,0 +1,109 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderErrorTypes(t *testing.T) {
+	defer func(f func(string, ...interface{})) { LogErrorFunc = f }(LogErrorFunc)
+	LogErrorFunc = nil
+
+	t.Run("not found", func(t *testing.T) {
+		t.Setenv(ConfigEnvVar, "doesnotexist:arg")
+		s := NewStore()
+		s.InitFromEnvironment()
+		_, err := s.GetItemList()
+
+		var e *ProviderNotFoundError
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, "doesnotexist", e.Name)
+
+		var perr ErrProvider
+		assert.True(t, errors.As(err, &perr))
+		assert.Equal(t, err.Error(), perr.Error())
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "broken.yaml")
+		require.NoError(t, os.WriteFile(filename, []byte("- key: foo\n  value: [unterminated\n"), 0600))
+		s := NewStore()
+		s.File(filename)
+		_, err := s.GetItemList()
+
+		var e *ProviderParseError
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, "file:"+filename, e.Name)
+		assert.Equal(t, filename, e.Filename)
+		assert.Error(t, e.Cause)
+		assert.False(t, isTemporary(err))
+	})
+
+	t.Run("network", func(t *testing.T) {
+		cause := errors.New("connection refused")
+		s := NewStore()
+		s.ErrorProvider("consul", &ProviderNetworkError{Name: "consul", URL: "http://localhost:8500", Cause: cause})
+		_, err := s.GetItemList()
+
+		var e *ProviderNetworkError
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, "http://localhost:8500", e.URL)
+		assert.True(t, errors.Is(err, cause))
+		assert.True(t, isTemporary(err))
+
+		// the type assertions written before the errors could be unwrapped keep working
+		perr, ok := err.(ErrProvider)
+		require.True(t, ok, "unexpected error type: %T", err)
+		assert.Equal(t, "configstore: provider 'consul': "+e.Error(), perr.Error())
+		assert.Equal(t, e, perr.Unwrap())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		s := NewStore()
+		s.ErrorProvider("vault", &ProviderTimeoutError{Name: "vault", Elapsed: 2 * time.Second})
+		_, err := s.GetItemList()
+
+		var e *ProviderTimeoutError
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, 2*time.Second, e.Elapsed)
+		assert.True(t, isTemporary(err))
+	})
+}
+
+func isTemporary(err error) bool {
+	var t interface{ Temporary() bool }
+	return errors.As(err, &t) && t.Temporary()
+}
+
+func TestLookupSentinelErrors(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Add(NewItem("dup", "1", 0), NewItem("dup", "2", 0), NewItem("one", "1", 0))
+
+	_, err := s.GetItemValue("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrAmbiguous))
+	assert.Contains(t, err.Error(), "'missing'", "the error names the key")
+	_, err = s.GetFirst("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = s.GetItemValueInt("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = s.Namespace("app.").GetItemValue("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	_, err = l.GetItemValueList("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = s.GetItemValue("dup")
+	assert.True(t, errors.Is(err, ErrAmbiguous))
+	assert.False(t, errors.Is(err, ErrNotFound))
+
+	_, err = s.GetItemValue("one")
+	assert.NoError(t, err)
+}
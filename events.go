@@ -0,0 +1,116 @@
+package configstore
+
+import (
+	"context"
+	"sort"
+)
+
+// EventType is the kind of change described by a ConfigEvent.
+type EventType int
+
+const (
+	// EventAdded is sent when a key appears in the configuration.
+	EventAdded EventType = iota
+	// EventRemoved is sent when a key disappears from the configuration.
+	EventRemoved
+	// EventModified is sent when the value of a key changes.
+	EventModified
+	// EventBatch replaces the events which could not be delivered to a late consumer: the configuration
+	// has to be read again in full. Its Key and values are empty.
+	EventBatch
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdded:
+		return "added"
+	case EventRemoved:
+		return "removed"
+	case EventModified:
+		return "modified"
+	case EventBatch:
+		return "batch"
+	}
+	return "unknown"
+}
+
+// ConfigEvent describes the change of a key, see WatchEvents. The values are those of the item with the highest
+// priority, redacted for sensitive items, and ProviderName is the provider of the new item (of the old one when
+// the key is removed).
+type ConfigEvent struct {
+	Type         EventType
+	Key          string
+	OldValue     string
+	NewValue     string
+	ProviderName string
+}
+
+// Size of the channels returned by WatchEvents.
+const eventBufferSize = 64
+
+// WatchEvents returns a channel receiving one event per changed key every time a provider notifies of a configuration
+// change (see Watch), the item list being compared with the one read on the previous notification. The events of a
+// notification are sorted by key. Delivery never blocks: when the consumer is late and the channel is full, the events
+// are dropped and replaced by a single EventBatch. The channel is closed when the context is cancelled or the store closed.
+func (s *Store) WatchEvents(ctx context.Context) <-chan ConfigEvent {
+	out := make(chan ConfigEvent, eventBufferSize)
+	notif := s.Watch()
+	prev := map[string]Item{}
+	if l, err := s.GetItemList(); err == nil {
+		prev = winningItems(*l)
+	}
+
+	go func() {
+		defer close(out)
+		defer s.unwatch(notif)
+
+		overflow := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.ctx.Done():
+				return
+			case <-notif:
+			}
+			l, err := s.GetItemList()
+			if err != nil {
+				continue
+			}
+			cur := winningItems(*l)
+			for _, e := range configEvents(prev, cur) {
+				// keep the last slot for the batch event
+				if len(out) < cap(out)-1 {
+					overflow = false
+					out <- e
+				} else if !overflow {
+					overflow = true
+					out <- ConfigEvent{Type: EventBatch}
+				}
+			}
+			prev = cur
+		}
+	}()
+	return out
+}
+
+// Returns the events turning the winning items before into the winning items after, sorted by key.
+func configEvents(before, after map[string]Item) []ConfigEvent {
+	var ret []ConfigEvent
+	for k, it := range after {
+		old, ok := before[k]
+		switch {
+		case !ok:
+			ret = append(ret, ConfigEvent{Type: EventAdded, Key: k, NewValue: it.printableValue(), ProviderName: it.source})
+		case old.value != it.value:
+			ret = append(ret, ConfigEvent{Type: EventModified, Key: k, OldValue: old.printableValue(), NewValue: it.printableValue(), ProviderName: it.source})
+		}
+	}
+	for k, it := range before {
+		if _, ok := after[k]; !ok {
+			ret = append(ret, ConfigEvent{Type: EventRemoved, Key: k, OldValue: it.printableValue(), ProviderName: it.source})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
+}
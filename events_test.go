@@ -0,0 +1,78 @@
+package configstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveEvent(t *testing.T, ch <-chan ConfigEvent) ConfigEvent {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+		return ConfigEvent{}
+	}
+}
+
+func TestStoreWatchEvents(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("a", "1", 1).Set("b", "1", 1).AddSecret("password", "old", 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := s.WatchEvents(ctx)
+
+	require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+		p.Replace(NewItem("b", "2", 1), NewItem("c", "1", 1), NewSecretItem("password", "new", 1))
+		return nil
+	}))
+	assert.Equal(t, ConfigEvent{Type: EventRemoved, Key: "a", OldValue: "1", ProviderName: "inmem"}, receiveEvent(t, ch))
+	assert.Equal(t, ConfigEvent{Type: EventModified, Key: "b", OldValue: "1", NewValue: "2", ProviderName: "inmem"}, receiveEvent(t, ch))
+	assert.Equal(t, ConfigEvent{Type: EventAdded, Key: "c", NewValue: "1", ProviderName: "inmem"}, receiveEvent(t, ch))
+	assert.Equal(t, ConfigEvent{Type: EventModified, Key: "password", OldValue: RedactedValue, NewValue: RedactedValue, ProviderName: "inmem"}, receiveEvent(t, ch))
+
+	// a notification without change sends nothing
+	s.NotifyWatchers()
+	select {
+	case e := <-ch:
+		t.Fatalf("unexpected event %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok, "the channel must be closed on cancellation")
+	s.watchersMut.Lock()
+	assert.Empty(t, s.watchers)
+	s.watchersMut.Unlock()
+}
+
+func TestStoreWatchEventsBatch(t *testing.T) {
+	s := NewStore()
+	inmem := s.InMemory("inmem")
+	ch := s.WatchEvents(context.Background())
+	defer s.Close()
+
+	require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+		for i := 0; i < 2*eventBufferSize; i++ {
+			p.Set(fmt.Sprintf("key-%03d", i), "1", 1)
+		}
+		return nil
+	}))
+	require.Eventually(t, func() bool { return len(ch) == eventBufferSize }, 2*time.Second, 10*time.Millisecond)
+	for i := 0; i < eventBufferSize-1; i++ {
+		assert.Equal(t, EventAdded, receiveEvent(t, ch).Type)
+	}
+	assert.Equal(t, EventBatch, receiveEvent(t, ch).Type)
+
+	// the delivery resumes once the consumer caught up
+	inmem.Set("key-000", "2", 1)
+	s.NotifyWatchers()
+	assert.Equal(t, ConfigEvent{Type: EventModified, Key: "key-000", OldValue: "1", NewValue: "2", ProviderName: "inmem"}, receiveEvent(t, ch))
+}
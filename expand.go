@@ -0,0 +1,107 @@
+package configstore
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type envExpansion struct {
+	strict bool
+	nested bool
+}
+
+// An EnvExpansionOption modifies the behavior of the environment variable expansion, see EnableEnvExpansion.
+type EnvExpansionOption func(*envExpansion)
+
+// WithStrictMode makes the environment variable expansion fail on unknown variables,
+// instead of expanding them to an empty string.
+func WithStrictMode() EnvExpansionOption {
+	return func(e *envExpansion) {
+		e.strict = true
+	}
+}
+
+// WithNestedExpansion makes the environment variable expansion also expand the references found in the values of
+// the variables, e.g. ${DATA_DIR} with DATA_DIR=${HOME}/data, a circular reference being an error returned when
+// accessing the item's value. By default, the values of the variables are substituted as is, as a shell does:
+// only enable it if the variables are known not to contain a literal $, e.g. in a password hash.
+func WithNestedExpansion() EnvExpansionOption {
+	return func(e *envExpansion) {
+		e.nested = true
+	}
+}
+
+// EnableEnvExpansion activates the expansion of environment variables in item values:
+// occurrences of ${VAR_NAME} or $VAR_NAME are replaced with the value of the corresponding environment variable,
+// as is (see WithNestedExpansion). A literal $ is written $$.
+// Unknown variables expand to an empty string, unless WithStrictMode is given, in which case
+// the error is returned when accessing the item's value.
+func (s *Store) EnableEnvExpansion(opts ...EnvExpansionOption) {
+	e := &envExpansion{}
+	for _, o := range opts {
+		o(e)
+	}
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.envExpansion = e
+	s.NotifyWatchers()
+}
+
+// Expands the environment variables in the values of the (non-indexed) item list.
+// Must be called with s.pMut held.
+func (s *Store) expandEnv(l *ItemList) {
+	if s.envExpansion == nil {
+		return
+	}
+	for i := range l.Items {
+		it := &l.Items[i]
+		if it.unmarshalErr != nil || !strings.Contains(it.value, "$") {
+			continue
+		}
+		var missing []string
+		v, err := expandEnvValue(it.value, s.envExpansion.nested, nil, &missing)
+		if err != nil {
+			it.unmarshalErr = fmt.Errorf("configstore: item '%s': %v", it.key, err)
+			continue
+		}
+		it.value = v
+		if s.envExpansion.strict && len(missing) > 0 {
+			it.unmarshalErr = fmt.Errorf("configstore: item '%s': undefined environment variable: %s", it.key, strings.Join(missing, ", "))
+		}
+	}
+}
+
+// Expands the environment variables in value, and, if nested, the ones in their own values. chain lists the variables
+// being expanded, to detect the circular references. The unknown variables are added to missing.
+func expandEnvValue(value string, nested bool, chain []string, missing *[]string) (string, error) {
+	var err error
+	ret := os.Expand(value, func(name string) string {
+		switch {
+		case name == "$":
+			return "$"
+		// not a variable name, e.g. a ${config:KEY} reference, see EnableTemplateSubstitution
+		case strings.Contains(name, ":"):
+			return "${" + name + "}"
+		case err != nil:
+			return ""
+		}
+		for _, n := range chain {
+			if n == name {
+				err = fmt.Errorf("circular reference: %s", strings.Join(append(chain, name), " -> "))
+				return ""
+			}
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			*missing = append(*missing, name)
+			return ""
+		}
+		if !nested || !strings.Contains(v, "$") {
+			return v
+		}
+		v, err = expandEnvValue(v, true, append(chain[:len(chain):len(chain)], name), missing)
+		return v
+	})
+	return ret, err
+}
@@ -0,0 +1,105 @@
+package configstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvExpansion(t *testing.T) {
+	t.Setenv("CONFIGSTORE_TEST_ROOT", "/srv")
+	t.Setenv("CONFIGSTORE_TEST_APP", "myapp")
+
+	s := NewStore()
+	s.InMemory("test").Add(
+		NewItem("data-dir", "${CONFIGSTORE_TEST_ROOT}/$CONFIGSTORE_TEST_APP/data", 1),
+		NewItem("plain", "no variable here", 1),
+		NewItem("missing", "${CONFIGSTORE_TEST_UNDEFINED}/data", 1),
+	)
+
+	// disabled by default
+	v, err := s.GetItemValue("data-dir")
+	require.NoError(t, err)
+	assert.Equal(t, "${CONFIGSTORE_TEST_ROOT}/$CONFIGSTORE_TEST_APP/data", v)
+
+	s.EnableEnvExpansion()
+
+	v, err = s.GetItemValue("data-dir")
+	require.NoError(t, err)
+	assert.Equal(t, "/srv/myapp/data", v)
+
+	v, err = s.GetItemValue("plain")
+	require.NoError(t, err)
+	assert.Equal(t, "no variable here", v)
+
+	v, err = s.GetItemValue("missing")
+	require.NoError(t, err)
+	assert.Equal(t, "/data", v)
+}
+
+func TestEnvExpansionStrict(t *testing.T) {
+	t.Setenv("CONFIGSTORE_TEST_ROOT", "/srv")
+
+	s := NewStore()
+	s.InMemory("test").Add(
+		NewItem("data-dir", "${CONFIGSTORE_TEST_ROOT}/data", 1),
+		NewItem("missing", "${CONFIGSTORE_TEST_UNDEFINED}/data", 1),
+	)
+	s.EnableEnvExpansion(WithStrictMode())
+
+	v, err := s.GetItemValue("data-dir")
+	require.NoError(t, err)
+	assert.Equal(t, "/srv/data", v)
+
+	_, err = s.GetItemValue("missing")
+	assert.EqualError(t, err, "configstore: item 'missing': undefined environment variable: CONFIGSTORE_TEST_UNDEFINED")
+}
+
+func TestEnvExpansionVerbatim(t *testing.T) {
+	t.Setenv("CONFIGSTORE_TEST_HASH", "$2a$10$N9qo8uLOickgx2ZMRZoMye")
+	t.Setenv("CONFIGSTORE_TEST_PRICE", "5$$")
+
+	s := NewStore()
+	s.InMemory("test").Add(
+		NewItem("hash", "${CONFIGSTORE_TEST_HASH}", 1),
+		NewItem("price", "$CONFIGSTORE_TEST_PRICE, not $$HOME", 1),
+	)
+	s.EnableEnvExpansion(WithStrictMode())
+
+	// the values of the variables are substituted as is
+	v, err := s.GetItemValue("hash")
+	require.NoError(t, err)
+	assert.Equal(t, "$2a$10$N9qo8uLOickgx2ZMRZoMye", v)
+
+	v, err = s.GetItemValue("price")
+	require.NoError(t, err)
+	assert.Equal(t, "5$$, not $HOME", v)
+}
+
+func TestEnvExpansionNested(t *testing.T) {
+	t.Setenv("CONFIGSTORE_TEST_A", "${CONFIGSTORE_TEST_B}/a")
+	t.Setenv("CONFIGSTORE_TEST_B", "$CONFIGSTORE_TEST_C/b")
+	t.Setenv("CONFIGSTORE_TEST_C", "/c")
+	t.Setenv("CONFIGSTORE_TEST_LOOP1", "x${CONFIGSTORE_TEST_LOOP2}")
+	t.Setenv("CONFIGSTORE_TEST_LOOP2", "${CONFIGSTORE_TEST_LOOP1}")
+
+	s := NewStore()
+	s.InMemory("test").Add(
+		NewItem("chain", "${CONFIGSTORE_TEST_A}/data", 1),
+		NewItem("loop", "${CONFIGSTORE_TEST_LOOP1}", 1),
+	)
+	s.EnableEnvExpansion()
+
+	v, err := s.GetItemValue("chain")
+	require.NoError(t, err)
+	assert.Equal(t, "${CONFIGSTORE_TEST_B}/a/data", v, "nested expansion must be opt-in")
+
+	s.EnableEnvExpansion(WithNestedExpansion())
+	v, err = s.GetItemValue("chain")
+	require.NoError(t, err)
+	assert.Equal(t, "/c/b/a/data", v)
+
+	_, err = s.GetItemValue("loop")
+	assert.EqualError(t, err, "configstore: item 'loop': circular reference: CONFIGSTORE_TEST_LOOP1 -> CONFIGSTORE_TEST_LOOP2 -> CONFIGSTORE_TEST_LOOP1")
+}
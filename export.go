@@ -0,0 +1,178 @@
+package configstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Export returns the merged configuration in the given format, "yaml" (see ExportYAML) or "json".
+// The JSON output is an array of items, in the same form as the YAML one.
+// The values of sensitive items are replaced with RedactedValue.
+func (s *Store) Export(format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		return s.exportYAML(false)
+	case "json":
+		items, err := s.exportItems(false)
+		if err != nil {
+			return nil, err
+		}
+		return json.MarshalIndent(items, "", "  ")
+	}
+	return nil, fmt.Errorf("configstore: export: unsupported format '%s'", format)
+}
+
+// ExportYAML returns the merged configuration as a YAML item list, in the format read by the File provider.
+// Only the items with the highest priority are kept for each key (see ItemFilter.Squash), sorted by key.
+// Item descriptions are written as comments above each entry, in addition to their description field.
+// The values of sensitive items are replaced with RedactedValue, see ExportYAMLWithSecrets.
+func (s *Store) ExportYAML() ([]byte, error) {
+	return s.exportYAML(false)
+}
+
+// ExportYAMLWithSecrets is similar to ExportYAML, but the values of sensitive items are exported as is.
+func (s *Store) ExportYAMLWithSecrets() ([]byte, error) {
+	return s.exportYAML(true)
+}
+
+func (s *Store) exportYAML(secrets bool) ([]byte, error) {
+	items, err := s.exportItems(secrets)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return yaml.Marshal(items)
+	}
+	// marshal the items one by one, to write their description as a comment above them
+	buf := &bytes.Buffer{}
+	for _, it := range items {
+		if it.Description != "" {
+			for _, line := range strings.Split(it.Description, "\n") {
+				fmt.Fprintf(buf, "# %s\n", line)
+			}
+		}
+		b, err := yaml.Marshal([]jsonItem{it})
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+type envExport struct {
+	envFile bool
+}
+
+// An EnvExportOption modifies the output of ExportEnv.
+type EnvExportOption func(*envExport)
+
+// WithEnvFileFormat makes ExportEnv write the values unquoted, for docker's --env-file, which reads the values as is:
+// the shell quoting would be kept in the values. As this format has no escaping, the export fails if a value contains
+// a line break.
+func WithEnvFileFormat() EnvExportOption {
+	return func(e *envExport) {
+		e.envFile = true
+	}
+}
+
+// ExportEnv returns the merged configuration as KEY=value lines, to be sourced by a POSIX shell, or, with
+// WithEnvFileFormat, to be read as an env-file. Only the item with the highest priority is kept for each key,
+// sorted by key. Keys are upper-cased, with dashes (-) and any other character not allowed in a variable name
+// replaced by underscores (_). The Env provider reads these variables back with dashes, so the keys containing
+// other characters, e.g. "db.host", do not round-trip: DB_HOST is read as "db-host".
+// In the shell format, the values containing special characters are single-quoted.
+// The values of sensitive items are replaced with RedactedValue, see ExportEnvWithSecrets.
+func (s *Store) ExportEnv(opts ...EnvExportOption) ([]byte, error) {
+	return s.exportEnv(false, opts)
+}
+
+// ExportEnvWithSecrets is similar to ExportEnv, but the values of sensitive items are exported as is.
+func (s *Store) ExportEnvWithSecrets(opts ...EnvExportOption) ([]byte, error) {
+	return s.exportEnv(true, opts)
+}
+
+func (s *Store) exportEnv(secrets bool, opts []EnvExportOption) ([]byte, error) {
+	e := &envExport{}
+	for _, o := range opts {
+		o(e)
+	}
+	items, err := s.exportItems(secrets)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	seen := map[string]bool{}
+	for _, it := range items {
+		if seen[it.Key] {
+			continue
+		}
+		seen[it.Key] = true
+		v := it.Value
+		if !e.envFile {
+			v = envQuote(v)
+		} else if strings.ContainsAny(v, "\r\n") {
+			return nil, fmt.Errorf("configstore: export: item '%s': line break in value, not supported by the env-file format", it.Key)
+		}
+		fmt.Fprintf(buf, "%s=%s\n", envName(it.Key), v)
+	}
+	return buf.Bytes(), nil
+}
+
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, key)
+}
+
+// Quotes a value for a POSIX shell.
+func envQuote(value string) string {
+	safe := true
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./:@%+,=", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return value
+	}
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// Returns the squashed item list, sorted by key, in its serializable form.
+func (s *Store) exportItems(secrets bool) ([]jsonItem, error) {
+	l, err := s.Filter().Squash().GetItemList()
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]Item, len(l.Items))
+	copy(sorted, l.Items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].key < sorted[j].key
+	})
+
+	ret := make([]jsonItem, 0, len(sorted))
+	for _, it := range sorted {
+		v, err := it.Value()
+		if err != nil {
+			return nil, fmt.Errorf("configstore: export: item '%s': %v", it.key, err)
+		}
+		if it.sensitive && !secrets {
+			v = RedactedValue
+		}
+		ret = append(ret, jsonItem{Key: it.key, Value: v, Priority: it.priority, Tags: it.tags, Description: it.description})
+	}
+	return ret, nil
+}
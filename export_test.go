@@ -0,0 +1,190 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportYAML(t *testing.T) {
+	s := NewStore()
+	s.InMemory("a").
+		Add(NewItem("foo", "low", 1), NewItem("foo", "high", 2), NewItem("bar", "bar value", 1)).
+		AddSecret("password", "s3cr3t", 1)
+
+	out, err := s.ExportYAML()
+	require.NoError(t, err)
+	assert.Equal(t, `- key: bar
+  priority: 1
+  value: bar value
+- key: foo
+  priority: 2
+  value: high
+- key: password
+  priority: 1
+  value: '[REDACTED]'
+`, string(out))
+
+	out, err = s.ExportYAMLWithSecrets()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "value: s3cr3t")
+
+	// the export can be read back by the file provider
+	filename := filepath.Join(t.TempDir(), "export.yaml")
+	require.NoError(t, os.WriteFile(filename, out, 0600))
+	s2 := NewStore()
+	s2.File(filename)
+	for k, v := range map[string]string{"foo": "high", "bar": "bar value", "password": "s3cr3t"} {
+		got, err := s2.GetItemValue(k)
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	}
+}
+
+func TestExportEnv(t *testing.T) {
+	s := NewStore()
+	s.InMemory("a").
+		Add(
+			NewItem("db-host", "localhost", 1),
+			NewItem("db-host", "ignored", 0),
+			NewItem("db.name", "my db", 1),
+			NewItem("motd", "it's $HOME", 1),
+			NewItem("url", "https://example.com/path?a=b", 1),
+		).
+		AddSecret("api_key", "s3cr3t", 1)
+
+	out, err := s.ExportEnv()
+	require.NoError(t, err)
+	assert.Equal(t, `API_KEY='[REDACTED]'
+DB_HOST=localhost
+DB_NAME='my db'
+MOTD='it'\''s $HOME'
+URL='https://example.com/path?a=b'
+`, string(out))
+
+	out, err = s.ExportEnvWithSecrets()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "API_KEY=s3cr3t\n")
+
+	out, err = s.ExportEnv(WithEnvFileFormat())
+	require.NoError(t, err)
+	assert.Equal(t, `API_KEY=[REDACTED]
+DB_HOST=localhost
+DB_NAME=my db
+MOTD=it's $HOME
+URL=https://example.com/path?a=b
+`, string(out))
+
+	// read back through the env provider: the dashes round-trip, the dots do not
+	t.Setenv("CONFIGSTORE_EXPORT_DB_HOST", "localhost")
+	t.Setenv("CONFIGSTORE_EXPORT_DB_NAME", "my db")
+	s2 := NewStore()
+	s2.Env("CONFIGSTORE_EXPORT")
+	v, err := s2.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+	_, err = s2.GetItemValue("db.name")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	v, err = s2.GetItemValue("db-name")
+	require.NoError(t, err)
+	assert.Equal(t, "my db", v)
+
+	s.InMemory("b").Add(NewItem("banner", "line 1\nline 2", 1))
+	_, err = s.ExportEnv(WithEnvFileFormat())
+	assert.Error(t, err)
+	out, err = s.ExportEnv()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "BANNER='line 1\nline 2'\n")
+}
+
+func TestExportYAMLDescription(t *testing.T) {
+	s := NewStore()
+	s.InMemory("defaults").Add(
+		NewItem("db.host", "localhost", 5).WithDescription("PostgreSQL host"),
+		NewItem("db.port", "5432", 5).WithDescription("PostgreSQL port\nDefaults to the standard port"),
+		NewItem("debug", "false", 5),
+	)
+
+	out, err := s.ExportYAML()
+	require.NoError(t, err)
+	assert.Equal(t, `# PostgreSQL host
+- description: PostgreSQL host
+  key: db.host
+  priority: 5
+  value: localhost
+# PostgreSQL port
+# Defaults to the standard port
+- description: |-
+    PostgreSQL port
+    Defaults to the standard port
+  key: db.port
+  priority: 5
+  value: "5432"
+- key: debug
+  priority: 5
+  value: "false"
+`, string(out))
+
+	// descriptions survive a round trip through the file provider
+	filename := filepath.Join(t.TempDir(), "export.yaml")
+	require.NoError(t, os.WriteFile(filename, out, 0600))
+	s2 := NewStore()
+	s2.File(filename)
+	i, err := s2.GetItem("db.host")
+	require.NoError(t, err)
+	assert.Equal(t, "PostgreSQL host", i.Description())
+	i, err = s2.GetItem("debug")
+	require.NoError(t, err)
+	assert.Equal(t, "", i.Description())
+}
+
+func TestExport(t *testing.T) {
+	s := NewStore()
+	s.InMemory("defaults").Add(
+		NewItem("db.host", "localhost", 1).WithDescription("PostgreSQL host"),
+		NewItem("db.port", "5432", 1),
+	)
+	s.InMemory("overrides").
+		Add(NewItem("db.host", "db.example.com", 10)).
+		AddSecret("db.password", "s3cr3t", 10)
+
+	out, err := s.Export("json")
+	require.NoError(t, err)
+	assert.Equal(t, `[
+  {
+    "key": "db.host",
+    "value": "db.example.com",
+    "priority": 10
+  },
+  {
+    "key": "db.password",
+    "value": "[REDACTED]",
+    "priority": 10
+  },
+  {
+    "key": "db.port",
+    "value": "5432",
+    "priority": 1
+  }
+]`, string(out))
+
+	out, err = s.Export("yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `- key: db.host
+  priority: 10
+  value: db.example.com
+- key: db.password
+  priority: 10
+  value: '[REDACTED]'
+- key: db.port
+  priority: 1
+  value: "5432"
+`, string(out))
+
+	_, err = s.Export("toml")
+	assert.EqualError(t, err, "configstore: export: unsupported format 'toml'")
+}
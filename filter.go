@@ -3,6 +3,8 @@ package configstore
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 )
 
@@ -105,6 +107,16 @@ func (s *ItemFilter) GetItemValue(key string) (string, error) {
 	return i.Value()
 }
 
+// GetItemValueList fetches the full item list, applies the filter, then returns the values of all the items
+// sharing that key, ordered by descending priority.
+func (s *ItemFilter) GetItemValueList(key string) ([]string, error) {
+	items, err := s.GetItemList()
+	if err != nil {
+		return []string{}, err
+	}
+	return items.GetItemValueList(key)
+}
+
 // GetItemValueBool fetches the full item list, applies the filter, then returns a single item's value by key.
 func (s *ItemFilter) GetItemValueBool(key string) (bool, error) {
 	i, err := s.GetItem(key)
@@ -180,7 +192,8 @@ func (s *ItemFilter) Apply(items *ItemList) *ItemList {
 func copyItemFilter(s *ItemFilter) *ItemFilter {
 	ret := Filter()
 	if s != nil {
-		ret.funcs = s.funcs
+		// copied, so that the filters derived from the same one do not share their steps
+		ret.funcs = append([]func(*ItemList) *ItemList(nil), s.funcs...)
 		ret.unmarshalType = s.unmarshalType
 		ret.initialKeySlice = s.initialKeySlice
 		ret.store = s.store
@@ -226,17 +239,34 @@ func (s *ItemFilter) Slice(key string, keyF ...func(string) string) *ItemFilter
 	return s
 }
 
+// Prefix filters the list items, keeping only those whose key begins with prefix.
+// Unlike Slice, the keys are kept as is.
+func (s *ItemFilter) Prefix(prefix string) *ItemFilter {
+
+	prefix = transformKey(prefix)
+
+	s = copyItemFilter(s)
+
+	s.funcs = append(s.funcs, func(s *ItemList) *ItemList {
+		ret := &ItemList{}
+		for _, sec := range s.Items {
+			if strings.HasPrefix(sec.key, prefix) {
+				ret.Items = append(ret.Items, sec)
+			}
+		}
+		return ret.index()
+	})
+
+	return s
+}
+
 // Rekey modifies item keys. The function parameter is called for each item in the item list, and the returned string
 // is used as the new key.
 func (s *ItemFilter) Rekey(rekeyF func(*Item) string) *ItemFilter {
 	return s.mapFunc(func(sec *Item) Item {
-		return Item{
-			key:          transformKey(rekeyF(sec)),
-			value:        sec.value,
-			priority:     sec.priority,
-			unmarshaled:  sec.unmarshaled,
-			unmarshalErr: sec.unmarshalErr,
-		}
+		ret := *sec
+		ret.key = transformKey(rekeyF(sec))
+		return ret
 	})
 }
 
@@ -244,13 +274,9 @@ func (s *ItemFilter) Rekey(rekeyF func(*Item) string) *ItemFilter {
 // is used as the new priority.
 func (s *ItemFilter) Reorder(reorderF func(*Item) int64) *ItemFilter {
 	return s.mapFunc(func(sec *Item) Item {
-		return Item{
-			key:          sec.key,
-			value:        sec.value,
-			priority:     reorderF(sec),
-			unmarshaled:  sec.unmarshaled,
-			unmarshalErr: sec.unmarshalErr,
-		}
+		ret := *sec
+		ret.priority = reorderF(sec)
+		return ret
 	})
 }
 
@@ -262,13 +288,10 @@ func (s *ItemFilter) Transform(transformF func(*Item) (string, error)) *ItemFilt
 			return *sec
 		}
 		tr, err := transformF(sec)
-		return Item{
-			key:          sec.key,
-			value:        tr,
-			priority:     sec.priority,
-			unmarshaled:  sec.unmarshaled,
-			unmarshalErr: err,
-		}
+		ret := *sec
+		ret.value = tr
+		ret.unmarshalErr = err
+		return ret
 	})
 }
 
@@ -339,3 +362,105 @@ func (s *ItemFilter) Squash() *ItemFilter {
 
 	return s
 }
+
+// Unique filters the items in the item list, dropping the exact duplicates. See ItemList.Unique.
+func (s *ItemFilter) Unique() *ItemFilter {
+
+	s = copyItemFilter(s)
+
+	s.funcs = append(s.funcs, func(s *ItemList) *ItemList {
+		return s.Unique()
+	})
+
+	return s
+}
+
+// TrimValues removes the leading and trailing white space from the item values. See ItemList.TrimValues.
+func (s *ItemFilter) TrimValues() *ItemFilter {
+
+	s = copyItemFilter(s)
+
+	s.funcs = append(s.funcs, func(s *ItemList) *ItemList {
+		return s.TrimValues()
+	})
+
+	return s
+}
+
+/*
+ ** LIST FILTER
+ */
+
+// ListFilter is a filter bound to an item list, see ItemList.NewFilter.
+// Like ItemFilter, each step returns a new filter, so that a pipeline can be reused or forked.
+type ListFilter struct {
+	list   *ItemList
+	filter *ItemFilter
+}
+
+// NewFilter returns an empty filter operating on the item list, to chain manipulation steps on it:
+//
+//	dbs := items.NewFilter().Keys("db-").Rekey(byName).Squash().Apply()
+//
+// The item list itself is never modified.
+func (s *ItemList) NewFilter() *ListFilter {
+	return &ListFilter{list: s, filter: &ItemFilter{}}
+}
+
+// Keys keeps only the items whose key begins with prefix. See ItemFilter.Prefix.
+func (f *ListFilter) Keys(prefix string) *ListFilter {
+	return &ListFilter{list: f.list, filter: f.filter.Prefix(prefix)}
+}
+
+// Rekey modifies item keys. See ItemFilter.Rekey.
+func (f *ListFilter) Rekey(rekeyF func(*Item) string) *ListFilter {
+	return &ListFilter{list: f.list, filter: f.filter.Rekey(rekeyF)}
+}
+
+// Reorder modifies item priority. See ItemFilter.Reorder.
+func (f *ListFilter) Reorder(reorderF func(*Item) int64) *ListFilter {
+	return &ListFilter{list: f.list, filter: f.filter.Reorder(reorderF)}
+}
+
+// Squash keeps only the items with the highest priority for each key. See ItemFilter.Squash.
+func (f *ListFilter) Squash() *ListFilter {
+	return &ListFilter{list: f.list, filter: f.filter.Squash()}
+}
+
+// Unique drops the exact duplicate items. See ItemList.Unique.
+func (f *ListFilter) Unique() *ListFilter {
+	return &ListFilter{list: f.list, filter: f.filter.Unique()}
+}
+
+// TrimValues removes the leading and trailing white space from the item values. See ItemList.TrimValues.
+func (f *ListFilter) TrimValues() *ListFilter {
+	return &ListFilter{list: f.list, filter: f.filter.TrimValues()}
+}
+
+// Apply runs the steps on a copy of the item list, and returns the result.
+func (f *ListFilter) Apply() *ItemList {
+	l := &ItemList{}
+	if f.list != nil {
+		l.Items = append(l.Items, f.list.Items...)
+	}
+	return f.filter.Apply(l.index())
+}
+
+// Unmarshal runs the steps, then unmarshals (from JSON or YAML) the value of each resulting item into
+// a new element of the slice pointed to by out, e.g. a *[]Database.
+func (f *ListFilter) Unmarshal(out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("configstore: unmarshal: expected a pointer to a slice, got %T", out)
+	}
+	slice := v.Elem()
+	for _, it := range f.Apply().Items {
+		elem := reflect.New(slice.Type().Elem())
+		if err := it.unmarshalValue(elem.Interface()); err != nil {
+			return fmt.Errorf("configstore: unmarshal '%s': %v", it.key, err)
+		}
+		slice = reflect.Append(slice, elem.Elem())
+	}
+	v.Elem().Set(slice)
+	return nil
+}
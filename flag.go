@@ -0,0 +1,70 @@
+package configstore
+
+import (
+	"flag"
+	"fmt"
+)
+
+const (
+	// FlagDefaultPriority is the priority of the items pushed by PopulateFromStdFlags for flags left at their default value.
+	FlagDefaultPriority int64 = 0
+	// FlagChangedPriority is the priority of the items pushed by PopulateFromStdFlags for flags set on the command line,
+	// high enough to override the other usual sources.
+	FlagChangedPriority int64 = 100
+)
+
+// BindStdFlags sets the default value of the flags from the store items with the same (normalized) name.
+// Flags explicitly set on the command line keep their value: BindStdFlags can be called either before or after
+// parsing the command line. Flags with no matching item are left untouched.
+func BindStdFlags(s *Store, fs *flag.FlagSet) error {
+	l, err := s.GetItemList()
+	if err != nil {
+		return err
+	}
+	set := visitedFlags(fs)
+
+	var bindErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		if bindErr != nil || set[f.Name] {
+			return
+		}
+		items := Filter().Slice(f.Name).Apply(l).Items
+		if len(items) == 0 {
+			return
+		}
+		v, err := items[0].Value()
+		if err != nil {
+			bindErr = fmt.Errorf("configstore: flag '%s': %v", f.Name, err)
+			return
+		}
+		if err := f.Value.Set(v); err != nil {
+			bindErr = fmt.Errorf("configstore: flag '%s': invalid value %q: %v", f.Name, v, err)
+			return
+		}
+		f.DefValue = f.Value.String()
+	})
+	return bindErr
+}
+
+// PopulateFromStdFlags pushes the values of all the flags of the set as items.
+// Flags set on the command line get FlagChangedPriority, so that they override the other sources,
+// while flags left at their default value get FlagDefaultPriority.
+func PopulateFromStdFlags(inmem *InMemoryProvider, fs *flag.FlagSet) {
+	set := visitedFlags(fs)
+	fs.VisitAll(func(f *flag.Flag) {
+		priority := FlagDefaultPriority
+		if set[f.Name] {
+			priority = FlagChangedPriority
+		}
+		inmem.Add(NewItem(f.Name, f.Value.String(), priority))
+	})
+}
+
+// Returns the names of the flags explicitly set on the command line.
+func visitedFlags(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
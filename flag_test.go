@@ -0,0 +1,59 @@
+package configstore
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFlagSet() (*flag.FlagSet, *string, *int) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	host := fs.String("db-host", "localhost", "")
+	port := fs.Int("port", 80, "")
+	return fs, host, port
+}
+
+func TestBindStdFlags(t *testing.T) {
+	s := NewStore()
+	s.InMemory("config").Add(NewItem("DB_HOST", "db.example.com", 1), NewItem("port", "5432", 1))
+
+	fs, host, port := newTestFlagSet()
+	require.NoError(t, fs.Parse([]string{"-port=8080"}))
+	require.NoError(t, BindStdFlags(s, fs))
+	assert.Equal(t, "db.example.com", *host, "the store value must override the default")
+	assert.Equal(t, 8080, *port, "the command line must override the store value")
+	assert.Equal(t, "db.example.com", fs.Lookup("db-host").DefValue)
+
+	// before parsing, the command line still wins
+	fs, host, port = newTestFlagSet()
+	require.NoError(t, BindStdFlags(s, fs))
+	require.NoError(t, fs.Parse([]string{"-port=8080"}))
+	assert.Equal(t, "db.example.com", *host)
+	assert.Equal(t, 8080, *port)
+
+	s.InMemory("invalid").Add(NewItem("port", "http", 2))
+	fs, _, _ = newTestFlagSet()
+	assert.Error(t, BindStdFlags(s, fs))
+}
+
+func TestPopulateFromStdFlags(t *testing.T) {
+	fs, _, _ := newTestFlagSet()
+	require.NoError(t, fs.Parse([]string{"-port=8080"}))
+
+	s := NewStore()
+	s.InMemory("config").Add(NewItem("db-host", "db.example.com", 1), NewItem("port", "5432", 1))
+	PopulateFromStdFlags(s.InMemory("flags"), fs)
+
+	// the store value beats the flag default, the command line beats the store value
+	i, err := s.GetFirst("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", mustValue(i))
+	i, err = s.GetFirst("port")
+	require.NoError(t, err)
+	assert.Equal(t, "8080", mustValue(i))
+	assert.Equal(t, FlagChangedPriority, i.Priority())
+}
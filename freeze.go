@@ -0,0 +1,18 @@
+package configstore
+
+// Freeze makes the set of providers of the store final: after it, registering or unregistering a provider
+// is rejected (and logged), and RegisterFromURL and Restore return a *StoreFrozenError. This catches late
+// registrations, e.g. by plugins, which would silently change the configuration.
+// Reads keep working normally, and refreshing providers keep updating their items.
+func (s *Store) Freeze() {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.frozen = true
+}
+
+// Frozen reports whether Freeze was called on the store.
+func (s *Store) Frozen() bool {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	return s.frozen
+}
@@ -0,0 +1,40 @@
+package configstore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreFreeze(t *testing.T) {
+	s := NewStore()
+	inmem := s.InMemory("inmem").Set("a", "1", 1)
+	assert.False(t, s.Frozen())
+
+	s.Freeze()
+	assert.True(t, s.Frozen())
+
+	s.InMemory("late").Set("b", "1", 1)
+	s.RegisterProvider("late2", func() (ItemList, error) {
+		return ItemList{Items: []Item{NewItem("b", "2", 1)}}, nil
+	})
+	_, err := s.GetItemValue("b")
+	assert.Error(t, err, "registration must be rejected")
+
+	s.UnregisterProvider("inmem")
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err, "unregistration must be rejected")
+	assert.Equal(t, "1", v)
+
+	var ferr *StoreFrozenError
+	err = RegisterFromURL(s, "env://CONFIG_")
+	assert.True(t, errors.As(err, &ferr), "unexpected error: %v", err)
+
+	// already registered providers can still be updated
+	inmem.Set("a", "2", 1)
+	v, err = s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v)
+}
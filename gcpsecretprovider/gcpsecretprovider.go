@@ -0,0 +1,159 @@
+// Package gcpsecretprovider reads configstore items from Google Cloud Secret Manager.
+//
+// Each named secret becomes a sensitive item, named after the secret, holding the payload of its latest version:
+//
+//	p := gcpsecretprovider.GCPSecret(configstore.DefaultStore, "my-project", "db-password", "api-key")
+//	defer p.Close()
+//
+// Authentication uses the Application Default Credentials.
+package gcpsecretprovider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/ovh/configstore"
+)
+
+// Priority is the priority of the items, high enough to override the other usual sources.
+const Priority int64 = 100
+
+// SecretClient is the subset of the Secret Manager client used by the provider.
+type SecretClient interface {
+	AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, opts ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error)
+}
+
+var _ SecretClient = (*secretmanager.Client)(nil)
+
+// Provider is a configstore provider serving the latest versions of Secret Manager secrets.
+type Provider struct {
+	store     *configstore.Store
+	client    SecretClient
+	projectID string
+	names     []string
+
+	items    []configstore.Item
+	versions map[string]string
+	mut      sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// GCPSecret registers on the store a provider serving the latest version of each named secret of the project (static content).
+// If a secret cannot be accessed, e.g. if it does not exist or permission is denied, the provider returns an error
+// naming the secret.
+func GCPSecret(s *configstore.Store, projectID string, names ...string) *Provider {
+	return GCPSecretRefresh(s, 0, projectID, names...)
+}
+
+// GCPSecretRefresh is similar to GCPSecret, fetching the latest versions again at the given interval.
+// Watchers get notified when a version changes.
+func GCPSecretRefresh(s *configstore.Store, interval time.Duration, projectID string, names ...string) *Provider {
+	client, err := secretmanager.NewClient(context.Background())
+	if err != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := &Provider{store: s, projectID: projectID, names: names, ctx: ctx, cancel: cancel}
+		s.ErrorProvider(p.Name(), fmt.Errorf("gcpsecretprovider: %v", err))
+		return p
+	}
+	return GCPSecretClient(s, client, interval, projectID, names...)
+}
+
+// GCPSecretClient is similar to GCPSecretRefresh, with a preconfigured client. An interval of 0 disables the refresh.
+func GCPSecretClient(s *configstore.Store, client SecretClient, interval time.Duration, projectID string, names ...string) *Provider {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{
+		store:     s,
+		client:    client,
+		projectID: projectID,
+		names:     names,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	items, versions, err := p.fetch()
+	if err != nil {
+		s.ErrorProvider(p.Name(), err)
+		return p
+	}
+	p.items, p.versions = items, versions
+	s.RegisterProvider(p.Name(), p.Items)
+
+	if interval > 0 {
+		go p.refresh(interval)
+	}
+	return p
+}
+
+// Name returns the name under which the provider is registered.
+func (p *Provider) Name() string {
+	return fmt.Sprintf("gcpsecret:%s", p.projectID)
+}
+
+// Items returns the secrets as items.
+func (p *Provider) Items() (configstore.ItemList, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return configstore.ItemList{Items: append([]configstore.Item(nil), p.items...)}, nil
+}
+
+// Close stops the refresh. The client is not closed.
+func (p *Provider) Close() error {
+	p.cancel()
+	return nil
+}
+
+func (p *Provider) fetch() ([]configstore.Item, map[string]string, error) {
+	items := make([]configstore.Item, 0, len(p.names))
+	versions := make(map[string]string, len(p.names))
+	for _, name := range p.names {
+		resp, err := p.client.AccessSecretVersion(p.ctx, &secretmanagerpb.AccessSecretVersionRequest{
+			Name: fmt.Sprintf("projects/%s/secrets/%s/versions/latest", p.projectID, name),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("gcpsecretprovider: secret '%s': %v", name, err)
+		}
+		items = append(items, configstore.NewSecretItem(name, string(resp.GetPayload().GetData()), Priority))
+		versions[name] = resp.GetName()
+	}
+	return items, versions, nil
+}
+
+func (p *Provider) refresh(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-t.C:
+		}
+		items, versions, err := p.fetch()
+		if err != nil {
+			if configstore.LogErrorFunc != nil {
+				configstore.LogErrorFunc("error: %v", err)
+			}
+			continue
+		}
+		p.mut.Lock()
+		changed := false
+		for name, v := range versions {
+			if p.versions[name] != v {
+				changed = true
+			}
+		}
+		if changed {
+			p.items, p.versions = items, versions
+		}
+		p.mut.Unlock()
+		if changed {
+			p.store.NotifyWatchers()
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package gcpsecretprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type secret struct {
+	version int
+	data    string
+}
+
+type fakeClient struct {
+	mut     sync.Mutex
+	secrets map[string]secret
+}
+
+func (c *fakeClient) set(name, data string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.secrets[name] = secret{version: c.secrets[name].version + 1, data: data}
+}
+
+func (c *fakeClient) AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, opts ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	name := strings.TrimSuffix(req.Name, "/versions/latest")
+	s, ok := c.secrets[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Secret [%s] not found or has no versions.", name)
+	}
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Name:    fmt.Sprintf("%s/versions/%d", name, s.version),
+		Payload: &secretmanagerpb.SecretPayload{Data: []byte(s.data)},
+	}, nil
+}
+
+func TestGCPSecret(t *testing.T) {
+	c := &fakeClient{secrets: map[string]secret{}}
+	c.set("projects/my-project/secrets/db-password", "s3cr3t")
+
+	s := configstore.NewStore()
+	p := GCPSecretClient(s, c, 10*time.Millisecond, "my-project", "db-password")
+	defer p.Close()
+
+	i, err := s.GetItem("db-password")
+	require.NoError(t, err)
+	assert.True(t, i.IsSensitive())
+	assert.Equal(t, Priority, i.Priority())
+	v, err := i.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", v)
+
+	ch := s.Watch()
+	c.set("projects/my-project/secrets/db-password", "n3w")
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification after a new version")
+	}
+	v, err = s.GetItemValue("db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "n3w", v)
+}
+
+func TestGCPSecretNotFound(t *testing.T) {
+	s := configstore.NewStore()
+	GCPSecretClient(s, &fakeClient{secrets: map[string]secret{}}, 0, "my-project", "missing")
+	_, err := s.GetItemList()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret 'missing'")
+	assert.Contains(t, err.Error(), "NotFound")
+}
@@ -0,0 +1,132 @@
+// Package grpcprovider reads configstore items from a server-streaming gRPC call, e.g. the Watch RPC
+// of a central configuration service. Each response received on the stream replaces the whole item set.
+//
+//	cancel, err := grpcprovider.GRPCProvider(configstore.DefaultStore, conn, &pb.WatchRequest{App: "myapp"}, codec{})
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer cancel()
+//
+// The messages are not tied to generated code: the codec names the RPC, instantiates the responses
+// and converts them to items.
+package grpcprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ovh/configstore"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// GRPCCodec describes the server-streaming RPC watched by the provider.
+type GRPCCodec interface {
+	// Method returns the full name of the RPC, e.g. "/config.v1.ConfigService/Watch".
+	Method() string
+	// NewResponse returns an empty response message, into which a received response is decoded.
+	NewResponse() proto.Message
+	// Items converts a received response to the full item set.
+	Items(proto.Message) ([]configstore.Item, error)
+}
+
+// Backoff describes the delay before reopening the stream after it failed: it starts at BaseDelay,
+// and doubles after each consecutive failure, up to MaxDelay. MaxAttempts is ignored, the stream is reopened forever.
+var Backoff = configstore.DefaultRetryPolicy
+
+var streamDesc = &grpc.StreamDesc{ServerStreams: true}
+
+// GRPCProvider registers on the store a provider serving the items of the last response received
+// on the server-streaming RPC described by the codec, called with req. It has no items until the first response.
+// If the stream fails, it is reopened with an exponential backoff (see Backoff), and the last items keep being served.
+// An error is returned if the stream cannot be opened initially, in which case nothing is registered.
+// The returned function closes the stream; the provider keeps serving the last items.
+func GRPCProvider(s *configstore.Store, conn *grpc.ClientConn, req proto.Message, codec GRPCCodec) (cancel func(), err error) {
+	if conn == nil || req == nil || codec == nil {
+		return nil, errors.New("grpcprovider: missing connection, request or codec")
+	}
+	name := fmt.Sprintf("grpc:%s%s", conn.Target(), codec.Method())
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	stream, err := openStream(ctx, conn, req, codec)
+	if err != nil {
+		cancelCtx()
+		return nil, &configstore.ProviderNetworkError{Name: name, URL: conn.Target(), Cause: err}
+	}
+
+	ch := make(chan []configstore.Item)
+	stop := configstore.ChanProvider(s, name, ch)
+	go func() {
+		delay := Backoff.BaseDelay
+		for {
+			err := receive(ctx, stream, codec, ch, func() { delay = Backoff.BaseDelay })
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				logError(&configstore.ProviderNetworkError{Name: name, URL: conn.Target(), Cause: err})
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+				delay *= 2
+				if Backoff.MaxDelay > 0 && delay > Backoff.MaxDelay {
+					delay = Backoff.MaxDelay
+				}
+				if stream, err = openStream(ctx, conn, req, codec); err == nil {
+					break
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancelCtx()
+		stop()
+	}, nil
+}
+
+func openStream(ctx context.Context, conn *grpc.ClientConn, req proto.Message, codec GRPCCodec) (grpc.ClientStream, error) {
+	stream, err := conn.NewStream(ctx, streamDesc, codec.Method())
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Applies the responses received on the stream, until it fails. received is called after each response.
+func receive(ctx context.Context, stream grpc.ClientStream, codec GRPCCodec, ch chan<- []configstore.Item, received func()) error {
+	for {
+		msg := codec.NewResponse()
+		if err := stream.RecvMsg(msg); err != nil {
+			return err
+		}
+		received()
+		items, err := codec.Items(msg)
+		if err != nil {
+			// a bad response does not break the stream
+			logError(fmt.Errorf("grpcprovider: cannot convert response: %v", err))
+			continue
+		}
+		select {
+		case ch <- items:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func logError(err error) {
+	if configstore.LogErrorFunc != nil {
+		configstore.LogErrorFunc("error: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package grpcprovider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The fake configuration server: each stream sends the responses pushed on the channel,
+// and fails when an error is pushed.
+type fakeServer struct {
+	responses chan interface{}
+	streams   chan struct{}
+	ended     chan struct{}
+}
+
+func (f *fakeServer) watch(srv interface{}, stream grpc.ServerStream) error {
+	if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+		return err
+	}
+	f.streams <- struct{}{}
+	defer func() { f.ended <- struct{}{} }()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case r := <-f.responses:
+			if err, ok := r.(error); ok {
+				return err
+			}
+			if err := stream.SendMsg(r); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type structCodec struct{}
+
+func (structCodec) Method() string             { return "/test.Config/Watch" }
+func (structCodec) NewResponse() proto.Message { return &structpb.Struct{} }
+func (structCodec) Items(m proto.Message) ([]configstore.Item, error) {
+	var items []configstore.Item
+	for k, v := range m.(*structpb.Struct).Fields {
+		items = append(items, configstore.NewItem(k, v.GetStringValue(), 1))
+	}
+	return items, nil
+}
+
+func startServer(t *testing.T) (*fakeServer, *grpc.ClientConn) {
+	f := &fakeServer{responses: make(chan interface{}), streams: make(chan struct{}, 10), ended: make(chan struct{}, 10)}
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Config",
+		HandlerType: (*interface{})(nil),
+		Streams:     []grpc.StreamDesc{{StreamName: "Watch", Handler: f.watch, ServerStreams: true}},
+	}, f)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return f, conn
+}
+
+func response(t *testing.T, fields map[string]interface{}) *structpb.Struct {
+	r, err := structpb.NewStruct(fields)
+	require.NoError(t, err)
+	return r
+}
+
+func TestGRPCProvider(t *testing.T) {
+	defer func(b configstore.RetryPolicy) { Backoff = b }(Backoff)
+	Backoff = configstore.RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	f, conn := startServer(t)
+	s := configstore.NewStore()
+	defer s.Close()
+	w := s.Watch()
+
+	cancel, err := GRPCProvider(s, conn, &emptypb.Empty{}, structCodec{})
+	require.NoError(t, err)
+	defer cancel()
+	<-f.streams
+
+	expect := func(key, value string) {
+		t.Helper()
+		select {
+		case <-w:
+		case <-time.After(2 * time.Second):
+			t.Fatal("watchers not notified")
+		}
+		v, err := s.GetItemValue(key)
+		require.NoError(t, err)
+		assert.Equal(t, value, v)
+	}
+
+	<-w // registration
+	f.responses <- response(t, map[string]interface{}{"a": "1", "b": "1"})
+	expect("a", "1")
+	f.responses <- response(t, map[string]interface{}{"a": "2"})
+	expect("a", "2")
+	_, err = s.GetItemValue("b")
+	assert.Error(t, err, "each response must replace the whole item set")
+
+	// the stream is reopened after a failure
+	f.responses <- errors.New("stream broken")
+	<-f.ended
+	select {
+	case <-f.streams:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream not reopened")
+	}
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v, "the last items must be kept while reconnecting")
+	f.responses <- response(t, map[string]interface{}{"a": "3"})
+	expect("a", "3")
+
+	cancel()
+	select {
+	case <-f.ended:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the stream must be closed by cancel")
+	}
+}
+
+func TestGRPCProviderErrors(t *testing.T) {
+	_, conn := startServer(t)
+	s := configstore.NewStore()
+	defer s.Close()
+
+	_, err := GRPCProvider(s, nil, &emptypb.Empty{}, structCodec{})
+	assert.Error(t, err)
+	_, err = GRPCProvider(s, conn, &emptypb.Empty{}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package configstore
+
+import (
+	"sort"
+)
+
+// InMemoryStoreProvider is the name of the provider registered by NewInMemoryStore.
+const InMemoryStoreProvider = "inmemory"
+
+// NewInMemoryStore returns a new store holding the given items, through an in-memory provider named
+// InMemoryStoreProvider. It is meant for tests of configuration-consuming code, to avoid temporary files or
+// environment variables:
+//
+//	s := configstore.NewInMemoryStore(configstore.WithItems(map[string]string{"db-host": "localhost"})...)
+//
+// More items can be added with Transaction.
+func NewInMemoryStore(items ...Item) *Store {
+	s := NewStore()
+	s.InMemory(InMemoryStoreProvider).Add(items...)
+	return s
+}
+
+// WithItems returns an item with a priority of 0 for each key/value pair of the map, sorted by key.
+func WithItems(m map[string]string) []Item {
+	items := make([]Item, 0, len(m))
+	for k, v := range m {
+		items = append(items, NewItem(k, v, 0))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].key < items[j].key
+	})
+	return items
+}
@@ -0,0 +1,38 @@
+package configstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInMemoryStore(t *testing.T) {
+	s := NewInMemoryStore(append(WithItems(map[string]string{"db-host": "localhost", "port": "5432"}),
+		NewItem("port", "5433", 10))...)
+
+	v, err := s.GetItemValue("DB_HOST")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+
+	it, err := s.GetFirst("port")
+	require.NoError(t, err)
+	assert.Equal(t, "5433", mustValue(it))
+	assert.Equal(t, InMemoryStoreProvider, it.Source())
+
+	require.NoError(t, s.Transaction(InMemoryStoreProvider, func(p *InMemoryProvider) error {
+		p.Set("db-host", "remote", 0)
+		return nil
+	}))
+	v, err = s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "remote", v)
+
+	items := WithItems(map[string]string{"b": "2", "a": "1"})
+	require.Len(t, items, 2)
+	assert.Equal(t, "a", items[0].Key())
+
+	l, err := NewInMemoryStore().GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 0, l.Len())
+}
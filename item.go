@@ -1,12 +1,16 @@
 package configstore
 
 import (
+	"bytes"
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ghodss/yaml"
+	yamlv2 "gopkg.in/yaml.v2"
 )
 
 // Item is a key/value pair with a priority attached.
@@ -17,15 +21,119 @@ type Item struct {
 	priority     int64
 	unmarshaled  interface{}
 	unmarshalErr error
+	source       string
+	sensitive    bool
+	tags         map[string]string
+	description  string
+	expires      time.Time
 }
 
+// RedactedValue replaces the value of sensitive items wherever the library prints them.
+const RedactedValue = "[REDACTED]"
+
 // Strictly used for unmarshaling, bypassing the fact that a Item properties are private
 type jsonItem struct {
-	Key      string `json:"key"`
-	Value    string `json:"value"`
-	Priority int64  `json:"priority"`
+	Key         string            `json:"key"`
+	Value       string            `json:"value"`
+	Priority    int64             `json:"priority"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// DefaultFilePriority is the priority given to the items decoded from a file (File, FileList, LoadFromReader, ...)
+// which do not set one, so that hand-written files without priorities rank above the in-code defaults (priority 0)
+// and below the environment (priority 15). An explicit priority of 0 is kept as is.
+// See DecodeItems to use another default for a given provider.
+const DefaultFilePriority int64 = 5
+
+// Strictly used for decoding files, to tell an omitted priority from an explicit 0
+type fileItem struct {
+	Key         string            `json:"key"`
+	Value       string            `json:"value"`
+	Priority    *int64            `json:"priority"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// DecodeItems returns a function decoding the YAML (or JSON) item list read by the File provider, giving the items
+// which do not set a priority the given one instead of DefaultFilePriority. It is meant to be used with the custom
+// file providers, e.g. FileCustom(filename, DecodeItems(10)).
+// The values are kept as written in the file, even when unquoted: version: 1.10 gives "1.10", not "1.1".
+func DecodeItems(defaultPriority int64) func([]byte) ([]Item, error) {
+	return func(b []byte) ([]Item, error) {
+		vals, err := decodeItems(b, defaultPriority, func(b []byte, v interface{}) error { return yaml.Unmarshal(b, v) })
+		if err != nil {
+			return nil, err
+		}
+		preserveScalars(b, vals)
+		return vals, nil
+	}
+}
+
+// Strictly used for decoding files, to read the values as written
+type scalarFileItem struct {
+	Value *string `yaml:"value"`
+}
+
+// Restores the values of the items decoded from YAML as they are written in the file. ghodss/yaml converts YAML
+// to JSON before decoding, which turns the unquoted scalars into JSON numbers and booleans: 1.10 would be read as 1.1,
+// 0755 as 493 and yes as true. yaml.v2 gives the scalar text as is when decoding into a string.
+func preserveScalars(b []byte, vals []Item) {
+	var raw []scalarFileItem
+	if err := yamlv2.Unmarshal(b, &raw); err != nil || len(raw) != len(vals) {
+		return
+	}
+	for i, r := range raw {
+		if r.Value != nil {
+			vals[i].value = *r.Value
+		}
+	}
+}
+
+// Reports the fields of the YAML (or JSON) item list which are not item fields, e.g. a "valeu" typo,
+// which would otherwise be silently ignored and leave the item empty.
+func checkUnknownFields(b []byte) error {
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(j, &raw); err != nil {
+		return err
+	}
+	for i, r := range raw {
+		dec := json.NewDecoder(bytes.NewReader(r))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&fileItem{}); err != nil {
+			return fmt.Errorf("item %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func decodeItems(b []byte, defaultPriority int64, unmarshal func([]byte, interface{}) error) ([]Item, error) {
+	var raw []fileItem
+	if err := unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	vals := make([]Item, 0, len(raw))
+	for _, r := range raw {
+		priority := defaultPriority
+		if r.Priority != nil {
+			priority = *r.Priority
+		}
+		it := NewItem(r.Key, r.Value, priority)
+		it.tags = r.Tags
+		it.description = r.Description
+		vals = append(vals, it)
+	}
+	return vals, nil
 }
 
+// Normalizes an item key: keys are lower-cased, and underscores (_) are replaced with dashes (-).
+// This is applied both when items are created (NewItem, file decoding) and when they are looked up
+// (GetItem, Slice, ...), so that "DB_HOST", "db_host" and "db-host" all refer to the same key
+// regardless of the provider they come from.
 func transformKey(k string) string {
 	k = strings.ToLower(k)
 	k = strings.Replace(k, "_", "-", -1)
@@ -34,10 +142,64 @@ func transformKey(k string) string {
 
 // NewItem creates a item object from key / value / priority values.
 // It is meant to be used by provider implementations.
+// The key is normalized: lower-cased, with underscores (_) replaced by dashes (-).
 func NewItem(key, value string, priority int64) Item {
 	return Item{key: transformKey(key), value: value, priority: priority}
 }
 
+// NewItemWithTags creates a item object from key / value / priority values, with initial tags (see WithTag).
+func NewItemWithTags(key, value string, priority int64, tags map[string]string) Item {
+	i := NewItem(key, value, priority)
+	for k, v := range tags {
+		i = i.WithTag(k, v)
+	}
+	return i
+}
+
+// NewSecretItem creates an item object flagged as sensitive: its value is redacted when the item is printed or logged.
+func NewSecretItem(key, value string, priority int64) Item {
+	i := NewItem(key, value, priority)
+	i.sensitive = true
+	return i
+}
+
+// IsSensitive reports whether the item holds a secret value, see NewSecretItem.
+func (s Item) IsSensitive() bool {
+	return s.sensitive
+}
+
+// String returns a printable description of the item, with the value redacted for sensitive items.
+// The provider which produced the item is included when known, see Source.
+func (s Item) String() string {
+	if s.source != "" {
+		return fmt.Sprintf("%s=%s (priority %d, from %s)", s.key, s.printableValue(), s.priority, s.source)
+	}
+	return fmt.Sprintf("%s=%s (priority %d)", s.key, s.printableValue(), s.priority)
+}
+
+// GoString implements fmt.GoStringer, so that sensitive values do not leak through the %#v verb.
+func (s Item) GoString() string {
+	return fmt.Sprintf("configstore.Item{key: %q, value: %q, priority: %d}", s.key, s.printableValue(), s.priority)
+}
+
+func (s Item) printableValue() string {
+	if s.sensitive {
+		return RedactedValue
+	}
+	return s.value
+}
+
+// Removes the value of sensitive items from the parse errors, which usually embed the invalid input.
+func (s Item) parseError(err error) error {
+	if err == nil || !s.sensitive {
+		return err
+	}
+	if numErr, ok := err.(*strconv.NumError); ok {
+		return &strconv.NumError{Func: numErr.Func, Num: RedactedValue, Err: numErr.Err}
+	}
+	return fmt.Errorf("configstore: item '%s': invalid value %s", s.key, RedactedValue)
+}
+
 // UnmarshalJSON respects json.Unmarshaler
 func (s *Item) UnmarshalJSON(b []byte) error {
 	j := &jsonItem{}
@@ -48,9 +210,67 @@ func (s *Item) UnmarshalJSON(b []byte) error {
 	s.key = transformKey(j.Key)
 	s.value = j.Value
 	s.priority = j.Priority
+	s.tags = j.Tags
+	s.description = j.Description
 	return nil
 }
 
+// MarshalJSON respects json.Marshaler. Items are serialized with the following stable field names:
+//
+//	{"key": "db-host", "value": "localhost", "priority": 10, "source": "file:/etc/app.yaml"}
+//
+// with the optional "tags" and "description" fields when set. The value of sensitive items is replaced with RedactedValue.
+// The output can be read back by UnmarshalJSON, the source being ignored.
+func (s Item) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Key         string            `json:"key"`
+		Value       string            `json:"value"`
+		Priority    int64             `json:"priority"`
+		Source      string            `json:"source"`
+		Tags        map[string]string `json:"tags,omitempty"`
+		Description string            `json:"description,omitempty"`
+	}{s.key, s.printableValue(), s.priority, s.source, s.tags, s.description})
+}
+
+// WithTag returns a copy of the item with a metadata tag attached (e.g. source: vault, rotates: true).
+// Tags do not affect the item value, they are meant for observability and routing.
+func (s Item) WithTag(key, value string) Item {
+	tags := make(map[string]string, len(s.tags)+1)
+	for k, v := range s.tags {
+		tags[k] = v
+	}
+	tags[key] = value
+	s.tags = tags
+	return s
+}
+
+// Tag returns the value of a metadata tag, and whether it is set on the item.
+func (s Item) Tag(key string) (string, bool) {
+	v, ok := s.tags[key]
+	return v, ok
+}
+
+// Tags returns a copy of the item metadata tags.
+func (s Item) Tags() map[string]string {
+	tags := make(map[string]string, len(s.tags))
+	for k, v := range s.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// WithDescription returns a copy of the item documented with a human readable description,
+// written as a comment by ExportYAML.
+func (s Item) WithDescription(desc string) Item {
+	s.description = desc
+	return s
+}
+
+// Description returns the item description, if any.
+func (s Item) Description() string {
+	return s.description
+}
+
 // Key returns the item key.
 func (s *Item) Key() string {
 	return s.key
@@ -67,7 +287,24 @@ func (s Item) ValueBool() (bool, error) {
 		return false, s.unmarshalErr
 	}
 
-	return strconv.ParseBool(s.value)
+	v, err := parseBool(s.value)
+	return v, s.parseError(err)
+}
+
+// Parses a boolean as strconv.ParseBool does, also accepting the YAML 1.1 words (yes, no, on, off, y, n),
+// which the files keep as written, see DecodeItems.
+func parseBool(str string) (bool, error) {
+	v, err := strconv.ParseBool(str)
+	if err == nil {
+		return v, nil
+	}
+	switch str {
+	case "y", "Y", "yes", "Yes", "YES", "on", "On", "ON":
+		return true, nil
+	case "n", "N", "no", "No", "NO", "off", "Off", "OFF":
+		return false, nil
+	}
+	return false, err
 }
 
 // ValueFloat returns the item value, along with any error that was encountered in list processing (unmarshal, transform).
@@ -76,7 +313,8 @@ func (s Item) ValueFloat() (float64, error) {
 		return 0, s.unmarshalErr
 	}
 
-	return strconv.ParseFloat(s.value, 64)
+	v, err := strconv.ParseFloat(s.value, 64)
+	return v, s.parseError(err)
 }
 
 // ValueInt returns the item value, along with any error that was encountered in list processing (unmarshal, transform).
@@ -85,7 +323,8 @@ func (s Item) ValueInt() (int64, error) {
 		return 0, s.unmarshalErr
 	}
 
-	return strconv.ParseInt(s.value, 10, 64)
+	v, err := strconv.ParseInt(s.value, 10, 64)
+	return v, s.parseError(err)
 }
 
 // ValueUint returns the item value, along with any error that was encountered in list processing (unmarshal, transform).
@@ -94,7 +333,8 @@ func (s Item) ValueUint() (uint64, error) {
 		return 0, s.unmarshalErr
 	}
 
-	return strconv.ParseUint(s.value, 10, 64)
+	v, err := strconv.ParseUint(s.value, 10, 64)
+	return v, s.parseError(err)
 }
 
 // ValueDuration returns the item value, along with any error that was encountered in list processing (unmarshal, transform).
@@ -103,7 +343,8 @@ func (s Item) ValueDuration() (time.Duration, error) {
 		return time.Duration(0), s.unmarshalErr
 	}
 
-	return time.ParseDuration(s.value)
+	v, err := time.ParseDuration(s.value)
+	return v, s.parseError(err)
 }
 
 // ValueBytes returns the item value, along with any error that was encountered in list processing (unmarshal, transform).
@@ -116,11 +357,83 @@ func (s Item) ValueBytes() ([]byte, error) {
 	return base64.StdEncoding.DecodeString(s.value)
 }
 
+// AsInt returns the item value parsed as a base 10 integer. Parse errors are returned as *ValueParseError,
+// which includes the item key and raw value.
+func (s Item) AsInt() (int64, error) {
+	if s.unmarshalErr != nil {
+		return 0, s.unmarshalErr
+	}
+	v, err := strconv.ParseInt(s.value, 10, 64)
+	return v, s.valueParseError("int", err)
+}
+
+// MustAsInt is similar to AsInt, but panics if the value cannot be parsed.
+func (s Item) MustAsInt() int64 {
+	v, err := s.AsInt()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// AsUint64 returns the item value parsed as a base 10 unsigned integer. Parse errors are returned as *ValueParseError,
+// which includes the item key and raw value.
+func (s Item) AsUint64() (uint64, error) {
+	if s.unmarshalErr != nil {
+		return 0, s.unmarshalErr
+	}
+	v, err := strconv.ParseUint(s.value, 10, 64)
+	return v, s.valueParseError("uint64", err)
+}
+
+// AsBool returns the item value parsed as a boolean (see strconv.ParseBool, the YAML words yes, no, on and off are accepted too). Parse errors are returned as *ValueParseError,
+// which includes the item key and raw value.
+func (s Item) AsBool() (bool, error) {
+	if s.unmarshalErr != nil {
+		return false, s.unmarshalErr
+	}
+	v, err := parseBool(s.value)
+	return v, s.valueParseError("bool", err)
+}
+
+// AsFloat64 returns the item value parsed as a floating point number. Parse errors are returned as *ValueParseError,
+// which includes the item key and raw value.
+func (s Item) AsFloat64() (float64, error) {
+	if s.unmarshalErr != nil {
+		return 0, s.unmarshalErr
+	}
+	v, err := strconv.ParseFloat(s.value, 64)
+	return v, s.valueParseError("float64", err)
+}
+
+// AsDuration returns the item value parsed as a duration (see time.ParseDuration). Parse errors are returned as *ValueParseError,
+// which includes the item key and raw value.
+func (s Item) AsDuration() (time.Duration, error) {
+	if s.unmarshalErr != nil {
+		return 0, s.unmarshalErr
+	}
+	v, err := time.ParseDuration(s.value)
+	return v, s.valueParseError("duration", err)
+}
+
+func (s Item) valueParseError(typ string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ValueParseError{Key: s.key, Value: s.printableValue(), Type: typ, Err: s.parseError(err)}
+}
+
 // Priority returns the item priority.
 func (s Item) Priority() int64 {
 	return s.priority
 }
 
+// Source returns the name of the provider which produced the item, e.g. "file:/etc/app.yaml".
+// It is set when the item is retrieved from a store, and empty otherwise.
+func (s Item) Source() string {
+	return s.source
+}
+
 // Tries to unmarshal (from JSON or YAML) the item value into i.
 // The result and error are stored within the item object, to be handled later.
 func (s *Item) storeUnmarshal(i interface{}) {
@@ -135,6 +448,15 @@ func (s *Item) storeUnmarshal(i interface{}) {
 	s.unmarshaled = i
 }
 
+// Unmarshals (from JSON or YAML) the item value into v, along with any error
+// that was encountered in list processing (unmarshal, transform).
+func (s Item) unmarshalValue(v interface{}) error {
+	if s.unmarshalErr != nil {
+		return s.unmarshalErr
+	}
+	return yaml.Unmarshal([]byte(s.value), v)
+}
+
 // Unmarshaled returns the unmarshaled object produced by ItemFilter.Unmarshal, along with any error
 // that was encountered in list processing (unmarshal, transform).
 func (s Item) Unmarshaled() (interface{}, error) {
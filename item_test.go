@@ -0,0 +1,288 @@
+package configstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretItem(t *testing.T) {
+	var logs strings.Builder
+	capture := func(format string, args ...interface{}) {
+		fmt.Fprintf(&logs, format+"\n", args...)
+	}
+	defer func(info, err func(string, ...interface{})) { LogInfoFunc, LogErrorFunc = info, err }(LogInfoFunc, LogErrorFunc)
+	LogInfoFunc, LogErrorFunc = capture, capture
+
+	t.Setenv("CONFIGSTORE_SECRET_TEST_PLAIN", "visible")
+
+	s := NewStore()
+	s.Env("CONFIGSTORE_SECRET_TEST")
+	s.InMemory("secrets").
+		AddSecret("token", "s3cr3t-t0ken", 1).
+		AddSecret("max-conns", "s3cr3t-int", 1)
+
+	items, err := s.GetItemList()
+	require.NoError(t, err)
+
+	token, err := items.GetItem("token")
+	require.NoError(t, err)
+	assert.True(t, token.IsSensitive())
+	v, err := token.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t-t0ken", v, "the value must still be accessible")
+
+	for _, i := range items.Items {
+		LogInfoFunc("item: %v", i)
+		LogInfoFunc("item: %s", i)
+		LogInfoFunc("item: %#v", i)
+		LogInfoFunc("item: %+v", i)
+	}
+	s.GetItemValueIntOr("max-conns", 10)
+
+	assert.Contains(t, logs.String(), "plain=visible")
+	assert.Contains(t, logs.String(), "token="+RedactedValue)
+	assert.Contains(t, logs.String(), "get 'max-conns': using default value")
+	assert.NotContains(t, logs.String(), "s3cr3t")
+}
+
+func TestItemAs(t *testing.T) {
+	assert := assert.New(t)
+
+	i, err := NewItem("n", "-42", 0).AsInt()
+	assert.NoError(err)
+	assert.Equal(int64(-42), i)
+	i, err = NewItem("n", "9223372036854775807", 0).AsInt()
+	assert.NoError(err)
+	assert.Equal(int64(math.MaxInt64), i)
+	i, err = NewItem("n", "-9223372036854775808", 0).AsInt()
+	assert.NoError(err)
+	assert.Equal(int64(math.MinInt64), i)
+	assert.Equal(int64(7), NewItem("n", "7", 0).MustAsInt())
+
+	u, err := NewItem("n", "18446744073709551615", 0).AsUint64()
+	assert.NoError(err)
+	assert.Equal(uint64(math.MaxUint64), u)
+
+	b, err := NewItem("b", "true", 0).AsBool()
+	assert.NoError(err)
+	assert.True(b)
+
+	f, err := NewItem("f", "42.42", 0).AsFloat64()
+	assert.NoError(err)
+	assert.Equal(42.42, f)
+
+	d, err := NewItem("d", "1m30s", 0).AsDuration()
+	assert.NoError(err)
+	assert.Equal(90*time.Second, d)
+
+	// invalid values
+	_, err = NewItem("port", "9223372036854775808", 0).AsInt()
+	var perr *ValueParseError
+	if assert.True(errors.As(err, &perr)) {
+		assert.Equal("port", perr.Key)
+		assert.Equal("9223372036854775808", perr.Value)
+		assert.Equal("int", perr.Type)
+		assert.True(errors.Is(err, strconv.ErrRange))
+	}
+	_, err = NewItem("n", "-1", 0).AsUint64()
+	assert.True(errors.As(err, &perr))
+	_, err = NewItem("enabled", "yes please", 0).AsBool()
+	assert.EqualError(err, `configstore: item 'enabled': cannot parse "yes please" as bool: strconv.ParseBool: parsing "yes please": invalid syntax`)
+	_, err = NewItem("f", "4,2", 0).AsFloat64()
+	assert.True(errors.As(err, &perr))
+	_, err = NewItem("timeout", "10", 0).AsDuration()
+	assert.True(errors.As(err, &perr))
+	assert.Panics(func() { NewItem("n", "abc", 0).MustAsInt() })
+
+	// the value of sensitive items is not disclosed
+	_, err = NewSecretItem("pin", "12a4", 0).AsInt()
+	assert.NotContains(err.Error(), "12a4")
+}
+
+func TestItemTags(t *testing.T) {
+	i := NewItem("token", "abc", 1).WithTag("source", "vault")
+	j := i.WithTag("rotates", "true")
+
+	v, ok := j.Tag("source")
+	assert.True(t, ok)
+	assert.Equal(t, "vault", v)
+	_, ok = i.Tag("rotates")
+	assert.False(t, ok, "WithTag must not modify the original item")
+	assert.Equal(t, map[string]string{"source": "vault", "rotates": "true"}, j.Tags())
+
+	tags := j.Tags()
+	tags["source"] = "modified"
+	v, _ = j.Tag("source")
+	assert.Equal(t, "vault", v, "Tags must return a copy")
+
+	i = NewItemWithTags("token", "abc", 1, map[string]string{"env": "production"})
+	v, _ = i.Tag("env")
+	assert.Equal(t, "production", v)
+}
+
+func TestItemTagsRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(`- key: db-host
+  value: localhost
+  priority: 1
+  tags:
+    env: production
+`), 0600))
+
+	s := NewStore()
+	s.File(filename)
+	s.InMemory("defaults").Add(NewItem("db-host", "default", 0).WithTag("env", "default"))
+
+	// tags survive the merge and the filters
+	i, err := s.Filter().Squash().Reorder(func(i *Item) int64 { return i.Priority() + 1 }).GetItem("db-host")
+	require.NoError(t, err)
+	v, ok := i.Tag("env")
+	assert.True(t, ok)
+	assert.Equal(t, "production", v)
+
+	out, err := s.ExportYAML()
+	require.NoError(t, err)
+	assert.Equal(t, `- key: db-host
+  priority: 1
+  tags:
+    env: production
+  value: localhost
+`, string(out))
+}
+
+func TestItemMarshalJSON(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Add(
+		NewItem("db-host", "localhost", 10).WithDescription("database host"),
+		NewSecretItem("db-password", "hunter2", 10),
+	)
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+
+	b, err := json.Marshal(l)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"key": "db-host", "value": "localhost", "priority": 10, "source": "inmem", "description": "database host"},
+		{"key": "db-password", "value": "[REDACTED]", "priority": 10, "source": "inmem"}
+	]`, string(b))
+
+	// the same shape is used for a list value and for a single item
+	b2, err := json.Marshal(*l)
+	require.NoError(t, err)
+	assert.Equal(t, string(b), string(b2))
+	b, err = json.Marshal(NewItem("port", "5432", 0))
+	require.NoError(t, err)
+	assert.Equal(t, `{"key":"port","value":"5432","priority":0,"source":""}`, string(b))
+
+	b, err = json.Marshal(&ItemList{})
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(b))
+
+	var it Item
+	require.NoError(t, json.Unmarshal([]byte(`{"key":"port","value":"5432","priority":3,"source":"inmem"}`), &it))
+	assert.Equal(t, NewItem("port", "5432", 3), it)
+}
+
+func TestFileDefaultPriority(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: omitted\n  value: a\n- key: zero\n  value: b\n  priority: 0\n- key: set\n  value: c\n  priority: 12\n"), 0600))
+
+	s := NewStore()
+	s.File(filename)
+	for key, priority := range map[string]int64{"omitted": DefaultFilePriority, "zero": 0, "set": 12} {
+		it, err := s.GetItem(key)
+		require.NoError(t, err)
+		assert.Equal(t, priority, it.Priority(), key)
+	}
+
+	s = NewStore()
+	s.FileCustom(filename, DecodeItems(10))
+	it, err := s.GetItem("omitted")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), it.Priority())
+	it, err = s.GetItem("zero")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), it.Priority())
+}
+
+func TestFileScalarValues(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.yaml")
+	content := `- key: version
+  value: 1.10
+- key: mode
+  value: 0755
+- key: enabled
+  value: yes
+- key: disabled
+  value: no
+- key: quoted
+  value: "1.10"
+- key: empty
+  value: ~
+`
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0600))
+
+	s := NewStore()
+	s.File(filename)
+	for key, expected := range map[string]string{"version": "1.10", "mode": "0755", "enabled": "yes", "disabled": "no", "quoted": "1.10", "empty": ""} {
+		v, err := s.GetItemValue(key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, v, key)
+	}
+
+	b, err := s.GetItemValueBool("enabled")
+	require.NoError(t, err)
+	assert.True(t, b)
+	b, err = s.GetItemValueBool("disabled")
+	require.NoError(t, err)
+	assert.False(t, b)
+}
+
+func TestStrictDecoding(t *testing.T) {
+	var logs strings.Builder
+	defer func(f func(string, ...interface{})) { LogErrorFunc = f }(LogErrorFunc)
+	LogErrorFunc = func(format string, args ...interface{}) {
+		fmt.Fprintf(&logs, format+"\n", args...)
+	}
+
+	filename := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: db-host\n  value: localhost\n- key: port\n  valeu: 5432\n"), 0600))
+
+	// by default, unknown fields are logged and ignored
+	s := NewStore()
+	s.File(filename)
+	v, err := s.GetItemValue("port")
+	require.NoError(t, err)
+	assert.Equal(t, "", v)
+	assert.Contains(t, logs.String(), `item 2: json: unknown field "valeu"`)
+
+	s = NewStore()
+	s.SetStrictDecoding(true)
+	s.File(filename)
+	_, err = s.GetItemValue("db-host")
+	var perr *ProviderParseError
+	require.True(t, errors.As(err, &perr), "unexpected error: %v", err)
+	assert.Contains(t, err.Error(), `item 2: json: unknown field "valeu"`)
+
+	err = s.LoadFromReader("body", strings.NewReader(`[{"key": "port", "valeu": "5432"}]`), "json")
+	assert.True(t, errors.As(err, &perr), "unexpected error: %v", err)
+
+	require.NoError(t, os.WriteFile(filename, []byte("- key: db-host\n  value: localhost\n  priority: 1\n  tags: {team: db}\n  description: database host\n"), 0600))
+	s = NewStore()
+	s.SetStrictDecoding(true)
+	s.File(filename)
+	v, err = s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+}
@@ -0,0 +1,56 @@
+package configstore
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+)
+
+// DefaultMaxFileSize is the maximum size of the files read by the providers, unless changed with SetMaxFileSize.
+const DefaultMaxFileSize int64 = 16 << 20
+
+// SetMaxFileSize changes the maximum size of the files read by the providers of the store (File, FileList,
+// LoadFromReader, ...), and of the documents downloaded by the network providers, so that a malformed or malicious
+// input is rejected with a *FileTooLargeError instead of being loaded into memory. 0 restores DefaultMaxFileSize,
+// a negative size disables the limit. It applies to the reads which happen afterwards, including refreshes.
+func (s *Store) SetMaxFileSize(n int64) {
+	atomic.StoreInt64(&s.maxFileSize, n)
+}
+
+// MaxFileSize returns the maximum size of the files read by the providers of the store, or a negative value
+// if there is no limit. See SetMaxFileSize.
+func (s *Store) MaxFileSize() int64 {
+	if n := atomic.LoadInt64(&s.maxFileSize); n != 0 {
+		return n
+	}
+	return DefaultMaxFileSize
+}
+
+// ReadAllLimited reads all the data from r, as ioutil.ReadAll, but returns a *FileTooLargeError as soon as
+// the maximum file size of the store is exceeded (see SetMaxFileSize). The name identifies the data in the error,
+// e.g. a file name or a URL. It is meant to be used by the provider implementations.
+func (s *Store) ReadAllLimited(name string, r io.Reader) ([]byte, error) {
+	limit := s.MaxFileSize()
+	if limit < 0 {
+		return ioutil.ReadAll(r)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, &FileTooLargeError{Name: name, Limit: limit}
+	}
+	return b, nil
+}
+
+// Reads a file, up to the maximum file size of the store.
+func (s *Store) readFileLimited(filename string) ([]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return s.ReadAllLimited(filename, f)
+}
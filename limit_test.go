@@ -0,0 +1,40 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreMaxFileSize(t *testing.T) {
+	content := "- key: a\n  value: \"1\"\n"
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0600))
+
+	s := NewStore()
+	assert.Equal(t, DefaultMaxFileSize, s.MaxFileSize())
+	s.SetMaxFileSize(int64(len(content)))
+	s.File(filename)
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v, "a file of the maximum size is accepted")
+
+	s = NewStore()
+	s.SetMaxFileSize(int64(len(content)) - 1)
+	s.File(filename)
+	_, err = s.GetItemList()
+	var tooLarge *FileTooLargeError
+	require.True(t, errors.As(err, &tooLarge), "unexpected error: %v", err)
+	assert.Equal(t, filename, tooLarge.Name)
+
+	err = s.LoadFromReader("stdin", strings.NewReader(content), "yaml")
+	assert.True(t, errors.As(err, &tooLarge), "unexpected error: %v", err)
+
+	s.SetMaxFileSize(-1)
+	assert.NoError(t, s.LoadFromReader("stdin", strings.NewReader(content), "yaml"))
+}
@@ -1,8 +1,10 @@
 package configstore
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -12,20 +14,76 @@ type ItemList struct {
 	indexed map[string][]Item
 }
 
-// Keys returns a list of the different keys present in the item list.
+// MarshalJSON respects json.Marshaler. The list is serialized as an array of items, see Item.MarshalJSON.
+func (s ItemList) MarshalJSON() ([]byte, error) {
+	if s.Items == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s.Items)
+}
+
+// Keys returns the sorted list of the different keys present in the item list, each key appearing once
+// however many items share it.
 func (s *ItemList) Keys() []string {
 	if s == nil {
 		return nil
 	}
 
 	ret := []string{}
-	for k := range s.indexed {
-		ret = append(ret, k)
+	seen := map[string]bool{}
+	for _, it := range s.Items {
+		if !seen[it.key] {
+			seen[it.key] = true
+			ret = append(ret, it.key)
+		}
 	}
+	sort.Strings(ret)
 
 	return ret
 }
 
+// Unique returns a copy of the item list without the exact duplicates, i.e. the items sharing the same key, value
+// and priority, e.g. when the same file is loaded twice. The first occurrence is kept, and the order of the items is preserved.
+// Unlike Squash, items sharing a key with different values or priorities are all kept.
+func (s *ItemList) Unique() *ItemList {
+	ret := &ItemList{}
+	if s == nil {
+		return ret.index()
+	}
+	type triple struct {
+		key      string
+		value    string
+		priority int64
+	}
+	seen := map[triple]bool{}
+	for _, it := range s.Items {
+		t := triple{it.key, it.value, it.priority}
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		ret.Items = append(ret.Items, it)
+	}
+	return ret.index()
+}
+
+// TrimValues returns a copy of the item list with the leading and trailing white space removed from the item values,
+// e.g. the final newline of a YAML block scalar (value: |) or of a secret file. This is opt-in, as some values embed
+// white space on purpose. The files are decoded with their values kept as written (see DecodeItems), so trimming
+// applies to that text: the quotes of a YAML quoted string are not part of the value, and "  1.10 " gives "1.10".
+func (s *ItemList) TrimValues() *ItemList {
+	ret := &ItemList{}
+	if s == nil {
+		return ret.index()
+	}
+	ret.Items = make([]Item, 0, len(s.Items))
+	for _, it := range s.Items {
+		it.value = strings.TrimSpace(it.value)
+		ret.Items = append(ret.Items, it)
+	}
+	return ret.index()
+}
+
 // GetItem returns a single item, by key.
 // If 0 or >=2 items are present with that key, it will return an error.
 func (s *ItemList) GetItem(key string) (Item, error) {
@@ -46,6 +104,31 @@ func (s *ItemList) GetItem(key string) (Item, error) {
 	return Item{}, ErrAmbiguousItem(fmt.Sprintf("configstore: get '%s': ambiguous, %d items share that key", key, len(l.Items)))
 }
 
+// GetItemValueList returns the values of all the items sharing that key, ordered by descending priority.
+// This is meant for keys which are legitimately repeated, e.g. a list of upstreams.
+// If no item is present with that key, it will return an empty list and an ErrItemNotFound error.
+func (s *ItemList) GetItemValueList(key string) ([]string, error) {
+
+	if s == nil {
+		return []string{}, ErrUninitializedItemList(fmt.Sprintf("configstore: get '%s': non-initialized item list", key))
+	}
+
+	l := (&ItemFilter{}).Slice(key).Apply(s)
+	if len(l.Items) == 0 {
+		return []string{}, ErrItemNotFound(fmt.Sprintf("configstore: get '%s': no item found", key))
+	}
+
+	ret := make([]string, 0, len(l.Items))
+	for _, i := range l.Items {
+		v, err := i.Value()
+		if err != nil {
+			return []string{}, err
+		}
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
+
 // GetItemValue returns a single item value, by key.
 // If 0 or >=2 items are present with that key, it will return an error.
 func (s *ItemList) GetItemValue(key string) (string, error) {
@@ -130,11 +213,12 @@ func (s *ItemList) Swap(i, j int) {
 }
 
 // Indexes the items of the list by key for easy access.
+// The items are sorted by decreasing priority, items sharing a priority keeping their relative order.
 func (s *ItemList) index() *ItemList {
 	if s.indexed != nil {
 		return s
 	}
-	sort.Sort(s)
+	sort.Stable(s)
 	s.indexed = map[string][]Item{}
 	for _, sec := range s.Items {
 		s.indexed[sec.key] = append(s.indexed[sec.key], sec)
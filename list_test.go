@@ -0,0 +1,156 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemListUnique(t *testing.T) {
+	l := (&ItemList{Items: []Item{
+		NewItem("a", "1", 1),
+		NewItem("b", "1", 1),
+		NewItem("a", "1", 1),
+		NewItem("a", "2", 1),
+		NewItem("a", "1", 2),
+		NewItem("b", "1", 1),
+	}}).index()
+
+	u := l.Unique()
+	assert.Equal(t, 6, l.Len(), "the original list must not be modified")
+	require.Equal(t, 4, u.Len())
+	var got []string
+	for _, it := range u.Items {
+		got = append(got, it.Key()+"="+mustValue(it))
+	}
+	assert.Equal(t, []string{"a=1", "a=1", "b=1", "a=2"}, got)
+	assert.Equal(t, int64(2), u.Items[0].Priority())
+
+	vals, err := u.GetItemValueList("a")
+	require.NoError(t, err)
+	assert.Len(t, vals, 3)
+
+	assert.Equal(t, 0, (*ItemList)(nil).Unique().Len())
+}
+
+func TestItemFilterUnique(t *testing.T) {
+	s := NewStore()
+	s.InMemory("first").Add(NewItem("a", "1", 1), NewItem("b", "1", 1))
+	s.InMemory("second").Add(NewItem("a", "1", 1), NewItem("a", "2", 5))
+
+	l, err := Filter().Store(s).Unique().Slice("a").GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 2, l.Len())
+
+	v, err := Filter().Store(s).Unique().Squash().GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v)
+}
+
+func TestItemListKeys(t *testing.T) {
+	l := &ItemList{Items: []Item{
+		NewItem("b", "1", 1),
+		NewItem("A", "1", 1),
+		NewItem("c", "1", 1),
+		NewItem("b", "2", 5),
+	}}
+	assert.Equal(t, []string{"a", "b", "c"}, l.Keys())
+	assert.Nil(t, (*ItemList)(nil).Keys())
+	assert.Equal(t, []string{}, (&ItemList{}).Keys())
+}
+
+func TestItemListTrimValues(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.yaml")
+	content := `- key: token
+  value: |
+    abc
+- key: version
+  value: " 1.10 "
+- key: indented
+  value: "  kept  "
+`
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0600))
+	s := NewStore()
+	s.File(filename)
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	v, err := l.GetItemValue("token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc\n", v, "values are not trimmed by default")
+
+	trimmed := l.TrimValues()
+	for key, expected := range map[string]string{"token": "abc", "version": "1.10", "indented": "kept"} {
+		v, err := trimmed.GetItemValue(key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, v, key)
+	}
+	v, err = l.GetItemValue("token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc\n", v, "the original list must not be modified")
+
+	v, err = Filter().Store(s).TrimValues().GetItemValue("token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", v)
+
+	assert.Equal(t, 0, (*ItemList)(nil).TrimValues().Len())
+}
+
+func TestItemListNewFilter(t *testing.T) {
+	l := &ItemList{Items: []Item{
+		NewItem("db-main", `{"name": "main", "port": 5432}`, 1),
+		NewItem("db-main", `{"name": "main", "port": 5433}`, 2),
+		NewItem("db-replica", `{"name": "replica", "port": 5434}`, 1),
+		NewItem("db-replica", `{"name": "replica", "port": 5434}`, 1),
+		NewItem("cache", `{"name": "cache", "port": 11211}`, 1),
+	}}
+
+	dbs := l.NewFilter().Keys("DB_").Unique()
+	squashed := dbs.Squash().Apply()
+	assert.Equal(t, 2, squashed.Len())
+	it, err := squashed.GetItem("db-main")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), it.Priority())
+
+	// filters are immutable: forking a pipeline does not affect it
+	reordered := dbs.Reorder(func(i *Item) int64 { return 10 - i.Priority() }).Squash().Apply()
+	it, err = reordered.GetItem("db-main")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), it.Priority())
+	assert.Equal(t, 3, dbs.Apply().Len())
+	assert.Equal(t, 5, l.Len(), "the item list must not be modified")
+	assert.Nil(t, l.indexed)
+
+	type db struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+	var out []db
+	require.NoError(t, dbs.Squash().Rekey(func(i *Item) string { return "x" }).Unmarshal(&out))
+	assert.ElementsMatch(t, []db{{"main", 5433}, {"replica", 5434}}, out)
+	assert.Error(t, dbs.Unmarshal(out), "a pointer to a slice is required")
+
+	var ports []int
+	assert.Error(t, dbs.Unmarshal(&ports))
+
+	assert.Equal(t, 0, (*ItemList)(nil).NewFilter().Squash().Apply().Len())
+}
+
+func TestItemFilterFork(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Add(NewItem("a", "1", 1), NewItem("a", "2", 2), NewItem("b", "1", 1))
+
+	base := Filter().Store(s).Prefix("a").Unique().Unique()
+	squashed := base.Squash()
+	reordered := base.Reorder(func(i *Item) int64 { return -i.Priority() })
+
+	l, err := squashed.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 1, l.Len())
+	l, err = reordered.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 2, l.Len(), "steps added to a fork must not leak into another one")
+}
@@ -0,0 +1,69 @@
+package configstore
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// LogErrorFunc is used by the stores to log errors, when no structured logger is set.
+// It can be overriden, or set to nil to disable error logging.
+//
+// Deprecated: use Store.SetLogger to log through a structured logger.
+var LogErrorFunc = log.Printf
+
+// LogInfoFunc is used by the stores to log informational messages, when no structured logger is set.
+// It can be overriden, or set to nil to disable informational logging.
+//
+// Deprecated: use Store.SetLogger to log through a structured logger.
+var LogInfoFunc = log.Printf
+
+// The subset of *slog.Logger used by the store, see SetLogger.
+type structuredLogger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+func (s *Store) getLogger() structuredLogger {
+	s.logMut.RLock()
+	defer s.logMut.RUnlock()
+	return s.logger
+}
+
+// Logs an informational message, along with key/value attributes.
+func (s *Store) logInfo(msg string, args ...interface{}) {
+	if atomic.LoadInt32(&s.quietInfo) != 0 {
+		return
+	}
+	if l := s.getLogger(); l != nil {
+		l.Info(msg, args...)
+		return
+	}
+	if LogInfoFunc != nil {
+		LogInfoFunc("%s%s", msg, formatLogAttrs(args))
+	}
+}
+
+// Logs an error, along with key/value attributes.
+func (s *Store) logError(err error, args ...interface{}) {
+	if l := s.getLogger(); l != nil {
+		l.Error("configstore: error", append(args, "error", err)...)
+		return
+	}
+	if LogErrorFunc != nil {
+		LogErrorFunc("error: %v", err)
+	}
+}
+
+// Formats key/value attributes for the Printf-style log functions.
+func formatLogAttrs(args []interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+	attrs := make([]string, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs = append(attrs, fmt.Sprintf("%v=%v", args[i], args[i+1]))
+	}
+	return ": " + strings.Join(attrs, " ")
+}
@@ -0,0 +1,45 @@
+package configstore
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// BindLogLevel makes the verbosity of the store logs follow the value of the given key, e.g. "configstore_log_level",
+// so that editing it in a refreshed file turns the informational logs (reload diagnostics, ...) on or off without a restart.
+// "debug" and "info" enable the informational logs, "warn", "warning", "error", "off" and "none" disable them;
+// errors are always logged. When the key is not defined, the informational logs are enabled, which is also the behavior
+// of an unbound store. Unknown levels are reported and ignored. The binding lasts until the store is closed.
+func (s *Store) BindLogLevel(key string) {
+	ch, cancel := s.WatchKey(key)
+	if l, err := s.GetItemList(); err == nil {
+		s.applyLogLevel(watchedValue(l, transformKey(key)))
+	}
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case v := <-ch:
+				s.applyLogLevel(v, v != "")
+			}
+		}
+	}()
+}
+
+func (s *Store) applyLogLevel(level string, present bool) {
+	if !present {
+		atomic.StoreInt32(&s.quietInfo, 0)
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug", "info":
+		atomic.StoreInt32(&s.quietInfo, 0)
+	case "warn", "warning", "error", "off", "none":
+		atomic.StoreInt32(&s.quietInfo, 1)
+	default:
+		s.logError(fmt.Errorf("configstore: unknown log level '%s'", level))
+	}
+}
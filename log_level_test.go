@@ -0,0 +1,54 @@
+package configstore
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreBindLogLevel(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+	s.InMemory("inmem").Set("configstore-log-level", "error", 1)
+
+	s.BindLogLevel("configstore_log_level")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&s.quietInfo), "the current value must be applied")
+
+	setLevel := func(level string) {
+		require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+			p.Set("configstore-log-level", level, 1)
+			return nil
+		}))
+	}
+	quiet := func(expected int32) func() bool {
+		return func() bool { return atomic.LoadInt32(&s.quietInfo) == expected }
+	}
+
+	setLevel("debug")
+	require.Eventually(t, quiet(0), 2*time.Second, 10*time.Millisecond)
+	setLevel("off")
+	require.Eventually(t, quiet(1), 2*time.Second, 10*time.Millisecond)
+	setLevel("bogus")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&s.quietInfo), "unknown levels are ignored")
+
+	// without the key, the default verbosity is restored
+	s.UnregisterProvider("inmem")
+	require.Eventually(t, quiet(0), 2*time.Second, 10*time.Millisecond)
+}
+
+func TestStoreLogInfoQuiet(t *testing.T) {
+	var logged []string
+	defer func(f func(string, ...interface{})) { LogInfoFunc = f }(LogInfoFunc)
+	LogInfoFunc = func(format string, args ...interface{}) { logged = append(logged, format) }
+
+	s := NewStore()
+	s.applyLogLevel("warn", true)
+	s.logInfo("hidden")
+	s.applyLogLevel("info", true)
+	s.logInfo("shown")
+	assert.Equal(t, 1, len(logged))
+}
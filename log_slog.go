@@ -0,0 +1,20 @@
+//go:build go1.21
+
+package configstore
+
+import (
+	"log/slog"
+)
+
+// SetLogger makes the store log through a structured logger, instead of the LogErrorFunc and LogInfoFunc functions.
+// Log records carry attributes such as provider, filename, key_count and error.
+// Passing nil reverts to the LogErrorFunc and LogInfoFunc functions.
+func (s *Store) SetLogger(logger *slog.Logger) {
+	s.logMut.Lock()
+	defer s.logMut.Unlock()
+	if logger == nil {
+		s.logger = nil
+		return
+	}
+	s.logger = logger
+}
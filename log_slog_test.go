@@ -0,0 +1,45 @@
+//go:build go1.21
+
+package configstore
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSetLogger(t *testing.T) {
+	defer func(info, err func(string, ...interface{})) { LogInfoFunc, LogErrorFunc = info, err }(LogInfoFunc, LogErrorFunc)
+	LogInfoFunc, LogErrorFunc = nil, nil
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: foo\n  value: bar\n- key: baz\n  value: buz\n"), 0o600))
+
+	buf := &bytes.Buffer{}
+	s := NewStore()
+	s.SetLogger(slog.New(slog.NewTextHandler(buf, nil)))
+
+	s.File(filename)
+	out := buf.String()
+	assert.Contains(t, out, "level=INFO")
+	assert.Contains(t, out, "provider=file:"+filename)
+	assert.Contains(t, out, "filename="+filename)
+	assert.Contains(t, out, "key_count=2")
+
+	buf.Reset()
+	s.File(filepath.Join(t.TempDir(), "missing.yaml"))
+	out = buf.String()
+	assert.Contains(t, out, "level=ERROR")
+	assert.Contains(t, out, "provider=file:")
+	assert.Contains(t, out, "error=")
+
+	buf.Reset()
+	s.SetLogger(nil)
+	s.File(filename + ".missing")
+	assert.Empty(t, buf.String())
+}
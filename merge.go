@@ -0,0 +1,79 @@
+package configstore
+
+import (
+	"sort"
+)
+
+// MergeStrategy decides which item to keep when several providers define the same key, see SetMergeStrategy.
+type MergeStrategy interface {
+	// Merge returns the item to keep between a, from a provider registered first, and b, from one registered later.
+	Merge(a, b Item) Item
+}
+
+// MergeFunc adapts a function to the MergeStrategy interface.
+type MergeFunc func(a, b Item) Item
+
+// Merge calls f(a, b).
+func (f MergeFunc) Merge(a, b Item) Item {
+	return f(a, b)
+}
+
+var (
+	// PriorityWins keeps the item with the highest priority, or the one of the provider registered first on a tie.
+	PriorityWins MergeStrategy = MergeFunc(func(a, b Item) Item {
+		if b.priority > a.priority {
+			return b
+		}
+		return a
+	})
+	// ProviderOrderWins keeps the item of the provider registered last, regardless of the priorities.
+	ProviderOrderWins MergeStrategy = MergeFunc(func(a, b Item) Item {
+		return b
+	})
+	// ProviderOrderWinsFirst keeps the item of the provider registered first, regardless of the priorities.
+	ProviderOrderWinsFirst MergeStrategy = MergeFunc(func(a, b Item) Item {
+		return a
+	})
+)
+
+// SetMergeStrategy installs the strategy used to merge the items of a key defined by several providers into a single one,
+// which is then served by all the getters (GetItemList, GetFirst, Filter, ...). The items are merged two by two,
+// in provider registration order. The items of a key defined by a single provider are all kept.
+// Without a strategy, which is the default, the items of all the providers are kept, sorted by priority.
+func (s *Store) SetMergeStrategy(ms MergeStrategy) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.mergeStrategy = ms
+	s.NotifyWatchers()
+}
+
+// Replaces the items of each key defined by several providers with the result of the strategy.
+// Must be called with s.pMut held.
+func (s *Store) mergeItems(l *ItemList) {
+	groups := map[string][]Item{}
+	for _, it := range l.Items {
+		groups[it.key] = append(groups[it.key], it)
+	}
+	items := make([]Item, 0, len(l.Items))
+	for _, it := range l.Items {
+		candidates := groups[it.key]
+		switch {
+		case candidates == nil:
+			// already merged
+		case !multipleSources(candidates):
+			items = append(items, it)
+		default:
+			sort.SliceStable(candidates, func(i, j int) bool {
+				return s.registrations[candidates[i].source] < s.registrations[candidates[j].source]
+			})
+			merged := candidates[0]
+			for _, c := range candidates[1:] {
+				merged = s.mergeStrategy.Merge(merged, c)
+			}
+			merged.key = it.key
+			items = append(items, merged)
+			groups[it.key] = nil
+		}
+	}
+	l.Items = items
+}
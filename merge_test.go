@@ -0,0 +1,49 @@
+package configstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeStrategy(t *testing.T) {
+	s := NewStore()
+	first := s.InMemory("first")
+	first.Add(NewItem("port", "5432", 10), NewItem("host", "localhost", 0), NewItem("host", "127.0.0.1", 1))
+	second := s.InMemory("second")
+	second.Add(NewItem("port", "5433", 5))
+
+	// without a strategy, all the items are kept
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(l.indexed["port"]))
+
+	check := func(ms MergeStrategy, expected string) {
+		t.Helper()
+		s.SetMergeStrategy(ms)
+		it, err := s.GetFirst("port")
+		require.NoError(t, err)
+		assert.Equal(t, expected, mustValue(it))
+
+		l, err := s.Filter().Slice("port").GetItemList()
+		require.NoError(t, err)
+		require.Equal(t, 1, l.Len())
+		assert.Equal(t, expected, mustValue(l.Items[0]))
+
+		// keys defined by a single provider are left untouched
+		l, err = s.GetItemList()
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(l.indexed["host"]))
+	}
+	check(PriorityWins, "5432")
+	check(ProviderOrderWins, "5433")
+	check(ProviderOrderWinsFirst, "5432")
+	check(MergeFunc(func(a, b Item) Item { return NewItem("ignored", mustValue(a)+","+mustValue(b), 0) }), "5432,5433")
+
+	// re-registering a provider moves it last
+	s.UnregisterProvider("first")
+	s.RegisterProvider("first", first.Items)
+	check(ProviderOrderWins, "5432")
+	check(ProviderOrderWinsFirst, "5433")
+}
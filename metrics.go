@@ -0,0 +1,120 @@
+package configstore
+
+import (
+	"time"
+)
+
+// A MetricsObserver is notified of the provider loads and errors, and of the watchers notifications,
+// so that they can be exposed as metrics. See SetMetricsObserver, and the prommetrics package for a Prometheus implementation.
+// The methods are called synchronously, they should not block.
+type MetricsObserver interface {
+	// ProviderReloaded is called every time a provider loads its items, with the number of items loaded.
+	ProviderReloaded(provider string, itemCount int)
+	// ProviderError is called every time a provider fails to load its items.
+	ProviderError(provider string, err error)
+	// WatchersNotified is called every time the watchers are notified of a configuration change.
+	WatchersNotified()
+}
+
+// A TimeoutObserver is a MetricsObserver also notified of the provider timeouts, see SetProviderTimeout.
+type TimeoutObserver interface {
+	MetricsObserver
+	// ProviderTimeout is called every time a provider call exceeds its timeout.
+	ProviderTimeout(provider string, elapsed time.Duration)
+}
+
+// A MetricsHandler records the duration and the result of every provider call, and the number of watchers reached
+// by every notification, see SetMetricsHandler. Unlike MetricsObserver, whose reloads are reported by the providers
+// when their items change, it sees the calls made by the store each time the configuration is read.
+// The methods are called synchronously, they should not block.
+type MetricsHandler interface {
+	// RecordProviderLoad is called after every call of a provider by the store, with the error it returned, if any.
+	RecordProviderLoad(name string, duration time.Duration, err error)
+	// RecordWatcherNotification is called on every notification (see NotifyWatchers), with the number of watchers notified.
+	RecordWatcherNotification(count int)
+}
+
+// SetMetricsHandler sets the handler recording the provider calls and the watchers notifications. Passing nil disables it.
+func (s *Store) SetMetricsHandler(h MetricsHandler) {
+	s.metricsMut.Lock()
+	defer s.metricsMut.Unlock()
+	s.metricsHandler = h
+}
+
+func (s *Store) getMetricsHandler() MetricsHandler {
+	s.metricsMut.RLock()
+	defer s.metricsMut.RUnlock()
+	return s.metricsHandler
+}
+
+// A ProviderInterceptor is called in place of every provider call made by the store, with the name of the provider
+// and the call itself, which it is responsible for running, e.g. to trace the calls (see the oteltrace package).
+type ProviderInterceptor func(name string, call Provider) (ItemList, error)
+
+// SetProviderInterceptor sets the interceptor of the provider calls. Passing nil disables it.
+// The interceptor runs within the provider timeout (see SetProviderTimeout), and the metrics handler measures it.
+func (s *Store) SetProviderInterceptor(i ProviderInterceptor) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.interceptor = i
+}
+
+// Calls the provider through the interceptor, recording the call with the metrics handler, see SetMetricsHandler.
+// Must be called with s.pMut held.
+func (s *Store) callProvider(name string, p Provider) (ItemList, error) {
+	if i := s.interceptor; i != nil {
+		next := p
+		p = func() (ItemList, error) {
+			return i(name, next)
+		}
+	}
+	h := s.getMetricsHandler()
+	if h == nil {
+		return s.callProviderWithTimeout(name, p)
+	}
+	start := time.Now()
+	l, err := s.callProviderWithTimeout(name, p)
+	h.RecordProviderLoad(name, time.Since(start), err)
+	return l, err
+}
+
+// SetMetricsObserver sets the observer notified of the provider loads and errors. Passing nil disables it.
+// It should be set before registering the providers, to observe their initial load.
+func (s *Store) SetMetricsObserver(o MetricsObserver) {
+	s.metricsMut.Lock()
+	defer s.metricsMut.Unlock()
+	s.metrics = o
+}
+
+func (s *Store) getMetricsObserver() MetricsObserver {
+	s.metricsMut.RLock()
+	defer s.metricsMut.RUnlock()
+	return s.metrics
+}
+
+func (s *Store) observeReload(provider string, itemCount int) {
+	if o := s.getMetricsObserver(); o != nil {
+		o.ProviderReloaded(provider, itemCount)
+	}
+}
+
+func (s *Store) observeError(provider string, err error) {
+	if o := s.getMetricsObserver(); o != nil {
+		o.ProviderError(provider, err)
+	}
+}
+
+func (s *Store) observeNotification(count int) {
+	if o := s.getMetricsObserver(); o != nil {
+		o.WatchersNotified()
+	}
+	if h := s.getMetricsHandler(); h != nil {
+		h.RecordWatcherNotification(count)
+	}
+}
+
+func (s *Store) observeTimeout(provider string, elapsed time.Duration) {
+	if o, ok := s.getMetricsObserver().(TimeoutObserver); ok {
+		o.ProviderTimeout(provider, elapsed)
+	}
+}
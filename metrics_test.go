@@ -0,0 +1,151 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testObserver struct {
+	mut           sync.Mutex
+	reloads       map[string][]int
+	errors        map[string]int
+	notifications int
+}
+
+func (o *testObserver) ProviderReloaded(provider string, itemCount int) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.reloads[provider] = append(o.reloads[provider], itemCount)
+}
+
+func (o *testObserver) ProviderError(provider string, err error) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.errors[provider]++
+}
+
+func (o *testObserver) WatchersNotified() {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.notifications++
+}
+
+func (o *testObserver) reloadCounts(provider string) []int {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return append([]int(nil), o.reloads[provider]...)
+}
+
+func TestMetricsObserver(t *testing.T) {
+	o := &testObserver{reloads: map[string][]int{}, errors: map[string]int{}}
+	s := NewStore()
+	defer s.Close()
+	s.SetMetricsObserver(o)
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: a\n  value: \"1\"\n"), 0600))
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+
+	s.FileRefresh(filename)
+	s.File(missing)
+
+	refreshName := buildProviderName("file", true, filename)
+	assert.Equal(t, []int{1}, o.reloadCounts(refreshName))
+	assert.Equal(t, 1, o.errors[buildProviderName("file", false, missing)])
+
+	ch := s.Watch()
+	rewriteFile(t, filename, "- key: a\n  value: \"1\"\n- key: b\n  value: \"2\"\n")
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification after file refresh")
+	}
+	counts := o.reloadCounts(refreshName)
+	assert.Equal(t, 2, counts[len(counts)-1])
+
+	o.mut.Lock()
+	assert.NotZero(t, o.notifications)
+	o.mut.Unlock()
+}
+
+type providerLoad struct {
+	name string
+	err  error
+}
+
+type testMetricsHandler struct {
+	mut           sync.Mutex
+	loads         []providerLoad
+	durations     []time.Duration
+	notifications []int
+}
+
+func (h *testMetricsHandler) RecordProviderLoad(name string, duration time.Duration, err error) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.loads = append(h.loads, providerLoad{name, err})
+	h.durations = append(h.durations, duration)
+}
+
+func (h *testMetricsHandler) RecordWatcherNotification(count int) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.notifications = append(h.notifications, count)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	h := &testMetricsHandler{}
+	s := NewStore()
+	s.SetMetricsHandler(h)
+	s.Watch()
+	s.Watch()
+
+	failure := errors.New("unavailable")
+	s.RegisterProvider("slow", func() (ItemList, error) {
+		time.Sleep(10 * time.Millisecond)
+		return ItemList{Items: []Item{NewItem("a", "1", 1)}}, nil
+	})
+	s.RegisterProvider("failing", newErrorProvider(failure))
+	assert.Equal(t, []int{2, 2}, h.notifications)
+
+	_, err := s.GetItemList()
+	require.Error(t, err)
+	assert.Equal(t, []providerLoad{{"slow", nil}, {"failing", failure}}, h.loads)
+	assert.True(t, h.durations[0] >= 10*time.Millisecond)
+
+	s.SetMetricsHandler(nil)
+	_, _ = s.GetItemList()
+	assert.Len(t, h.loads, 2)
+}
+
+func TestProviderInterceptor(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("a", "1", 1)
+
+	var calls []string
+	s.SetProviderInterceptor(func(name string, call Provider) (ItemList, error) {
+		calls = append(calls, name)
+		l, err := call()
+		ret := ItemList{}
+		for _, it := range l.Items {
+			ret.Items = append(ret.Items, NewItem(it.Key(), mustValue(it)+"-intercepted", it.Priority()))
+		}
+		return ret, err
+	})
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1-intercepted", v)
+	assert.Equal(t, []string{"inmem"}, calls)
+
+	s.SetProviderInterceptor(nil)
+	v, err = s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+}
@@ -0,0 +1,183 @@
+// Package natsprovider reads configstore items from a NATS JetStream Key-Value bucket.
+//
+//	p, err := natsprovider.NATSKVProvider(configstore.DefaultStore, nc, "myapp")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer p.Close()
+//
+// Each entry of the bucket becomes an item, named after the entry key, with the entry value.
+// The bucket is watched: updates are applied as they happen, and deleted entries are removed.
+package natsprovider
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/ovh/configstore"
+)
+
+// NATSOption modifies the behavior of the provider.
+type NATSOption func(*Provider)
+
+// WithKeyValueConfig makes the provider create the bucket with the given configuration if it does not exist.
+// The name of the bucket is the one given to NATSKVProvider.
+func WithKeyValueConfig(cfg nats.KeyValueConfig) NATSOption {
+	return func(p *Provider) {
+		p.config = &cfg
+	}
+}
+
+// WithItemMapper sets the function converting an entry of the bucket to an item.
+// By default, the item is named after the entry key, with the entry value and a priority of 0.
+func WithItemMapper(mapper func(nats.KeyValueEntry) configstore.Item) NATSOption {
+	return func(p *Provider) {
+		p.mapper = mapper
+	}
+}
+
+// Provider is a configstore provider serving the entries of a NATS JetStream Key-Value bucket.
+type Provider struct {
+	store  *configstore.Store
+	bucket string
+	config *nats.KeyValueConfig
+	mapper func(nats.KeyValueEntry) configstore.Item
+
+	kv      nats.KeyValue
+	watcher nats.KeyWatcher
+	done    chan struct{}
+	once    sync.Once
+
+	items     map[string]configstore.Item
+	revisions map[string]uint64
+	mut       sync.Mutex
+}
+
+// NATSKVProvider registers on the store a provider serving the entries of the bucket, loaded initially
+// and then kept up to date by watching the bucket. An error is returned if the bucket cannot be read,
+// in which case nothing is registered.
+func NATSKVProvider(s *configstore.Store, nc *nats.Conn, bucket string, opts ...NATSOption) (*Provider, error) {
+	p := &Provider{
+		store:     s,
+		bucket:    bucket,
+		mapper:    defaultMapper,
+		done:      make(chan struct{}),
+		items:     map[string]configstore.Item{},
+		revisions: map[string]uint64{},
+	}
+	for _, o := range opts {
+		o(p)
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, p.error(nc, err)
+	}
+	p.kv, err = js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) && p.config != nil {
+		cfg := *p.config
+		cfg.Bucket = bucket
+		p.kv, err = js.CreateKeyValue(&cfg)
+	}
+	if err != nil {
+		return nil, p.error(nc, err)
+	}
+
+	keys, err := p.kv.Keys()
+	if err != nil && !errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, p.error(nc, err)
+	}
+	for _, k := range keys {
+		entry, err := p.kv.Get(k)
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			// deleted in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, p.error(nc, err)
+		}
+		p.apply(entry)
+	}
+
+	// the watcher first replays the current entries, which are skipped as they were already loaded
+	p.watcher, err = p.kv.WatchAll()
+	if err != nil {
+		return nil, p.error(nc, err)
+	}
+	s.RegisterProvider(p.Name(), p.Items)
+	go p.watch()
+	return p, nil
+}
+
+// Name returns the name under which the provider is registered.
+func (p *Provider) Name() string {
+	return fmt.Sprintf("nats-kv:%s", p.bucket)
+}
+
+// Items returns the items of the bucket entries.
+func (p *Provider) Items() (configstore.ItemList, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	ret := configstore.ItemList{Items: make([]configstore.Item, 0, len(p.items))}
+	for _, it := range p.items {
+		ret.Items = append(ret.Items, it)
+	}
+	return ret, nil
+}
+
+// Close stops watching the bucket. The provider keeps serving the last items.
+func (p *Provider) Close() error {
+	var err error
+	p.once.Do(func() {
+		close(p.done)
+		err = p.watcher.Stop()
+	})
+	return err
+}
+
+func (p *Provider) watch() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case entry, ok := <-p.watcher.Updates():
+			if !ok {
+				return
+			}
+			// a nil entry marks the end of the initial values
+			if entry != nil && p.apply(entry) {
+				p.store.NotifyWatchers()
+			}
+		}
+	}
+}
+
+// Applies an entry, unless a more recent revision of its key was already applied. Reports whether the items changed.
+func (p *Provider) apply(entry nats.KeyValueEntry) bool {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	key := entry.Key()
+	if entry.Revision() <= p.revisions[key] {
+		return false
+	}
+	p.revisions[key] = entry.Revision()
+	switch entry.Operation() {
+	case nats.KeyValueDelete, nats.KeyValuePurge:
+		_, existed := p.items[key]
+		delete(p.items, key)
+		return existed
+	default:
+		p.items[key] = p.mapper(entry)
+		return true
+	}
+}
+
+func (p *Provider) error(nc *nats.Conn, err error) error {
+	return &configstore.ProviderNetworkError{Name: p.Name(), URL: nc.ConnectedUrlRedacted(), Cause: err}
+}
+
+func defaultMapper(entry nats.KeyValueEntry) configstore.Item {
+	return configstore.NewItem(entry.Key(), string(entry.Value()), 0)
+}
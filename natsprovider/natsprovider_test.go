@@ -0,0 +1,96 @@
+package natsprovider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startServer(t *testing.T) *nats.Conn {
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+	require.NoError(t, err)
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second), "nats server not ready")
+
+	nc, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+	return nc
+}
+
+func TestNATSKVProvider(t *testing.T) {
+	nc := startServer(t)
+	js, err := nc.JetStream()
+	require.NoError(t, err)
+	kv, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: "config"})
+	require.NoError(t, err)
+	_, err = kv.PutString("db.host", "localhost")
+	require.NoError(t, err)
+	_, err = kv.PutString("db.port", "5432")
+	require.NoError(t, err)
+
+	s := configstore.NewStore()
+	defer s.Close()
+	p, err := NATSKVProvider(s, nc, "config")
+	require.NoError(t, err)
+	defer p.Close()
+
+	v, err := s.GetItemValue("db.host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+
+	_, err = kv.PutString("db.host", "remote")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("db.host")
+		return v == "remote"
+	}, 5*time.Second, 10*time.Millisecond, "update not applied")
+
+	require.NoError(t, kv.Delete("db.port"))
+	require.Eventually(t, func() bool {
+		_, err := s.GetItemValue("db.port")
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond, "delete not applied")
+
+	require.NoError(t, p.Close())
+	_, err = kv.PutString("db.host", "closed")
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	v, err = s.GetItemValue("db.host")
+	require.NoError(t, err)
+	assert.Equal(t, "remote", v, "updates after Close must be ignored")
+}
+
+func TestNATSKVProviderOptions(t *testing.T) {
+	nc := startServer(t)
+
+	s := configstore.NewStore()
+	defer s.Close()
+	_, err := NATSKVProvider(s, nc, "missing")
+	assert.Error(t, err)
+
+	p, err := NATSKVProvider(s, nc, "created",
+		WithKeyValueConfig(nats.KeyValueConfig{History: 5}),
+		WithItemMapper(func(e nats.KeyValueEntry) configstore.Item {
+			return configstore.NewItem("nats."+e.Key(), string(e.Value()), 10)
+		}))
+	require.NoError(t, err)
+	defer p.Close()
+
+	js, err := nc.JetStream()
+	require.NoError(t, err)
+	kv, err := js.KeyValue("created")
+	require.NoError(t, err)
+	_, err = kv.PutString("foo", "bar")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		it, err := s.GetItem("nats.foo")
+		return err == nil && it.Priority() == 10
+	}, 5*time.Second, 10*time.Millisecond, "mapper not used")
+}
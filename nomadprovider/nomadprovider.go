@@ -0,0 +1,268 @@
+// Package nomadprovider reads configstore items from Nomad Variables, through the Nomad HTTP API.
+//
+//	p, err := nomadprovider.NomadVariableProvider(configstore.DefaultStore, "https://nomad.example.com:4646",
+//		"default", "nomad/jobs/myapp", nomadprovider.WithToken(os.Getenv("NOMAD_TOKEN")))
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer p.Close()
+//
+// Each key/value pair of the variables stored under the path becomes an item. The pairs of the variable
+// at the path itself are named after their key; those of the nested variables are prefixed with
+// their path relative to it (e.g. "db/host" for the "host" key of the nomad/jobs/myapp/db variable).
+// The variables are polled, and only the ones whose ModifyIndex changed are read again.
+package nomadprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ovh/configstore"
+)
+
+// DefaultPollInterval is the default delay between two polls of the variables.
+const DefaultPollInterval = 30 * time.Second
+
+// NomadOption modifies the behavior of the provider.
+type NomadOption func(*Provider)
+
+// WithToken sets the ACL token sent with the requests.
+func WithToken(token string) NomadOption {
+	return func(p *Provider) {
+		p.token = token
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for the requests, e.g. to configure TLS.
+func WithHTTPClient(c *http.Client) NomadOption {
+	return func(p *Provider) {
+		p.client = c
+	}
+}
+
+// WithPollInterval sets the delay between two polls of the variables.
+func WithPollInterval(d time.Duration) NomadOption {
+	return func(p *Provider) {
+		p.interval = d
+	}
+}
+
+// WithPriority sets the priority of the items, 0 by default.
+func WithPriority(priority int64) NomadOption {
+	return func(p *Provider) {
+		p.priority = priority
+	}
+}
+
+// Provider is a configstore provider serving the Nomad Variables stored under a path.
+// When Nomad cannot be reached, the last known items keep being served.
+type Provider struct {
+	store     *configstore.Store
+	addr      string
+	namespace string
+	path      string
+	token     string
+	client    *http.Client
+	interval  time.Duration
+	priority  int64
+
+	// the variables read, by path
+	vars map[string]variable
+	mut  sync.Mutex
+	// serializes the polls of the loop and of UpdateCredentials
+	pollMut sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type variable struct {
+	Path        string
+	Items       map[string]string
+	ModifyIndex uint64
+}
+
+// NomadVariableProvider registers on the store a provider serving the variables stored under path in the namespace,
+// read from the Nomad agent at addr. An error is returned if they cannot be read initially, in which case nothing
+// is registered.
+func NomadVariableProvider(s *configstore.Store, addr, namespace, path string, opts ...NomadOption) (*Provider, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{
+		store:     s,
+		addr:      strings.TrimSuffix(addr, "/"),
+		namespace: namespace,
+		path:      strings.Trim(path, "/"),
+		client:    http.DefaultClient,
+		interval:  DefaultPollInterval,
+		vars:      map[string]variable{},
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+
+	if _, err := p.poll(); err != nil {
+		cancel()
+		return nil, err
+	}
+	s.RegisterProvider(p.Name(), p.Items)
+	go p.pollLoop()
+	return p, nil
+}
+
+// Name returns the name under which the provider is registered.
+func (p *Provider) Name() string {
+	return fmt.Sprintf("nomad:%s/%s", p.namespace, p.path)
+}
+
+// Items returns the items of the variables.
+func (p *Provider) Items() (configstore.ItemList, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	ret := configstore.ItemList{}
+	for _, v := range p.vars {
+		prefix := strings.Trim(strings.TrimPrefix(v.Path, p.path), "/")
+		if prefix != "" {
+			prefix += "/"
+		}
+		for k, val := range v.Items {
+			ret.Items = append(ret.Items, configstore.NewItem(prefix+k, val, p.priority))
+		}
+	}
+	return ret, nil
+}
+
+// Close stops polling the variables.
+func (p *Provider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// UpdateCredentials replaces the ACL token sent with the requests, e.g. to rotate an expiring token in place.
+// The variables are polled again with the new token, and watchers get notified if they changed.
+// On failure, the last known items keep being served, and the new token is still used by the following polls.
+func (p *Provider) UpdateCredentials(token string) error {
+	p.mut.Lock()
+	p.token = token
+	p.mut.Unlock()
+
+	changed, err := p.poll()
+	if err != nil {
+		return err
+	}
+	if changed {
+		p.store.NotifyWatchers()
+	}
+	return nil
+}
+
+func (p *Provider) pollLoop() {
+	t := time.NewTicker(p.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-t.C:
+		}
+		changed, err := p.poll()
+		if err != nil {
+			logError(err)
+			continue
+		}
+		if changed {
+			p.store.NotifyWatchers()
+		}
+	}
+}
+
+// Lists the variables under the path, and reads the ones which are new or were modified. Reports whether they changed.
+func (p *Provider) poll() (bool, error) {
+	p.pollMut.Lock()
+	defer p.pollMut.Unlock()
+
+	var list []variable
+	if err := p.get("/v1/vars", url.Values{"prefix": {p.path}}, &list); err != nil {
+		return false, err
+	}
+
+	p.mut.Lock()
+	known := make(map[string]uint64, len(p.vars))
+	for path, v := range p.vars {
+		known[path] = v.ModifyIndex
+	}
+	p.mut.Unlock()
+
+	vars := make(map[string]variable, len(list))
+	changed := len(list) != len(known)
+	for _, meta := range list {
+		if index, ok := known[meta.Path]; ok && index == meta.ModifyIndex {
+			continue
+		}
+		var v variable
+		if err := p.get("/v1/var/"+meta.Path, nil, &v); err != nil {
+			return false, err
+		}
+		vars[meta.Path] = v
+		changed = true
+	}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	for _, meta := range list {
+		if _, ok := vars[meta.Path]; !ok {
+			vars[meta.Path] = p.vars[meta.Path]
+		}
+	}
+	p.vars = vars
+	return changed, nil
+}
+
+func (p *Provider) get(path string, query url.Values, out interface{}) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	if p.namespace != "" {
+		query.Set("namespace", p.namespace)
+	}
+	u := p.addr + path + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return p.error(u, err)
+	}
+	p.mut.Lock()
+	token := p.token
+	p.mut.Unlock()
+	if token != "" {
+		req.Header.Set("X-Nomad-Token", token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return p.error(u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return p.error(u, fmt.Errorf("unexpected status: %s", resp.Status))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &configstore.ProviderParseError{Name: p.Name(), Filename: u, Cause: err}
+	}
+	return nil
+}
+
+func (p *Provider) error(u string, err error) error {
+	return &configstore.ProviderNetworkError{Name: p.Name(), URL: u, Cause: err}
+}
+
+func logError(err error) {
+	if configstore.LogErrorFunc != nil {
+		configstore.LogErrorFunc("error: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package nomadprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A fake Nomad agent serving the Variables API.
+type fakeNomad struct {
+	vars  map[string]variable
+	reads map[string]int
+	// the accepted token, "secret" by default
+	token string
+	mut   sync.Mutex
+}
+
+func (f *fakeNomad) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	token := f.token
+	if token == "" {
+		token = "secret"
+	}
+	if r.Header.Get("X-Nomad-Token") != token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.URL.Query().Get("namespace") != "apps" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case r.URL.Path == "/v1/vars":
+		list := []variable{}
+		for path, v := range f.vars {
+			if strings.HasPrefix(path, r.URL.Query().Get("prefix")) {
+				list = append(list, variable{Path: path, ModifyIndex: v.ModifyIndex})
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	case strings.HasPrefix(r.URL.Path, "/v1/var/"):
+		path := strings.TrimPrefix(r.URL.Path, "/v1/var/")
+		v, ok := f.vars[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.reads[path]++
+		json.NewEncoder(w).Encode(v)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeNomad) set(path string, index uint64, items map[string]string) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if items == nil {
+		delete(f.vars, path)
+		return
+	}
+	f.vars[path] = variable{Path: path, Items: items, ModifyIndex: index}
+}
+
+func TestNomadVariableProvider(t *testing.T) {
+	f := &fakeNomad{vars: map[string]variable{}, reads: map[string]int{}}
+	f.set("jobs/app", 1, map[string]string{"name": "app"})
+	f.set("jobs/app/db", 2, map[string]string{"host": "localhost", "port": "5432"})
+	f.set("jobs/other", 3, map[string]string{"name": "other"})
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s := configstore.NewStore()
+	defer s.Close()
+	p, err := NomadVariableProvider(s, srv.URL, "apps", "/jobs/app",
+		WithToken("secret"), WithPollInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Close()
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 3, l.Len())
+	v, err := l.GetItemValue("db/host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+	v, err = l.GetItemValue("name")
+	require.NoError(t, err)
+	assert.Equal(t, "app", v)
+
+	f.set("jobs/app/db", 4, map[string]string{"host": "remote"})
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("db/host")
+		return v == "remote"
+	}, 2*time.Second, 10*time.Millisecond, "modified variable not read")
+	_, err = s.GetItemValue("db/port")
+	assert.Error(t, err)
+
+	f.set("jobs/app/db", 0, nil)
+	require.Eventually(t, func() bool {
+		_, err := s.GetItemValue("db/host")
+		return err != nil
+	}, 2*time.Second, 10*time.Millisecond, "deleted variable still served")
+
+	f.mut.Lock()
+	assert.Equal(t, 1, f.reads["jobs/app"], "unmodified variables must not be read again")
+	f.mut.Unlock()
+}
+
+func TestNomadUpdateCredentials(t *testing.T) {
+	f := &fakeNomad{vars: map[string]variable{}, reads: map[string]int{}}
+	f.set("jobs/app", 1, map[string]string{"name": "app"})
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s := configstore.NewStore()
+	defer s.Close()
+	p, err := NomadVariableProvider(s, srv.URL, "apps", "jobs/app", WithToken("secret"), WithPollInterval(time.Hour))
+	require.NoError(t, err)
+	defer p.Close()
+
+	// the token expires
+	f.mut.Lock()
+	f.token = "rotated"
+	f.mut.Unlock()
+	f.set("jobs/app", 2, map[string]string{"name": "renamed"})
+	var nerr *configstore.ProviderNetworkError
+	assert.True(t, errors.As(p.UpdateCredentials("expired"), &nerr))
+	v, err := s.GetItemValue("name")
+	require.NoError(t, err)
+	assert.Equal(t, "app", v, "the last items should be kept")
+
+	ch := s.Watch()
+	require.NoError(t, p.UpdateCredentials("rotated"))
+	<-ch
+	v, err = s.GetItemValue("name")
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", v)
+}
+
+func TestNomadVariableProviderErrors(t *testing.T) {
+	f := &fakeNomad{vars: map[string]variable{}, reads: map[string]int{}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s := configstore.NewStore()
+	_, err := NomadVariableProvider(s, srv.URL, "apps", "jobs/app")
+	var nerr *configstore.ProviderNetworkError
+	assert.True(t, errors.As(err, &nerr), "missing token: %v", err)
+
+	p, err := NomadVariableProvider(s, srv.URL, "apps", "jobs/app", WithToken("secret"), WithHTTPClient(srv.Client()))
+	require.NoError(t, err)
+	defer p.Close()
+	assert.Equal(t, "nomad:apps/jobs/app", p.Name())
+}
@@ -0,0 +1,49 @@
+// Package oteltrace traces the configstore provider calls with OpenTelemetry.
+//
+//	oteltrace.SetTracerProvider(configstore.DefaultStore, otel.GetTracerProvider())
+package oteltrace
+
+import (
+	"context"
+
+	"github.com/ovh/configstore"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// SpanName is the name of the span of each provider call.
+	SpanName = "configstore.provider.load"
+	// TracerName is the name of the tracer, the instrumentation library.
+	TracerName = "github.com/ovh/configstore/oteltrace"
+)
+
+// SetTracerProvider wraps each provider call made by the store in a span named SpanName, with the attributes
+// provider.name and item.count. A failed call is recorded as an error on the span, whose status is set to Error.
+// Passing nil stops tracing. It replaces the provider interceptor of the store, see Store.SetProviderInterceptor.
+func SetTracerProvider(s *configstore.Store, tp trace.TracerProvider) {
+	if tp == nil {
+		s.SetProviderInterceptor(nil)
+		return
+	}
+	s.SetProviderInterceptor(Interceptor(tp))
+}
+
+// Interceptor returns the provider interceptor tracing the provider calls, see SetTracerProvider.
+func Interceptor(tp trace.TracerProvider) configstore.ProviderInterceptor {
+	tracer := tp.Tracer(TracerName)
+	return func(name string, call configstore.Provider) (configstore.ItemList, error) {
+		_, span := tracer.Start(context.Background(), SpanName, trace.WithAttributes(attribute.String("provider.name", name)))
+		defer span.End()
+
+		l, err := call()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return l, err
+		}
+		span.SetAttributes(attribute.Int("item.count", len(l.Items)))
+		return l, nil
+	}
+}
@@ -0,0 +1,62 @@
+package oteltrace
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetTracerProvider(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	s := configstore.NewStore()
+	SetTracerProvider(s, tp)
+	s.InMemory("inmem").Set("a", "1", 1).Set("b", "2", 1)
+	_, err := s.GetItemList()
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, SpanName, spans[0].Name())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("provider.name", "inmem"),
+		attribute.Int("item.count", 2),
+	}, spans[0].Attributes())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	s.RegisterProvider("failing", func() (configstore.ItemList, error) {
+		return configstore.ItemList{}, errors.New("boom")
+	})
+	_, err = s.GetItemList()
+	require.Error(t, err)
+	spans = recorder.Ended()
+	require.Len(t, spans, 3)
+	failed := spans[2]
+	assert.Equal(t, []attribute.KeyValue{attribute.String("provider.name", "failing")}, failed.Attributes())
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Equal(t, "boom", failed.Status().Description)
+	require.Len(t, failed.Events(), 1)
+	assert.Equal(t, "exception", failed.Events()[0].Name)
+
+	SetTracerProvider(s, nil)
+	_, _ = s.GetItemList()
+	assert.Len(t, recorder.Ended(), 3)
+}
+
+func TestNoopTracerProvider(t *testing.T) {
+	s := configstore.NewStore()
+	SetTracerProvider(s, trace.NewNoopTracerProvider())
+	s.InMemory("inmem").Set("a", "1", 1)
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+}
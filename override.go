@@ -0,0 +1,46 @@
+package configstore
+
+import (
+	"context"
+	"math"
+)
+
+// ContextOverrideSource is the source (see Item.Source) of the items overridden with WithConfigOverride.
+const ContextOverrideSource = "context"
+
+type contextOverrideKey struct{}
+
+// The overrides of a context, the most recent first.
+type contextOverride struct {
+	item   Item
+	parent *contextOverride
+}
+
+// WithConfigOverride returns a copy of ctx in which the value of key is overridden, e.g. to apply the rate limits of
+// a tenant for the duration of its request, see GetFromContext. The override is only visible to ctx and the contexts
+// derived from it. Overriding a key again in a derived context takes precedence over the previous override.
+func WithConfigOverride(ctx context.Context, key, value string) context.Context {
+	it := NewItem(key, value, math.MaxInt64)
+	it.source = ContextOverrideSource
+	parent, _ := ctx.Value(contextOverrideKey{}).(*contextOverride)
+	return context.WithValue(ctx, contextOverrideKey{}, &contextOverride{item: it, parent: parent})
+}
+
+// GetFromContext returns the item of key overridden in ctx, if any (see WithConfigOverride),
+// or the item retrieved from the store otherwise, as Get does. Both are recorded by the audit logger, the overrides
+// with the ContextOverrideSource source, see EnableAuditLog and SourceAuditLogger.
+func (s *Store) GetFromContext(ctx context.Context, key string) (Item, error) {
+	return s.getFromContext(ctx, key, 1)
+}
+
+func (s *Store) getFromContext(ctx context.Context, key string, skip int) (Item, error) {
+	k := transformKey(key)
+	o, _ := ctx.Value(contextOverrideKey{}).(*contextOverride)
+	for ; o != nil; o = o.parent {
+		if o.item.key == k {
+			s.auditRead(key, o.item, skip+1)
+			return o.item, nil
+		}
+	}
+	return s.get(key, skip+1)
+}
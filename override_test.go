@@ -0,0 +1,94 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFromContext(t *testing.T) {
+	s := NewStore()
+	s.InMemory("test").Add(NewItem("rate-limit", "100", 0), NewItem("burst", "10", 0))
+
+	ctx := WithConfigOverride(context.Background(), "RATE_LIMIT", "50")
+	tenantA := WithConfigOverride(ctx, "rate-limit", "20")
+	tenantB := WithConfigOverride(ctx, "burst", "5")
+
+	for _, tc := range []struct {
+		ctx      context.Context
+		key      string
+		expected string
+	}{
+		{context.Background(), "rate-limit", "100"},
+		{ctx, "rate-limit", "50"},
+		{ctx, "burst", "10"},
+		{tenantA, "rate-limit", "20"},
+		{tenantA, "burst", "10"},
+		{tenantB, "rate-limit", "50"},
+		{tenantB, "burst", "5"},
+	} {
+		i, err := s.GetFromContext(tc.ctx, tc.key)
+		require.NoError(t, err)
+		v, err := i.Value()
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, v, tc.key)
+	}
+
+	i, err := s.GetFromContext(tenantA, "rate-limit")
+	require.NoError(t, err)
+	assert.Equal(t, ContextOverrideSource, i.Source())
+	assert.Equal(t, "rate-limit", i.Key())
+}
+
+func TestGetFromContextWithoutOverride(t *testing.T) {
+	s := NewStore()
+	s.InMemory("test").Add(NewItem("a", "1", 0), NewItem("dup", "1", 0), NewItem("dup", "2", 0))
+	ctx := WithConfigOverride(context.Background(), "other", "x")
+
+	for _, key := range []string{"a", "dup", "missing"} {
+		expected, expectedErr := s.Get(key)
+		i, err := s.GetFromContext(ctx, key)
+		assert.Equal(t, expected, i, key)
+		assert.Equal(t, expectedErr, err, key)
+	}
+	_, err := s.GetFromContext(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+type sourceAuditRecorder struct {
+	keys    []string
+	sources []string
+	lines   []int
+}
+
+func (r *sourceAuditRecorder) LogRead(key, callerPackage, callerFile string, line int, isSensitive bool) {
+	panic("LogReadSource must be called instead")
+}
+
+func (r *sourceAuditRecorder) LogReadSource(key, source, callerPackage, callerFile string, line int, isSensitive bool) {
+	r.keys = append(r.keys, key)
+	r.sources = append(r.sources, source)
+	r.lines = append(r.lines, line)
+}
+
+func TestGetFromContextAuditLog(t *testing.T) {
+	s := NewStore()
+	s.InMemory("test").Add(NewItem("rate-limit", "100", 0))
+	r := &sourceAuditRecorder{}
+	s.EnableAuditLog(r)
+
+	ctx := WithConfigOverride(context.Background(), "rate-limit", "20")
+	_, err := s.GetFromContext(ctx, "rate-limit")
+	_, _, line, _ := runtime.Caller(0)
+	require.NoError(t, err)
+	_, err = s.GetFromContext(context.Background(), "rate-limit")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"rate-limit", "rate-limit"}, r.keys)
+	assert.Equal(t, []string{ContextOverrideSource, "test"}, r.sources)
+	assert.Equal(t, []int{line - 1, line + 2}, r.lines)
+}
@@ -0,0 +1,82 @@
+package configstore
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PatchSource is the source (see Item.Source) of the items injected with Patch.
+const PatchSource = "patch"
+
+// Patch temporarily overrides the value of a key, e.g. during an incident, without changing the providers: the patch
+// is an item with the highest possible priority, replacing the items of all the providers for that key. It expires
+// after the given duration, or never if it is not positive, and the returned function removes it right away. Patching
+// a key again replaces its previous patch, whose revert function then does nothing. The patches are held by the store
+// rather than by a provider, so they are kept when the providers are refreshed, replaced or restored (see Restore).
+// The watchers are notified when a patch is applied, reverted or expires.
+func (s *Store) Patch(key, value string, ttl time.Duration) (revert func()) {
+	it := NewItem(key, value, math.MaxInt64)
+	if ttl > 0 {
+		it.expires = time.Now().Add(ttl)
+	}
+
+	s.pMut.Lock()
+	if s.patches == nil {
+		s.patches = map[string]*patch{}
+	}
+	p := &patch{item: it}
+	s.patches[it.key] = p
+	s.pMut.Unlock()
+	s.NotifyWatchers()
+
+	revert = func() {
+		s.pMut.Lock()
+		if s.patches[it.key] != p {
+			s.pMut.Unlock()
+			return
+		}
+		delete(s.patches, it.key)
+		s.pMut.Unlock()
+		s.NotifyWatchers()
+	}
+	if ttl > 0 {
+		time.AfterFunc(ttl, revert)
+	}
+	return revert
+}
+
+type patch struct {
+	item Item
+}
+
+// Replaces the items of the patched keys with the items of the patches, see Patch.
+// Must be called with s.pMut held.
+func (s *Store) applyPatches(l *ItemList, now time.Time) {
+	if len(s.patches) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(s.patches))
+	for k, p := range s.patches {
+		if !p.item.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	patched := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		patched[k] = true
+	}
+	items := make([]Item, 0, len(l.Items)+len(keys))
+	for _, it := range l.Items {
+		if !patched[it.key] {
+			items = append(items, it)
+		}
+	}
+	for _, k := range keys {
+		it := s.patches[k].item
+		it.source = PatchSource
+		items = append(items, it)
+	}
+	l.Items = items
+}
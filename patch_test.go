@@ -0,0 +1,111 @@
+package configstore
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorePatch(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("rate-limit", "10", 100)
+	ch := s.Watch()
+
+	revert := s.Patch("RATE_LIMIT", "1000", time.Hour)
+	<-ch
+	i, err := s.GetItem("rate-limit")
+	require.NoError(t, err, "the patch must shadow the provider item")
+	assert.Equal(t, "1000", mustValue(i))
+	assert.Equal(t, PatchSource, i.Source())
+
+	// the patch is kept when the providers change
+	s.UnregisterProvider("inmem")
+	s.InMemory("inmem").Set("rate-limit", "20", 100)
+	v, err := s.GetItemValue("rate-limit")
+	require.NoError(t, err)
+	assert.Equal(t, "1000", v)
+
+	revert()
+	v, err = s.GetItemValue("rate-limit")
+	require.NoError(t, err)
+	assert.Equal(t, "20", v)
+	revert()
+}
+
+func TestStorePatchReplace(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("a", "provider", 1)
+
+	revert1 := s.Patch("a", "first", 0)
+	revert2 := s.Patch("a", "second", 0)
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "second", v)
+
+	revert1()
+	v, err = s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "second", v, "a replaced patch must not revert the new one")
+
+	revert2()
+	v, err = s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "provider", v)
+}
+
+func TestStorePatchExpiration(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("a", "provider", 1)
+	ch := s.Watch()
+
+	s.Patch("a", "patched", 50*time.Millisecond)
+	<-ch
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "patched", v)
+
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the expiration must be notified")
+	}
+	v, err = s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "provider", v)
+}
+
+func TestStorePatchConcurrent(t *testing.T) {
+	const keys = 20
+	s := NewStore()
+	var wg sync.WaitGroup
+	reverts := make([]func(), keys)
+	for i := 0; i < keys; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reverts[i] = s.Patch("key-"+strconv.Itoa(i), strconv.Itoa(i), time.Hour)
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < keys; i++ {
+		v, err := s.GetItemValue("key-" + strconv.Itoa(i))
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(i), v)
+	}
+
+	for i := 0; i < keys; i += 2 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reverts[i]()
+		}(i)
+	}
+	wg.Wait()
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Len(t, l.Items, keys/2)
+}
@@ -0,0 +1,21 @@
+module github.com/ovh/configstore/pflagbind
+
+go 1.19
+
+replace github.com/ovh/configstore => ../
+
+require (
+	github.com/ovh/configstore v0.0.0-00010101000000-000000000000
+	github.com/spf13/pflag v1.0.5
+	github.com/stretchr/testify v1.6.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/fsnotify/fsnotify v1.5.1 // indirect
+	github.com/ghodss/yaml v1.0.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
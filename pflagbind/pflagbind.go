@@ -0,0 +1,88 @@
+// Package pflagbind binds configstore items to github.com/spf13/pflag flags, as used by cobra.
+//
+// Flag names are matched against item keys after normalization, so that the flag "db-host"
+// matches the item "db-host", "DB_HOST" or "db_host".
+package pflagbind
+
+import (
+	"fmt"
+
+	"github.com/ovh/configstore"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// DefaultPriority is the priority of the items pushed by PopulateFromFlags for flags left at their default value.
+	DefaultPriority int64 = 0
+	// ChangedPriority is the priority of the items pushed by PopulateFromFlags for flags set on the command line,
+	// high enough to override the other usual sources.
+	ChangedPriority int64 = 100
+)
+
+// BindPFlags sets the default value of the flags from the store items with the same name.
+// Flags explicitly set on the command line keep their value: BindPFlags can be called either before or after
+// parsing the command line. Flags with no matching item are left untouched.
+// Multiple items with the same key set a slice flag to all their values.
+func BindPFlags(s *configstore.Store, fs *pflag.FlagSet) error {
+	l, err := s.GetItemList()
+	if err != nil {
+		return err
+	}
+
+	var bindErr error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if bindErr != nil || f.Changed {
+			return
+		}
+		items := configstore.Filter().Slice(f.Name).Apply(l).Items
+		if len(items) == 0 {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			vals := make([]string, 0, len(items))
+			for _, it := range items {
+				v, err := it.Value()
+				if err != nil {
+					bindErr = fmt.Errorf("pflagbind: flag '%s': %v", f.Name, err)
+					return
+				}
+				vals = append(vals, v)
+			}
+			if err := sv.Replace(vals); err != nil {
+				bindErr = fmt.Errorf("pflagbind: flag '%s': %v", f.Name, err)
+				return
+			}
+		} else {
+			v, err := items[0].Value()
+			if err != nil {
+				bindErr = fmt.Errorf("pflagbind: flag '%s': %v", f.Name, err)
+				return
+			}
+			if err := f.Value.Set(v); err != nil {
+				bindErr = fmt.Errorf("pflagbind: flag '%s': invalid value %q: %v", f.Name, v, err)
+				return
+			}
+		}
+		f.DefValue = f.Value.String()
+	})
+	return bindErr
+}
+
+// PopulateFromFlags pushes the values of all the flags of the set as items.
+// Flags set on the command line get ChangedPriority, so that they override the other sources,
+// while flags left at their default value get DefaultPriority. Slice flags push an item per value.
+func PopulateFromFlags(inmem *configstore.InMemoryProvider, fs *pflag.FlagSet) {
+	fs.VisitAll(func(f *pflag.Flag) {
+		priority := DefaultPriority
+		if f.Changed {
+			priority = ChangedPriority
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				inmem.Add(configstore.NewItem(f.Name, v, priority))
+			}
+			return
+		}
+		inmem.Add(configstore.NewItem(f.Name, f.Value.String(), priority))
+	})
+}
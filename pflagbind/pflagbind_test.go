@@ -0,0 +1,85 @@
+package pflagbind
+
+import (
+	"testing"
+
+	"github.com/ovh/configstore"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindPFlags(t *testing.T) {
+	s := configstore.NewStore()
+	s.InMemory("config").Add(
+		configstore.NewItem("db_host", "db.example.com", 1),
+		configstore.NewItem("port", "5432", 1),
+		configstore.NewItem("tags", "a", 2),
+		configstore.NewItem("tags", "b", 1),
+	)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	host := fs.String("db-host", "localhost", "")
+	port := fs.Int("port", 80, "")
+	tags := fs.StringSlice("tags", nil, "")
+	debug := fs.Bool("debug", false, "")
+
+	require.NoError(t, fs.Parse([]string{"--port=8080"}))
+	require.NoError(t, BindPFlags(s, fs))
+
+	assert.Equal(t, "db.example.com", *host, "the store value must override the default")
+	assert.Equal(t, 8080, *port, "the command line must override the store value")
+	assert.Equal(t, []string{"a", "b"}, *tags)
+	assert.False(t, *debug)
+	assert.Equal(t, "db.example.com", fs.Lookup("db-host").DefValue)
+	assert.False(t, fs.Lookup("db-host").Changed)
+}
+
+func TestBindPFlagsBeforeParse(t *testing.T) {
+	s := configstore.NewStore()
+	s.InMemory("config").Add(configstore.NewItem("port", "5432", 1))
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	port := fs.Int("port", 80, "")
+	require.NoError(t, BindPFlags(s, fs))
+	assert.Equal(t, 5432, *port)
+	require.NoError(t, fs.Parse([]string{"--port=8080"}))
+	assert.Equal(t, 8080, *port)
+}
+
+func TestBindPFlagsInvalid(t *testing.T) {
+	s := configstore.NewStore()
+	s.InMemory("config").Add(configstore.NewItem("port", "http", 1))
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Int("port", 80, "")
+	assert.Error(t, BindPFlags(s, fs))
+}
+
+func TestPopulateFromFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("db-host", "localhost", "")
+	fs.Int("port", 80, "")
+	fs.StringSlice("tags", []string{"a", "b"}, "")
+	require.NoError(t, fs.Parse([]string{"--port=8080"}))
+
+	s := configstore.NewStore()
+	s.InMemory("config").Add(
+		configstore.NewItem("db-host", "db.example.com", 1),
+		configstore.NewItem("port", "5432", 1),
+	)
+	PopulateFromFlags(s.InMemory("flags"), fs)
+
+	// the store value beats the flag default, the command line beats the store value
+	i, err := s.GetFirst("db-host")
+	require.NoError(t, err)
+	v, _ := i.Value()
+	assert.Equal(t, "db.example.com", v)
+	i, err = s.GetFirst("port")
+	require.NoError(t, err)
+	v, _ = i.Value()
+	assert.Equal(t, "8080", v)
+	vals, err := s.GetItemValueList("tags")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, vals)
+}
@@ -0,0 +1,74 @@
+package configstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProfileEnv is the environment variable holding the active profile, when SetProfile is not called.
+const ProfileEnv = "ENV_PROFILE"
+
+// DefaultProfile is the name of the profile every profile falls back to, see FileProfile.
+const DefaultProfile = "default"
+
+// SetProfile sets the active profile of the store, e.g. "dev", "staging" or "production".
+// Several profiles can be stacked in a comma-separated list, e.g. "production,eu": the last ones take precedence.
+// When SetProfile is not called, the profile is read from the ENV_PROFILE environment variable.
+func (s *Store) SetProfile(profile string) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.profile = &profile
+}
+
+// ActiveProfile returns the active profile of the store, see SetProfile.
+func (s *Store) ActiveProfile() string {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	if s.profile != nil {
+		return *s.profile
+	}
+	return os.Getenv(ProfileEnv)
+}
+
+// FileProfile registers a configstore provider reading the profile files from baseDir (static content):
+// {baseDir}/{profile}.yaml, falling back to {baseDir}/default.yaml for the keys it does not define.
+// If profile is empty, the active profile of the store is used (see ActiveProfile).
+// With stacked profiles (e.g. "production,eu"), each profile overrides the keys of the previous ones.
+// Missing profile files are ignored, but at least one of the files must exist.
+func FileProfile(s *Store, baseDir, profile string) {
+	if profile == "" {
+		profile = s.ActiveProfile()
+	}
+	profiles := []string{DefaultProfile}
+	for _, p := range strings.Split(profile, ",") {
+		if p = strings.TrimSpace(p); p != "" && p != DefaultProfile {
+			profiles = append(profiles, p)
+		}
+	}
+
+	providername := fmt.Sprintf("profile:%s:%s", baseDir, strings.Join(profiles, ","))
+
+	// the last profile comes first in the fallback chain
+	var layers []Provider
+	for i := len(profiles) - 1; i >= 0; i-- {
+		filename := filepath.Join(baseDir, profiles[i]+".yaml")
+		vals, err := readFile(s, providername, filename, nil)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errorProvider(s, providername, err)
+			return
+		}
+		s.logInfo("configuration from profile", "provider", providername, "filename", filename, "key_count", len(vals))
+		layers = append(layers, (&InMemoryProvider{items: vals}).Items)
+	}
+	if len(layers) == 0 {
+		errorProvider(s, providername, fmt.Errorf("configstore: no profile file found in '%s' for profile(s): %s", baseDir, strings.Join(profiles, ", ")))
+		return
+	}
+	s.RegisterProvider(providername, FallbackProvider(layers...))
+}
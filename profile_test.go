@@ -0,0 +1,60 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProfiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestActiveProfile(t *testing.T) {
+	t.Setenv(ProfileEnv, "staging")
+	s := NewStore()
+	assert.Equal(t, "staging", s.ActiveProfile())
+	s.SetProfile("dev")
+	assert.Equal(t, "dev", s.ActiveProfile())
+	s.SetProfile("")
+	assert.Equal(t, "", s.ActiveProfile(), "an explicit profile takes precedence over the environment")
+}
+
+func TestFileProfile(t *testing.T) {
+	dir := writeProfiles(t, map[string]string{
+		"default.yaml":    "- key: db-host\n  value: localhost\n- key: debug\n  value: \"false\"\n- key: region\n  value: local\n",
+		"production.yaml": "- key: db-host\n  value: db.example.com\n- key: region\n  value: us\n",
+		"eu.yaml":         "- key: region\n  value: eu\n",
+	})
+
+	// fallback to the default profile
+	s := NewStore()
+	s.SetProfile("missing")
+	FileProfile(s, dir, "")
+	assert.Equal(t, "localhost", mustValue(must(s.GetItem("db-host")).(Item)))
+
+	s = NewStore()
+	FileProfile(s, dir, "production")
+	assert.Equal(t, "db.example.com", mustValue(must(s.GetItem("db-host")).(Item)))
+	assert.Equal(t, "false", mustValue(must(s.GetItem("debug")).(Item)), "keys not defined by the profile fall back to the default one")
+
+	// stacked profiles, the last one wins
+	s = NewStore()
+	s.SetProfile("production, eu")
+	FileProfile(s, dir, "")
+	assert.Equal(t, "eu", mustValue(must(s.GetItem("region")).(Item)))
+	assert.Equal(t, "db.example.com", mustValue(must(s.GetItem("db-host")).(Item)))
+	assert.Equal(t, "false", mustValue(must(s.GetItem("debug")).(Item)))
+
+	s = NewStore()
+	FileProfile(s, t.TempDir(), "production")
+	_, err := s.GetItemList()
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+// Package prommetrics exposes the configstore provider loads and errors as Prometheus metrics.
+//
+//	o, err := prommetrics.NewObserver(prometheus.DefaultRegisterer)
+//	if err != nil {
+//		return err
+//	}
+//	configstore.SetMetricsObserver(o)
+package prommetrics
+
+import (
+	"time"
+
+	"github.com/ovh/configstore"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Observer is a configstore.MetricsObserver maintaining the following metrics:
+//   - configstore_provider_reloads_total{provider}: number of times a provider loaded its items
+//   - configstore_provider_errors_total{provider}: number of times a provider failed to load its items
+//   - configstore_provider_timeouts_total{provider}: number of times a provider call exceeded its timeout
+//   - configstore_provider_items{provider}: number of items loaded by a provider
+//   - configstore_watchers_notifications_total: number of configuration change notifications
+type Observer struct {
+	reloads       *prometheus.CounterVec
+	errors        *prometheus.CounterVec
+	timeouts      *prometheus.CounterVec
+	items         *prometheus.GaugeVec
+	notifications prometheus.Counter
+}
+
+var _ configstore.TimeoutObserver = (*Observer)(nil)
+
+// NewObserver creates an Observer and registers its metrics with the given registerer.
+func NewObserver(reg prometheus.Registerer) (*Observer, error) {
+	o := &Observer{
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "configstore_provider_reloads_total",
+			Help: "Number of times a configstore provider loaded its items.",
+		}, []string{"provider"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "configstore_provider_errors_total",
+			Help: "Number of times a configstore provider failed to load its items.",
+		}, []string{"provider"}),
+		timeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "configstore_provider_timeouts_total",
+			Help: "Number of times a configstore provider call exceeded its timeout.",
+		}, []string{"provider"}),
+		items: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "configstore_provider_items",
+			Help: "Number of items loaded by a configstore provider.",
+		}, []string{"provider"}),
+		notifications: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "configstore_watchers_notifications_total",
+			Help: "Number of configuration change notifications sent to the configstore watchers.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{o.reloads, o.errors, o.timeouts, o.items, o.notifications} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// ProviderReloaded implements configstore.MetricsObserver.
+func (o *Observer) ProviderReloaded(provider string, itemCount int) {
+	o.reloads.WithLabelValues(provider).Inc()
+	o.items.WithLabelValues(provider).Set(float64(itemCount))
+}
+
+// ProviderError implements configstore.MetricsObserver.
+func (o *Observer) ProviderError(provider string, err error) {
+	o.errors.WithLabelValues(provider).Inc()
+}
+
+// ProviderTimeout implements configstore.TimeoutObserver.
+func (o *Observer) ProviderTimeout(provider string, elapsed time.Duration) {
+	o.timeouts.WithLabelValues(provider).Inc()
+}
+
+// WatchersNotified implements configstore.MetricsObserver.
+func (o *Observer) WatchersNotified() {
+	o.notifications.Inc()
+}
+
+// Handler is a configstore.MetricsHandler maintaining the following metrics:
+//   - configstore_provider_call_duration_seconds{provider}: duration of the provider calls made by the store
+//   - configstore_provider_call_errors_total{provider}: number of provider calls which returned an error
+//   - configstore_watchers_notified_total: number of notifications sent to the watchers, one per watcher
+type Handler struct {
+	durations *prometheus.HistogramVec
+	errors    *prometheus.CounterVec
+	notified  prometheus.Counter
+}
+
+var _ configstore.MetricsHandler = (*Handler)(nil)
+
+// NewHandler creates a Handler and registers its metrics with the given registerer.
+//
+//	h, err := prommetrics.NewHandler(prometheus.DefaultRegisterer)
+//	if err != nil {
+//		return err
+//	}
+//	configstore.SetMetricsHandler(h)
+func NewHandler(reg prometheus.Registerer) (*Handler, error) {
+	h := &Handler{
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "configstore_provider_call_duration_seconds",
+			Help:    "Duration of the configstore provider calls.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"provider"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "configstore_provider_call_errors_total",
+			Help: "Number of configstore provider calls which returned an error.",
+		}, []string{"provider"}),
+		notified: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "configstore_watchers_notified_total",
+			Help: "Number of notifications sent to the configstore watchers, one per watcher.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{h.durations, h.errors, h.notified} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// RecordProviderLoad implements configstore.MetricsHandler.
+func (h *Handler) RecordProviderLoad(name string, duration time.Duration, err error) {
+	h.durations.WithLabelValues(name).Observe(duration.Seconds())
+	if err != nil {
+		h.errors.WithLabelValues(name).Inc()
+	}
+}
+
+// RecordWatcherNotification implements configstore.MetricsHandler.
+func (h *Handler) RecordWatcherNotification(count int) {
+	h.notified.Add(float64(count))
+}
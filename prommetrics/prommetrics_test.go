@@ -0,0 +1,81 @@
+package prommetrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ovh/configstore"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	o, err := NewObserver(reg)
+	require.NoError(t, err)
+
+	o.ProviderReloaded("file:a.yaml", 3)
+	o.ProviderReloaded("file:a.yaml", 5)
+	o.ProviderError("file:b.yaml", errors.New("boom"))
+	o.ProviderTimeout("vault", time.Second)
+	o.WatchersNotified()
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP configstore_provider_errors_total Number of times a configstore provider failed to load its items.
+# TYPE configstore_provider_errors_total counter
+configstore_provider_errors_total{provider="file:b.yaml"} 1
+# HELP configstore_provider_items Number of items loaded by a configstore provider.
+# TYPE configstore_provider_items gauge
+configstore_provider_items{provider="file:a.yaml"} 5
+# HELP configstore_provider_reloads_total Number of times a configstore provider loaded its items.
+# TYPE configstore_provider_reloads_total counter
+configstore_provider_reloads_total{provider="file:a.yaml"} 2
+# HELP configstore_provider_timeouts_total Number of times a configstore provider call exceeded its timeout.
+# TYPE configstore_provider_timeouts_total counter
+configstore_provider_timeouts_total{provider="vault"} 1
+# HELP configstore_watchers_notifications_total Number of configuration change notifications sent to the configstore watchers.
+# TYPE configstore_watchers_notifications_total counter
+configstore_watchers_notifications_total 1
+`))
+	assert.NoError(t, err)
+
+	_, err = NewObserver(reg)
+	assert.Error(t, err, "metrics cannot be registered twice")
+}
+
+func TestHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h, err := NewHandler(reg)
+	require.NoError(t, err)
+
+	s := configstore.NewStore()
+	s.SetMetricsHandler(h)
+	s.Watch()
+	s.Watch()
+	s.RegisterProvider("ok", func() (configstore.ItemList, error) {
+		return configstore.ItemList{Items: []configstore.Item{configstore.NewItem("a", "1", 1)}}, nil
+	})
+	s.RegisterProvider("failing", func() (configstore.ItemList, error) {
+		return configstore.ItemList{}, errors.New("boom")
+	})
+	_, err = s.GetItemList()
+	require.Error(t, err)
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP configstore_provider_call_errors_total Number of configstore provider calls which returned an error.
+# TYPE configstore_provider_call_errors_total counter
+configstore_provider_call_errors_total{provider="failing"} 1
+# HELP configstore_watchers_notified_total Number of notifications sent to the configstore watchers, one per watcher.
+# TYPE configstore_watchers_notified_total counter
+configstore_watchers_notified_total 4
+`), "configstore_provider_call_errors_total", "configstore_watchers_notified_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, testutil.CollectAndCount(h.durations))
+
+	_, err = NewHandler(reg)
+	assert.Error(t, err, "metrics cannot be registered twice")
+}
@@ -0,0 +1,25 @@
+package configstore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoadFromArgs registers an in-memory provider with the given name, holding an item with the given priority
+// for each "KEY=VALUE" argument, e.g. positional command line arguments. The argument is split on the first '=',
+// and the key is normalized like any other key. Nothing is registered if an argument is malformed.
+func (s *Store) LoadFromArgs(name string, priority int64, args []string) error {
+	items := make([]Item, 0, len(args))
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("configstore: provider '%s': malformed argument '%s', expected KEY=VALUE", name, arg)
+		}
+		items = append(items, NewItem(parts[0], parts[1], priority))
+	}
+	inmem := inMemoryProvider(s, name)
+	s.logInfo("configuration from arguments", "provider", name, "key_count", len(items))
+	s.observeReload(name, len(items))
+	inmem.Add(items...)
+	return nil
+}
@@ -0,0 +1,38 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreLoadFromArgs(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: db-host\n  value: file\n  priority: 10\n- key: port\n  value: \"80\"\n"), 0600))
+
+	s := NewStore()
+	s.File(filename)
+	require.NoError(t, s.LoadFromArgs("args", 100, []string{"DB_HOST=localhost", "query=a=b", "empty="}))
+
+	it, err := s.GetFirst("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", mustValue(it), "arguments must override the file at a higher priority")
+	v, err := s.GetItemValue("query")
+	require.NoError(t, err)
+	assert.Equal(t, "a=b", v)
+	v, err = s.GetItemValue("empty")
+	require.NoError(t, err)
+	assert.Equal(t, "", v)
+	v, err = s.GetItemValue("port")
+	require.NoError(t, err)
+	assert.Equal(t, "80", v)
+
+	assert.EqualError(t, s.LoadFromArgs("bad", 100, []string{"a=1", "oops"}),
+		"configstore: provider 'bad': malformed argument 'oops', expected KEY=VALUE")
+	assert.Error(t, s.LoadFromArgs("bad", 100, []string{"=1"}))
+	_, err = s.GetItemValue("a")
+	assert.Error(t, err, "nothing must be registered on error")
+}
@@ -0,0 +1,40 @@
+package configstore
+
+import (
+	"os"
+	"strings"
+)
+
+// CascadeFileProvider loads the first of the given files which exists and can be decoded, e.g. to look for the
+// configuration in several places: CascadeFileProvider(s, "./app.yaml", "/usr/local/etc/app.yaml", "/etc/app.yaml").
+// The subsequent files are not read. The provider is named after the chosen file, as the File provider would be.
+// The files which cannot be decoded are skipped, and if none of the files can be decoded the first error is returned
+// by the provider. If none of the files exists, no provider is registered.
+func CascadeFileProvider(s *Store, filenames ...string) {
+	var firstErr error
+	var firstName string
+	for _, filename := range filenames {
+		providername := buildProviderName("file", false, filename)
+		vals, err := readFile(s, providername, filename, nil)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			s.logError(err, "provider", providername, "filename", filename)
+			if firstErr == nil {
+				firstErr, firstName = err, providername
+			}
+			continue
+		}
+		inmem := inMemoryProvider(s, providername)
+		s.logInfo("configuration from file", "provider", providername, "filename", filename, "key_count", len(vals))
+		s.observeReload(providername, len(vals))
+		inmem.Add(vals...)
+		return
+	}
+	if firstErr != nil {
+		errorProvider(s, firstName, firstErr)
+		return
+	}
+	s.logInfo("no configuration file found", "filenames", strings.Join(filenames, ","))
+}
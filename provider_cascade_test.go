@@ -0,0 +1,70 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCascadeFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	third := filepath.Join(dir, "third.yaml")
+	require.NoError(t, os.WriteFile(second, []byte("- key: a\n  value: second\n"), 0600))
+
+	s := NewStore()
+	CascadeFileProvider(s, first, second, third)
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	require.Len(t, l.Items, 1)
+	i, err := l.GetItem("a")
+	require.NoError(t, err)
+	assert.Equal(t, "second", mustValue(i))
+	assert.Equal(t, "file:"+second, i.Source())
+
+	// the subsequent files are not read
+	require.NoError(t, os.WriteFile(third, []byte("- key: b\n  value: third\n"), 0600))
+	s = NewStore()
+	CascadeFileProvider(s, first, second, third)
+	l, err = s.GetItemList()
+	require.NoError(t, err)
+	assert.Len(t, l.Items, 1)
+}
+
+func TestCascadeFileProviderMalformed(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	require.NoError(t, os.WriteFile(first, []byte("not: [a list"), 0600))
+
+	// a malformed file is skipped
+	require.NoError(t, os.WriteFile(second, []byte("- key: a\n  value: second\n"), 0600))
+	s := NewStore()
+	CascadeFileProvider(s, first, second)
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "second", v)
+
+	// but reported if no other file can be loaded
+	require.NoError(t, os.Remove(second))
+	s = NewStore()
+	CascadeFileProvider(s, first, second)
+	_, err = s.GetItemList()
+	var perr *ProviderParseError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, first, perr.Filename)
+}
+
+func TestCascadeFileProviderNone(t *testing.T) {
+	s := NewStore()
+	CascadeFileProvider(s, filepath.Join(t.TempDir(), "missing.yaml"))
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Empty(t, l.Items)
+}
@@ -0,0 +1,51 @@
+package configstore
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ChanProvider registers a configstore provider serving the last item slice received on ch, e.g. pushed by a stream
+// of updates. Each slice replaces the whole item set, and watchers get notified of the changes, with their diff
+// (see WatchDiff).
+// The provider has no items until the first slice is received. The returned function stops consuming ch,
+// which also stops when ch is closed or the store is closed; the provider keeps serving the last slice received.
+func ChanProvider(s *Store, name string, ch <-chan []Item) (cancel func()) {
+	inmem := inMemoryProvider(s, name)
+	stop := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-stop:
+				return
+			case items, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				old, _ := inmem.Items()
+				s.observeReload(name, len(items))
+				if reflect.DeepEqual(old.Items, items) {
+					continue
+				}
+				diff := Diff(old, ItemList{Items: items})
+				inmem.Replace(items...)
+				if s.revalidate() {
+					s.notifyChange(diff)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(stop) })
+	}
+}
@@ -0,0 +1,100 @@
+package configstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanProvider(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+	w := s.Watch()
+	ch := make(chan []Item)
+	cancel := ChanProvider(s, "stream", ch)
+	defer cancel()
+	<-w // registration
+
+	for _, v := range []string{"1", "2", "3"} {
+		ch <- []Item{NewItem("a", v, 1), NewItem("b", "b"+v, 1)}
+		select {
+		case <-w:
+		case <-time.After(time.Second):
+			t.Fatal("watchers not notified")
+		}
+		l, err := s.GetItemList()
+		require.NoError(t, err)
+		assert.Equal(t, 2, l.Len())
+		a, err := l.GetItemValue("a")
+		require.NoError(t, err)
+		assert.Equal(t, v, a)
+	}
+
+	ch <- []Item{NewItem("c", "1", 1)}
+	<-w
+	_, err := s.GetItemValue("a")
+	assert.Error(t, err, "each slice must replace the whole item set")
+}
+
+func TestChanProviderStop(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+
+	closed := make(chan []Item)
+	ChanProvider(s, "closed", closed)
+	close(closed)
+
+	ch := make(chan []Item)
+	cancel := ChanProvider(s, "cancelled", ch)
+	cancel()
+	cancel()
+	select {
+	case ch <- []Item{NewItem("a", "1", 1)}:
+	case <-time.After(50 * time.Millisecond):
+	}
+	time.Sleep(10 * time.Millisecond)
+	_, err := s.GetItemValue("a")
+	assert.Error(t, err, "slices received after cancel must be ignored")
+}
+
+func TestChanProviderDiff(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+	s.AddValidator(validPort)
+	diffs := s.WatchDiff()
+	ch := make(chan []Item)
+	cancel := ChanProvider(s, "stream", ch)
+	defer cancel()
+
+	ch <- []Item{NewItem("port", "8080", 1)}
+	select {
+	case d := <-diffs:
+		require.Len(t, d.Added, 1)
+		assert.Equal(t, "port", d.Added[0].Key())
+	case <-time.After(time.Second):
+		t.Fatal("no diff notified")
+	}
+
+	// a rejected configuration is not notified
+	w := s.Watch()
+	ch <- []Item{NewItem("port", "0", 1)}
+	require.Eventually(t, func() bool { return s.ValidationError() != nil }, time.Second, time.Millisecond)
+	select {
+	case <-w:
+		t.Fatal("watchers must not be notified of a rejected configuration")
+	default:
+	}
+
+	ch <- []Item{NewItem("port", "9090", 1)}
+	select {
+	case d := <-diffs:
+		assert.Equal(t, []ItemChange{{Key: "port", OldValue: "0", NewValue: "9090"}}, d.Modified)
+	case <-time.After(time.Second):
+		t.Fatal("no diff notified")
+	}
+	v, err := s.GetItemValueInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9090), v)
+}
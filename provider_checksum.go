@@ -0,0 +1,45 @@
+package configstore
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+)
+
+// FileWithChecksum registers a configstore provider which reads from the file given in parameter (static content),
+// after verifying its integrity against the hex digest stored in checksumFile. The algorithm is "sha256" or "sha512".
+// The checksum file can hold the digest alone, or the output of the sha256sum/sha512sum tools.
+// On a mismatch, no item is loaded and the provider returns a *ChecksumMismatchError.
+func FileWithChecksum(s *Store, filename, checksumFile string, alg string) {
+	var newHash func() hash.Hash
+	switch strings.ToLower(alg) {
+	case "sha256":
+		newHash = sha256.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		errorProvider(s, buildProviderName("file", false, filename), fmt.Errorf("configstore: checksum: unsupported algorithm '%s'", alg))
+		return
+	}
+	file(s.ctx, s, filename, false, func(b []byte) ([]Item, error) {
+		sum, err := os.ReadFile(checksumFile)
+		if err != nil {
+			return nil, fmt.Errorf("configstore: checksum: %v", err)
+		}
+		fields := strings.Fields(string(sum))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("configstore: checksum: '%s' is empty", checksumFile)
+		}
+		h := newHash()
+		h.Write(b)
+		expected, actual := strings.ToLower(fields[0]), hex.EncodeToString(h.Sum(nil))
+		if expected != actual {
+			return nil, &ChecksumMismatchError{Filename: filename, Algorithm: strings.ToLower(alg), Expected: expected, Actual: actual}
+		}
+		return DecodeItems(DefaultFilePriority)(b)
+	})
+}
@@ -0,0 +1,43 @@
+package configstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWithChecksum(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.yaml")
+	checksumFile := filepath.Join(dir, "config.yaml.sha256")
+	content := []byte("- key: db-host\n  value: localhost\n")
+	require.NoError(t, os.WriteFile(filename, content, 0600))
+	sum := sha256.Sum256(content)
+	require.NoError(t, os.WriteFile(checksumFile, []byte(hex.EncodeToString(sum[:])+"  config.yaml\n"), 0600))
+
+	s := NewStore()
+	FileWithChecksum(s, filename, checksumFile, "sha256")
+	v, err := s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+
+	require.NoError(t, os.WriteFile(filename, []byte("- key: db-host\n  value: attacker\n"), 0600))
+	s = NewStore()
+	FileWithChecksum(s, filename, checksumFile, "sha256")
+	_, err = s.GetItemValue("db-host")
+	var mismatch *ChecksumMismatchError
+	require.True(t, errors.As(err, &mismatch), "unexpected error: %v", err)
+	assert.Equal(t, filename, mismatch.Filename)
+	assert.Equal(t, hex.EncodeToString(sum[:]), mismatch.Expected)
+
+	s = NewStore()
+	FileWithChecksum(s, filename, checksumFile, "md5")
+	_, err = s.GetItemList()
+	assert.Error(t, err)
+}
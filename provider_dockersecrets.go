@@ -0,0 +1,99 @@
+package configstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DockerSecretsDir is the directory where Docker mounts the secrets of a service.
+const DockerSecretsDir = "/run/secrets"
+
+// DockerSecretsProvider registers a configstore provider reading the Docker secrets mounted in dir
+// (DockerSecretsDir if empty): each file is a sensitive item (see NewSecretItem), named after the file, with its
+// content as value and a priority of 5, like the FileTree provider. Hidden files and directories are skipped.
+// If the directory does not exist, the provider is registered with no items.
+func DockerSecretsProvider(s *Store, dir string) {
+	dockerSecrets(s, dir, 0)
+}
+
+// DockerSecretsRefreshProvider is similar to DockerSecretsProvider, scanning the directory again at the given interval
+// to pick up rotated secrets. Watchers get notified when the secrets change.
+func DockerSecretsRefreshProvider(s *Store, dir string, interval time.Duration) {
+	dockerSecrets(s, dir, interval)
+}
+
+func dockerSecrets(s *Store, dir string, refresh time.Duration) {
+	if dir == "" {
+		dir = DockerSecretsDir
+	}
+	providername := buildProviderName("dockersecrets", refresh > 0, dir)
+
+	vals, err := readDockerSecrets(dir)
+	if err != nil {
+		errorProvider(s, providername, err)
+		return
+	}
+	inmem := inMemoryProvider(s, providername)
+	s.logInfo("configuration from docker secrets", "provider", providername, "key_count", len(vals))
+	s.observeReload(providername, len(vals))
+	inmem.Add(vals...)
+
+	if refresh <= 0 {
+		return
+	}
+
+	go func() {
+		t := time.NewTicker(refresh)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-t.C:
+			}
+			vals, err := readDockerSecrets(dir)
+			if err != nil {
+				s.logError(err, "provider", providername)
+				s.observeError(providername, err)
+				continue
+			}
+			old, _ := inmem.Items()
+			s.observeReload(providername, len(vals))
+			if reflect.DeepEqual(old.Items, vals) {
+				continue
+			}
+			diff := Diff(old, ItemList{Items: vals})
+			inmem.Replace(vals...)
+			if s.revalidate() {
+				s.notifyChange(diff)
+			}
+		}
+	}()
+}
+
+func readDockerSecrets(dir string) ([]Item, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, f := range files {
+		filename := filepath.Join(dir, f.Name())
+		if strings.HasPrefix(f.Name(), ".") || isDir(filename, f) {
+			continue
+		}
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, NewSecretItem(f.Name(), string(content), 5))
+	}
+	return items, nil
+}
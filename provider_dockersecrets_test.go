@@ -0,0 +1,63 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerSecretsProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cr3t"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0700))
+
+	s := NewStore()
+	DockerSecretsProvider(s, dir)
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 1, l.Len())
+	it, err := l.GetItem("db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", mustValue(it))
+	assert.True(t, it.IsSensitive())
+	assert.NotContains(t, it.String(), "s3cr3t")
+	assert.Equal(t, "dockersecrets:"+dir, it.Source())
+
+	DockerSecretsProvider(s, filepath.Join(dir, "missing"))
+	_, err = s.GetItemList()
+	assert.NoError(t, err, "a missing directory must not be an error")
+}
+
+func TestDockerSecretsRefreshProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("v1"), 0600))
+
+	s := NewStore()
+	defer s.Close()
+	DockerSecretsRefreshProvider(s, dir, 10*time.Millisecond)
+	w := s.WatchDiff()
+
+	rewriteFile(t, filepath.Join(dir, "token"), "v2")
+	select {
+	case diff := <-w:
+		require.Len(t, diff.Modified, 1)
+		assert.Equal(t, "token", diff.Modified[0].Key)
+	case <-time.After(2 * time.Second):
+		t.Fatal("rotated secret not picked up")
+	}
+	v, err := s.GetItemValue("token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", v)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "token")))
+	require.Eventually(t, func() bool {
+		_, err := s.GetItemValue("token")
+		return err != nil
+	}, 2*time.Second, 10*time.Millisecond, "removed secret still served")
+}
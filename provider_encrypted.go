@@ -0,0 +1,89 @@
+package configstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func fileEncryptedProvider(s *Store, filename string, key []byte) {
+	decode := s.fileDecoder(buildProviderName("file", false, filename))
+	file(s.ctx, s, filename, false, func(b []byte) ([]Item, error) {
+		plain, err := DecryptFile(b, key)
+		if err != nil {
+			return nil, err
+		}
+		return decode(plain)
+	})
+}
+
+// EncryptConfig encrypts a YAML configuration with AES-GCM, producing the content of a file readable by FileEncrypted.
+// The key must be 16, 24 or 32 bytes long, to select AES-128, AES-192 or AES-256.
+// A random nonce is generated and prepended to the ciphertext.
+func EncryptConfig(plaintext, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("configstore: encrypt: %v", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// EncryptFile is the same as EncryptConfig, see DecryptFile for the reverse operation.
+func EncryptFile(plaintext, key []byte) ([]byte, error) {
+	return EncryptConfig(plaintext, key)
+}
+
+// DecryptFile decrypts the content of a file produced by EncryptFile (or EncryptConfig): the nonce prefix is followed
+// by the AES-GCM ciphertext. It fails if the key is wrong or if the data was corrupted or tampered with.
+func DecryptFile(ciphertext, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("configstore: decrypt: ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("configstore: decrypt: wrong key or corrupted data")
+	}
+	return plain, nil
+}
+
+// EncryptionKeyFromEnv reads an encryption key, for FileEncrypted, EncryptFile and DecryptFile, from the given environment
+// variable, holding it base64 encoded (e.g. from openssl rand -base64 32), so that the key does not have to be stored
+// along with the encrypted files.
+func EncryptionKeyFromEnv(name string) ([]byte, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return nil, fmt.Errorf("configstore: encryption key: environment variable %s is not set", name)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+	if err != nil {
+		return nil, fmt.Errorf("configstore: encryption key: environment variable %s: invalid base64: %v", name, err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	}
+	return nil, fmt.Errorf("configstore: encryption key: environment variable %s: got %d bytes, expected 16, 24 or 32", name, len(key))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("configstore: invalid encryption key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,86 @@
+package configstore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileEncrypted(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	ciphertext, err := EncryptConfig([]byte("- key: password\n  value: s3cr3t\n  priority: 1\n"), key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(ciphertext), "s3cr3t")
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.enc")
+	require.NoError(t, os.WriteFile(filename, ciphertext, 0600))
+
+	s := NewStore()
+	s.FileEncrypted(filename, key)
+	v, err := s.GetItemValue("password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", v)
+
+	truncated := filepath.Join(dir, "truncated.enc")
+	require.NoError(t, os.WriteFile(truncated, ciphertext[:10], 0600))
+	tampered := filepath.Join(dir, "tampered.enc")
+	corrupted := append([]byte(nil), ciphertext...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(tampered, corrupted, 0600))
+
+	for _, tc := range []struct {
+		filename string
+		key      []byte
+		err      string
+	}{
+		{filename, bytes.Repeat([]byte{0x43}, 32), "wrong key or corrupted data"},
+		{tampered, key, "wrong key or corrupted data"},
+		{truncated, key, "ciphertext too short"},
+		{filename, []byte("short"), "invalid encryption key"},
+	} {
+		s := NewStore()
+		s.FileEncrypted(tc.filename, tc.key)
+		_, err := s.GetItemList()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tc.err)
+		var parseErr *ProviderParseError
+		assert.True(t, errors.As(err, &parseErr))
+	}
+}
+
+func TestEncryptFile(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	t.Setenv("CONFIGSTORE_TEST_KEY", base64.StdEncoding.EncodeToString(key))
+	envKey, err := EncryptionKeyFromEnv("CONFIGSTORE_TEST_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, key, envKey)
+
+	plaintext := []byte("- key: password\n  value: s3cr3t\n")
+	ciphertext, err := EncryptFile(plaintext, envKey)
+	require.NoError(t, err)
+	plain, err := DecryptFile(ciphertext, key)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, plain)
+
+	_, err = DecryptFile(ciphertext, bytes.Repeat([]byte{0x43}, 32))
+	assert.Error(t, err)
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = DecryptFile(ciphertext, key)
+	assert.Error(t, err)
+
+	t.Setenv("CONFIGSTORE_TEST_KEY", base64.StdEncoding.EncodeToString(key[:10]))
+	_, err = EncryptionKeyFromEnv("CONFIGSTORE_TEST_KEY")
+	assert.Error(t, err)
+	t.Setenv("CONFIGSTORE_TEST_KEY", "not base64!")
+	_, err = EncryptionKeyFromEnv("CONFIGSTORE_TEST_KEY")
+	assert.Error(t, err)
+	_, err = EncryptionKeyFromEnv("CONFIGSTORE_TEST_MISSING_KEY")
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package configstore
+
+// FallbackProvider returns a provider which layers the given providers on top of each other.
+// For each key, the items returned are those of the first provider (in argument order) that contains that key,
+// the subsequent providers are only used as a fallback for the keys that the previous ones do not know about.
+// Unlike the regular priority-based merge, no provider "loses" globally: each is authoritative for the keys it provides.
+// An error from any of the providers is returned as is.
+func FallbackProvider(providers ...Provider) Provider {
+	return func() (ItemList, error) {
+		ret := ItemList{}
+		seen := map[string]bool{}
+		for _, p := range providers {
+			if p == nil {
+				continue
+			}
+			l, err := p()
+			if err != nil {
+				return ItemList{}, err
+			}
+			provided := map[string]bool{}
+			for _, it := range l.Items {
+				if seen[it.key] {
+					continue
+				}
+				provided[it.key] = true
+				ret.Items = append(ret.Items, it)
+			}
+			for k := range provided {
+				seen[k] = true
+			}
+		}
+		return ret, nil
+	}
+}
@@ -0,0 +1,60 @@
+package configstore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticProvider(items ...Item) Provider {
+	return func() (ItemList, error) {
+		return ItemList{Items: items}, nil
+	}
+}
+
+func TestFallbackProvider(t *testing.T) {
+	a := staticProvider(NewItem("x", "a-x", 1))
+	b := staticProvider(NewItem("x", "b-x", 10), NewItem("y", "b-y", 1))
+	c := staticProvider(NewItem("x", "c-x", 20), NewItem("y", "c-y", 20), NewItem("z", "c-z", 1))
+
+	s := NewStore()
+	s.RegisterProvider("layered", FallbackProvider(a, b, c))
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Len(t, l.Items, 3)
+
+	v, err := l.GetItemValue("x")
+	require.NoError(t, err)
+	assert.Equal(t, "a-x", v)
+
+	v, err = l.GetItemValue("y")
+	require.NoError(t, err)
+	assert.Equal(t, "b-y", v)
+
+	v, err = l.GetItemValue("z")
+	require.NoError(t, err)
+	assert.Equal(t, "c-z", v)
+}
+
+func TestFallbackProviderMultipleItems(t *testing.T) {
+	a := staticProvider(NewItem("x", "a-x1", 1), NewItem("x", "a-x2", 2))
+	b := staticProvider(NewItem("x", "b-x", 10))
+
+	l, err := FallbackProvider(a, b)()
+	require.NoError(t, err)
+	assert.Len(t, l.Items, 2)
+	for _, i := range l.Items {
+		assert.NotEqual(t, "b-x", i.value)
+	}
+}
+
+func TestFallbackProviderError(t *testing.T) {
+	a := staticProvider(NewItem("x", "a-x", 1))
+	b := newErrorProvider(errors.New("unavailable"))
+
+	_, err := FallbackProvider(a, b)()
+	assert.EqualError(t, err, "unavailable")
+}
@@ -0,0 +1,109 @@
+package configstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FileListRefreshProvider is similar to the FileList provider, scanning the directory again at the given interval
+// rather than watching it, e.g. for network file systems which do not report changes: the files created since the
+// last scan are loaded, the items of the removed files are dropped, and the modified files are read again.
+// Watchers get notified when the set of files or their items change. As FileList, it skips the hidden files.
+// Prefer FileListRefresh, which is notified of the changes right away, on local file systems.
+func FileListRefreshProvider(s *Store, dirname string, interval time.Duration) {
+	if dirname == "" {
+		return
+	}
+	providername := buildProviderName("filelist", true, dirname)
+
+	files := map[string]*InMemoryProvider{}
+	scan := func() (changed bool, err error) {
+		found, err := listFiles(dirname)
+		if err != nil {
+			return false, err
+		}
+		for filename := range files {
+			if !found[filename] {
+				s.UnregisterProvider(buildProviderName("file", true, filename))
+				delete(files, filename)
+				changed = true
+			}
+		}
+		for filename := range found {
+			name := buildProviderName("file", true, filename)
+			vals, err := readFile(s, name, filename, nil)
+			if err != nil {
+				s.logError(err, "provider", name, "filename", filename)
+				s.observeError(name, err)
+				continue
+			}
+			inmem, ok := files[filename]
+			if !ok {
+				inmem = inMemoryProvider(s, name)
+				files[filename] = inmem
+				s.logInfo("configuration from file", "provider", name, "filename", filename, "key_count", len(vals))
+			} else if old, _ := inmem.Items(); reflect.DeepEqual(old.Items, vals) {
+				continue
+			}
+			inmem.Replace(vals...)
+			s.observeReload(name, len(vals))
+			changed = true
+		}
+		return changed, nil
+	}
+
+	if _, err := scan(); err != nil {
+		errorProvider(s, providername, err)
+		return
+	}
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-t.C:
+			}
+			changed, err := scan()
+			if err != nil {
+				s.logError(err, "provider", providername)
+				s.observeError(providername, err)
+				continue
+			}
+			if changed && s.revalidate() {
+				s.NotifyWatchers()
+			}
+		}
+	}()
+}
+
+// Returns the files of the directory, following the symbolic links to files.
+// The hidden files are skipped, e.g. the temporary files of the editors, see FileList.
+func listFiles(dirname string) (map[string]bool, error) {
+	entries, err := ioutil.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]bool{}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		filename := filepath.Join(dirname, e.Name())
+		if e.Mode()&os.ModeSymlink != 0 {
+			if e, err = os.Stat(filename); err != nil {
+				continue
+			}
+		}
+		if !e.IsDir() {
+			found[filename] = true
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,230 @@
+package configstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileListRefreshDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("- key: a\n  value: \"1\"\n"), 0600))
+
+	s := NewStore()
+	defer s.Close()
+	s.FileListRefresh(dir)
+
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+
+	// a file dropped in later is loaded
+	tmp := filepath.Join(t.TempDir(), "b.yaml")
+	require.NoError(t, os.WriteFile(tmp, []byte("- key: b\n  value: \"2\"\n"), 0600))
+	require.NoError(t, os.Rename(tmp, filepath.Join(dir, "b.yaml")))
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("b")
+		return v == "2"
+	}, 5*time.Second, 10*time.Millisecond, "new file not loaded")
+
+	// a file renamed over an existing one replaces it
+	require.NoError(t, os.WriteFile(tmp, []byte("- key: b\n  value: \"3\"\n"), 0600))
+	require.NoError(t, os.Rename(tmp, filepath.Join(dir, "b.yaml")))
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("b")
+		return v == "3"
+	}, 5*time.Second, 10*time.Millisecond, "replaced file not reloaded")
+
+	// a removed file's items are dropped
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.yaml")))
+	require.Eventually(t, func() bool {
+		_, err := s.GetItemValue("a")
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond, "removed file still served")
+
+	_, err = s.GetItemList()
+	assert.NoError(t, err, "no provider conflict expected")
+}
+
+func TestFileListRefreshStopsRemovedFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore()
+	defer s.Close()
+	s.FileListRefresh(dir)
+	base := runtime.NumGoroutine()
+
+	filename := filepath.Join(dir, "a.yaml")
+	for i := 0; i < 20; i++ {
+		require.NoError(t, os.WriteFile(filename, []byte("- key: a\n  value: \"1\"\n"), 0600))
+		require.Eventually(t, func() bool {
+			_, err := s.GetItemValue("a")
+			return err == nil
+		}, 5*time.Second, time.Millisecond, "new file not loaded")
+		require.NoError(t, os.Remove(filename))
+		require.Eventually(t, func() bool {
+			_, err := s.GetItemValue("a")
+			return err != nil
+		}, 5*time.Second, time.Millisecond, "removed file still served")
+	}
+
+	// the refresh of the removed files is stopped
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= base+2
+	}, 5*time.Second, 10*time.Millisecond, "goroutines leaked: %d, %d before", runtime.NumGoroutine(), base)
+}
+
+func TestFileListRefreshAtomicSave(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: a\n  value: \"0\"\n"), 0600))
+
+	s := NewStore()
+	defer s.Close()
+	s.FileListRefresh(dir)
+
+	done := make(chan struct{})
+	failures := make(chan error, 1)
+	go func() {
+		defer close(failures)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := s.GetItemValue("a"); err != nil {
+				failures <- err
+				return
+			}
+		}
+	}()
+
+	// the way editors and deployment tools save a file: a hidden temporary file renamed over it
+	tmp := filepath.Join(dir, ".a.yaml.tmp")
+	for i := 1; i <= 20; i++ {
+		require.NoError(t, os.WriteFile(tmp, []byte(fmt.Sprintf("- key: a\n  value: \"%d\"\n", i)), 0600))
+		require.NoError(t, os.Rename(tmp, filename))
+		time.Sleep(5 * time.Millisecond)
+	}
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("a")
+		return v == "20"
+	}, 5*time.Second, 10*time.Millisecond, "saved file not reloaded")
+	close(done)
+	assert.NoError(t, <-failures)
+}
+
+func TestFileListSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..2024_01_01")
+	require.NoError(t, os.Mkdir(data, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(data, "a.yaml"), []byte("- key: a\n  value: \"1\"\n"), 0600))
+	require.NoError(t, os.Symlink(data, filepath.Join(dir, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "..data", "a.yaml"), filepath.Join(dir, "a.yaml")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "broken.yaml")))
+
+	shared := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "b.yaml"), []byte("- key: b\n  value: \"2\"\n"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(shared, "nested"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "nested", "c.yaml"), []byte("- key: c\n  value: \"3\"\n"), 0600))
+	require.NoError(t, os.Symlink(shared, filepath.Join(dir, "shared")))
+
+	// a dangling link does not prevent the other files from loading
+	s := NewStore()
+	s.FileList(dir)
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+	_, err = s.GetItemValue("b")
+	assert.Error(t, err, "directory links are not followed by default")
+
+	s = NewStore()
+	s.FileList(dir, WithDirectorySymlinks())
+	v, err = s.GetItemValue("a")
+	require.NoError(t, err, "the files behind ..data must not be loaded twice")
+	assert.Equal(t, "1", v)
+	v, err = s.GetItemValue("b")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v)
+	_, err = s.GetItemValue("c")
+	assert.Error(t, err, "links are followed one level deep")
+}
+
+func TestFileListWithFilter(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("- key: a\n  value: \"1\"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[{"key": "b", "value": "2"}]`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml.bak"), []byte("- key: a\n  value: \"0\"\n"), 0600))
+	yamlOnly := func(fi os.FileInfo) bool { return strings.HasSuffix(fi.Name(), ".yaml") }
+
+	s := NewStore()
+	FileListWithFilter(s, dir, yamlOnly)
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, l.Keys())
+	v, err := l.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+
+	s = NewStore()
+	defer s.Close()
+	FileListRefreshWithFilter(s, dir, yamlOnly)
+	for name, content := range map[string]string{"c.yaml": "- key: c\n  value: \"3\"\n", "d.json": `[{"key": "d", "value": "4"}]`} {
+		tmp := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(tmp, []byte(content), 0600))
+		require.NoError(t, os.Rename(tmp, filepath.Join(dir, name)))
+	}
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("c")
+		return v == "3"
+	}, 5*time.Second, 10*time.Millisecond, "new file not loaded")
+	_, err = s.GetItemValue("d")
+	assert.Error(t, err, "the filter applies to the new files")
+}
+
+func TestFileListRefreshProvider(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore()
+	defer s.Close()
+	FileListRefreshProvider(s, dir, 10*time.Millisecond)
+	ch := s.Watch()
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 0, l.Len())
+
+	tmp := filepath.Join(t.TempDir(), "a.yaml")
+	require.NoError(t, os.WriteFile(tmp, []byte("- key: a\n  value: \"1\"\n"), 0600))
+	require.NoError(t, os.Rename(tmp, filepath.Join(dir, "a.yaml")))
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("a")
+		return v == "1"
+	}, 5*time.Second, 10*time.Millisecond, "new file not loaded")
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchers not notified")
+	}
+
+	// hidden files are skipped, e.g. the swap file of an editor
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".a.yaml.swp"), []byte("- key: a\n  value: swap\n"), 0600))
+	rewriteFile(t, filepath.Join(dir, "a.yaml"), "- key: a\n  value: \"2\"\n")
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("a")
+		return v == "2"
+	}, 5*time.Second, 10*time.Millisecond, "modified file not reloaded")
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.yaml")))
+	require.Eventually(t, func() bool {
+		_, err := s.GetItemValue("a")
+		return errors.Is(err, ErrNotFound)
+	}, 5*time.Second, 10*time.Millisecond, "removed file still served")
+}
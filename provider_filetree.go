@@ -32,9 +32,7 @@ func fileTree(s *Store, dirname string, refresh bool) {
 	}
 
 	inmem := inMemoryProvider(s, providername)
-	inmem.mut.Lock()
-	inmem.items = items
-	inmem.mut.Unlock()
+	inmem.Replace(items...)
 
 	if !refresh {
 		return
@@ -67,7 +65,7 @@ func fileTree(s *Store, dirname string, refresh bool) {
 				// Add new path if it's a directory
 				if event.Op&fsnotify.Create != 0 {
 					if err := watchDirectory(watcher, event.Name); err != nil {
-						logError(err)
+						s.logError(err, "provider", providername)
 					}
 				}
 
@@ -79,11 +77,9 @@ func fileTree(s *Store, dirname string, refresh bool) {
 
 				items, err := loadItems(dirname)
 				if err != nil {
-					logError(err)
+					s.logError(err, "provider", providername)
 				} else {
-					inmem.mut.Lock()
-					inmem.items = items
-					inmem.mut.Unlock()
+					inmem.Replace(items...)
 					s.NotifyWatchers()
 				}
 
@@ -91,7 +87,7 @@ func fileTree(s *Store, dirname string, refresh bool) {
 				if !ok {
 					continue
 				}
-				logError(err)
+				s.logError(err, "provider", providername)
 			}
 		}
 	}()
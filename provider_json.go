@@ -0,0 +1,87 @@
+package configstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonFileOptions struct {
+	flatten   bool
+	separator string
+}
+
+// A JSONFileOption modifies the behavior of the FileJSON provider.
+type JSONFileOption func(*jsonFileOptions)
+
+// WithFlattening makes the FileJSON provider flatten the nested objects and arrays of the document into one item
+// per leaf value, the keys of each level being joined with the given separator, e.g. with ".":
+// {"database": {"host": "localhost", "port": 5432}, "servers": [{"host": "a"}]} gives the items database.host=localhost,
+// database.port=5432 and servers.0.host=a. Numbers and booleans are converted to strings, as written in the document,
+// and null values to empty strings.
+func WithFlattening(separator string) JSONFileOption {
+	return func(o *jsonFileOptions) {
+		o.flatten = true
+		o.separator = separator
+	}
+}
+
+// FileJSON registers a configstore provider which reads an arbitrary JSON document (static content),
+// e.g. the response of an API saved to a file, as opposed to the item list read by the File provider.
+// By default, the whole document is the value of a single item, named after the file without its extension
+// (database.json gives the key "database"); see WithFlattening to get an item per value instead.
+// The items have the priority DefaultFilePriority.
+func (s *Store) FileJSON(filename string, opts ...JSONFileOption) {
+	o := &jsonFileOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	file(s.ctx, s, filename, false, func(b []byte) ([]Item, error) {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var doc interface{}
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if !o.flatten {
+			key := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+			return []Item{NewItem(key, string(bytes.TrimSpace(b)), DefaultFilePriority)}, nil
+		}
+		var vals []Item
+		flattenJSON(&vals, "", o.separator, doc)
+		return vals, nil
+	})
+}
+
+// Appends an item for each leaf value of the decoded JSON document, in key order.
+func flattenJSON(vals *[]Item, prefix, separator string, v interface{}) {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + separator + k
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			flattenJSON(vals, join(k), separator, v[k])
+		}
+	case []interface{}:
+		for i, e := range v {
+			flattenJSON(vals, join(strconv.Itoa(i)), separator, e)
+		}
+	case nil:
+		*vals = append(*vals, NewItem(prefix, "", DefaultFilePriority))
+	default:
+		*vals = append(*vals, NewItem(prefix, fmt.Sprint(v), DefaultFilePriority))
+	}
+}
@@ -0,0 +1,59 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileJSON(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "api.json")
+	doc := `{
+  "database": {"host": "localhost", "port": 5432, "tls": {"enabled": true, "ratio": 0.5}},
+  "servers": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}],
+  "comment": null
+}`
+	require.NoError(t, os.WriteFile(filename, []byte(doc+"\n"), 0600))
+
+	s := NewStore()
+	s.FileJSON(filename)
+	v, err := s.GetItemValue("api")
+	require.NoError(t, err)
+	assert.Equal(t, doc, v, "the whole document is kept by default")
+
+	s = NewStore()
+	s.FileJSON(filename, WithFlattening("."))
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	values := map[string]string{}
+	for _, it := range l.Items {
+		values[it.Key()] = mustValue(it)
+		assert.Equal(t, DefaultFilePriority, it.Priority())
+	}
+	assert.Equal(t, map[string]string{
+		"database.host":        "localhost",
+		"database.port":        "5432",
+		"database.tls.enabled": "true",
+		"database.tls.ratio":   "0.5",
+		"servers.0.host":       "a",
+		"servers.1.host":       "b",
+		"servers.1.tags.0":     "x",
+		"servers.1.tags.1":     "y",
+		"comment":              "",
+	}, values)
+
+	s = NewStore()
+	s.FileJSON(filename, WithFlattening("/"))
+	v, err = s.GetItemValue("database/tls/enabled")
+	require.NoError(t, err)
+	assert.Equal(t, "true", v)
+
+	require.NoError(t, os.WriteFile(filename, []byte(`{"database": `), 0600))
+	s = NewStore()
+	s.FileJSON(filename)
+	_, err = s.GetItemList()
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// MultiFileProvider reads the given files in order, and registers their items as a single provider with the given name,
+// e.g. for a base configuration and its local overrides: MultiFileProvider(s, "app", "/etc/app/base.yaml", "/etc/app/local.yaml").
+// The files which do not exist are skipped silently. For the keys present in several files, the items of the last file
+// replace those of the previous ones. A file which cannot be read or decoded fails the whole provider.
+func MultiFileProvider(s *Store, name string, filenames ...string) {
+	multiFile(s, name, false, filenames)
+}
+
+// MultiFileRefreshProvider is similar to MultiFileProvider, with the refresh feature enabled: the files are read again
+// when any of them is created, modified or removed.
+func MultiFileRefreshProvider(s *Store, name string, filenames ...string) {
+	multiFile(s, name, true, filenames)
+}
+
+func multiFile(s *Store, name string, refresh bool, filenames []string) {
+	vals, err := readFiles(s, name, filenames)
+	if err != nil {
+		errorProvider(s, name, err)
+		return
+	}
+	inmem := inMemoryProvider(s, name)
+	s.logInfo("configuration from files", "provider", name, "file_count", len(filenames), "key_count", len(vals))
+	s.observeReload(name, len(vals))
+	inmem.Replace(vals...)
+
+	if !refresh {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		errorProvider(s, name, err)
+		return
+	}
+
+	configured := make(map[string]bool, len(filenames))
+	for _, filename := range filenames {
+		configured[filepath.Clean(filename)] = true
+	}
+
+	reload := func() {
+		vals, err := readFiles(s, name, filenames)
+		if err != nil {
+			s.logError(err, "provider", name)
+			s.observeError(name, err)
+			return
+		}
+		old, _ := inmem.Items()
+		s.observeReload(name, len(vals))
+		if reflect.DeepEqual(old.Items, vals) {
+			return
+		}
+		diff := Diff(old, ItemList{Items: vals})
+		inmem.Replace(vals...)
+		if s.revalidate() {
+			s.notifyChange(diff)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					continue
+				}
+				if event.Op&fsnotify.Chmod != 0 || !configured[filepath.Clean(event.Name)] {
+					continue
+				}
+				// the directories are watched rather than the files, so that the missing files are picked up
+				// once created, and the replaced files are read again; the events of the other files are ignored
+				reload()
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					continue
+				}
+				s.logError(err, "provider", name)
+				s.observeError(name, err)
+			}
+		}
+	}()
+
+	watched := map[string]bool{}
+	for _, filename := range filenames {
+		dir := filepath.Dir(filename)
+		if watched[dir] {
+			continue
+		}
+		watched[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			s.logError(err, "provider", name, "filename", filename)
+		}
+	}
+}
+
+// Reads the files in order, skipping the missing ones, the items of a file replacing the items of the previous files
+// sharing their keys.
+func readFiles(s *Store, providername string, filenames []string) ([]Item, error) {
+	var ret []Item
+	for _, filename := range filenames {
+		vals, err := readFile(s, providername, filename, nil)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		overridden := map[string]bool{}
+		for _, it := range vals {
+			overridden[it.key] = true
+		}
+		merged := make([]Item, 0, len(ret)+len(vals))
+		for _, it := range ret {
+			if !overridden[it.key] {
+				merged = append(merged, it)
+			}
+		}
+		ret = append(merged, vals...)
+	}
+	return ret, nil
+}
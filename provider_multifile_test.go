@@ -0,0 +1,96 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	local := filepath.Join(dir, "local.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("- key: a\n  value: base\n- key: b\n  value: base\n"), 0600))
+	require.NoError(t, os.WriteFile(local, []byte("- key: b\n  value: local\n- key: c\n  value: local\n"), 0600))
+
+	s := NewStore()
+	MultiFileProvider(s, "app", base, filepath.Join(dir, "missing.yaml"), local)
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Len(t, l.Items, 3)
+	for key, expected := range map[string]string{"a": "base", "b": "local", "c": "local"} {
+		v, err := l.GetItemValue(key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, v, key)
+	}
+	i, err := l.GetItem("a")
+	require.NoError(t, err)
+	assert.Equal(t, "app", i.Source())
+}
+
+func TestMultiFileProviderMissing(t *testing.T) {
+	s := NewStore()
+	MultiFileProvider(s, "app", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Empty(t, l.Items)
+}
+
+func TestMultiFileProviderMalformed(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	local := filepath.Join(dir, "local.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("- key: a\n  value: base\n"), 0600))
+	require.NoError(t, os.WriteFile(local, []byte("not: [a list"), 0600))
+
+	s := NewStore()
+	MultiFileProvider(s, "app", base, local)
+
+	_, err := s.GetItemList()
+	var perr *ProviderParseError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, local, perr.Filename)
+}
+
+func TestMultiFileRefreshProvider(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	local := filepath.Join(dir, "local.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("- key: a\n  value: base\n"), 0600))
+
+	s := NewStore()
+	defer s.Close()
+	o := &testObserver{reloads: map[string][]int{}, errors: map[string]int{}}
+	s.SetMetricsObserver(o)
+	MultiFileRefreshProvider(s, "app", base, local)
+
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "base", v)
+
+	// a missing file is loaded once created
+	require.NoError(t, os.WriteFile(local, []byte("- key: a\n  value: local\n"), 0600))
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("a")
+		return v == "local"
+	}, 5*time.Second, 10*time.Millisecond, "created file not loaded")
+
+	// the other files of the directories are ignored
+	reloads := len(o.reloadCounts("app"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("- key: a\n  value: other\n"), 0600))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, reloads, len(o.reloadCounts("app")), "reloaded on a change of another file")
+
+	require.NoError(t, os.Remove(local))
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("a")
+		return v == "base"
+	}, 5*time.Second, 10*time.Millisecond, "removed file still served")
+}
@@ -0,0 +1,46 @@
+package configstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LoadFromReader reads all the data from r, e.g. stdin or an HTTP response body, decodes it according to the format
+// ("yaml", "json", or any format with a decoder, see RegisterDecoder) and registers the resulting items as an in-memory
+// provider with the given name.
+// The data has the same layout as the one of the File provider. It is read once: there is no refresh.
+func (s *Store) LoadFromReader(name string, r io.Reader, format string) error {
+	b, err := s.ReadAllLimited(name, r)
+	if tooLarge, ok := err.(*FileTooLargeError); ok {
+		return tooLarge
+	}
+	if err != nil {
+		return fmt.Errorf("configstore: provider '%s': read failed: %v", name, err)
+	}
+	var vals []Item
+	if fn := lookupDecoder("." + format); fn != nil {
+		vals, err = fn(b)
+	} else {
+		switch strings.ToLower(format) {
+		case "yaml", "yml":
+			vals, err = s.fileDecoder(name)(b)
+		case "json":
+			vals, err = decodeItems(b, DefaultFilePriority, json.Unmarshal)
+			if err == nil {
+				vals, err = s.fileDecoder(name)(b)
+			}
+		default:
+			return fmt.Errorf("configstore: provider '%s': unsupported format '%s'", name, format)
+		}
+	}
+	if err != nil {
+		return &ProviderParseError{Name: name, Filename: "<reader>", Cause: err}
+	}
+	inmem := inMemoryProvider(s, name)
+	s.logInfo("configuration from reader", "provider", name, "format", format, "key_count", len(vals))
+	s.observeReload(name, len(vals))
+	inmem.Add(vals...)
+	return nil
+}
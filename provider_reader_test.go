@@ -0,0 +1,32 @@
+package configstore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreLoadFromReader(t *testing.T) {
+	s := NewStore()
+	require.NoError(t, s.LoadFromReader("stdin", strings.NewReader("- key: foo\n  value: bar\n  priority: 5\n"), "yaml"))
+	require.NoError(t, s.LoadFromReader("body", strings.NewReader(`[{"key": "baz", "value": "qux"}]`), "json"))
+
+	it, err := s.GetItem("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", mustValue(it))
+	assert.Equal(t, int64(5), it.Priority())
+	assert.Equal(t, "stdin", it.Source())
+	v, err := s.GetItemValue("baz")
+	require.NoError(t, err)
+	assert.Equal(t, "qux", v)
+
+	var perr *ProviderParseError
+	err = s.LoadFromReader("bad", strings.NewReader("- key: [\n"), "yaml")
+	assert.True(t, errors.As(err, &perr), "unexpected error: %v", err)
+	assert.Error(t, s.LoadFromReader("toml", strings.NewReader(""), "toml"))
+	_, err = s.GetItemList()
+	assert.NoError(t, err, "failed loads must not register a provider")
+}
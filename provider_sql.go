@@ -0,0 +1,112 @@
+package configstore
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+func sqlProvider(s *Store, db *sql.DB, query string, refresh time.Duration) {
+	providername := fmt.Sprintf("sql:%s", query)
+	if refresh > 0 {
+		providername = fmt.Sprintf("sql+refresh:%s", query)
+	}
+
+	vals, err := querySQL(db, query)
+	if err != nil {
+		errorProvider(s, providername, err)
+		return
+	}
+	inmem := inMemoryProvider(s, providername)
+	s.logInfo("configuration from sql", "provider", providername, "key_count", len(vals))
+	s.observeReload(providername, len(vals))
+	inmem.Add(vals...)
+
+	if refresh <= 0 {
+		return
+	}
+
+	go func() {
+		t := time.NewTicker(refresh)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-t.C:
+			}
+			vals, err := querySQL(db, query)
+			if err != nil {
+				s.logError(err, "provider", providername)
+				s.observeError(providername, err)
+				continue
+			}
+			old, _ := inmem.Items()
+			s.observeReload(providername, len(vals))
+			if reflect.DeepEqual(old.Items, vals) {
+				continue
+			}
+			diff := Diff(old, ItemList{Items: vals})
+			inmem.Replace(vals...)
+			if s.revalidate() {
+				s.notifyChange(diff)
+			}
+		}
+	}()
+}
+
+// Runs the query, mapping the key, value and (optional) priority columns of the rows to items.
+func querySQL(db *sql.DB, query string) ([]Item, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("configstore: sql: %v", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("configstore: sql: %v", err)
+	}
+	keyIdx, valueIdx, priorityIdx := -1, -1, -1
+	for i, c := range cols {
+		switch strings.ToLower(c) {
+		case "key":
+			keyIdx = i
+		case "value":
+			valueIdx = i
+		case "priority":
+			priorityIdx = i
+		}
+	}
+	if keyIdx < 0 || valueIdx < 0 {
+		return nil, fmt.Errorf("configstore: sql: query must return 'key' and 'value' columns, got: %s", strings.Join(cols, ", "))
+	}
+
+	vals := []Item{}
+	for rows.Next() {
+		var key, value sql.NullString
+		var priority sql.NullInt64
+		dest := make([]interface{}, len(cols))
+		for i := range dest {
+			dest[i] = new(sql.RawBytes)
+		}
+		dest[keyIdx] = &key
+		dest[valueIdx] = &value
+		if priorityIdx >= 0 {
+			dest[priorityIdx] = &priority
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("configstore: sql: row %d: %v", len(vals)+1, err)
+		}
+		if !key.Valid {
+			return nil, fmt.Errorf("configstore: sql: row %d: null key", len(vals)+1)
+		}
+		vals = append(vals, NewItem(key.String, value.String, priority.Int64))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("configstore: sql: %v", err)
+	}
+	return vals, nil
+}
@@ -0,0 +1,151 @@
+package configstore
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A minimal database/sql driver, whose queries return the content of the table named by the DSN.
+type fakeTable struct {
+	mut  sync.Mutex
+	cols []string
+	rows [][]driver.Value
+}
+
+var fakeTables sync.Map
+
+func init() {
+	sql.Register("configstore-fake", fakeDriver{})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	t, _ := fakeTables.Load(name)
+	return fakeConn{t.(*fakeTable)}, nil
+}
+
+type fakeConn struct{ t *fakeTable }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type fakeStmt struct{ t *fakeTable }
+
+func (s fakeStmt) Close() error                                    { return nil }
+func (s fakeStmt) NumInput() int                                   { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.t.mut.Lock()
+	defer s.t.mut.Unlock()
+	return &fakeRows{cols: s.t.cols, rows: append([][]driver.Value(nil), s.t.rows...)}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func openFakeDB(t *testing.T, cols []string, rows ...[]driver.Value) (*sql.DB, *fakeTable) {
+	table := &fakeTable{cols: cols, rows: rows}
+	fakeTables.Store(t.Name(), table)
+	db, err := sql.Open("configstore-fake", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, table
+}
+
+func TestSQL(t *testing.T) {
+	db, _ := openFakeDB(t, []string{"id", "KEY", "value", "priority"},
+		[]driver.Value{int64(1), "db-host", "localhost", int64(10)},
+		[]driver.Value{int64(2), "flag", nil, int64(1)},
+	)
+	s := NewStore()
+	s.SQL(db, "SELECT * FROM config")
+
+	i, err := s.GetItem("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", mustValue(i))
+	assert.Equal(t, int64(10), i.Priority())
+	i, err = s.GetItem("flag")
+	require.NoError(t, err)
+	assert.Equal(t, "", mustValue(i))
+}
+
+func TestSQLErrors(t *testing.T) {
+	db, _ := openFakeDB(t, []string{"name", "value"}, []driver.Value{"a", "b"})
+	s := NewStore()
+	s.SQL(db, "SELECT name, value FROM config")
+	_, err := s.GetItemList()
+	assert.EqualError(t, err, "configstore: provider 'sql:SELECT name, value FROM config': configstore: sql: query must return 'key' and 'value' columns, got: name, value")
+
+	db, _ = openFakeDB(t, []string{"key", "value", "priority"}, []driver.Value{"a", "b", "high"})
+	s = NewStore()
+	s.SQL(db, "SELECT key, value, priority FROM config")
+	_, err = s.GetItemList()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "configstore: sql: row 1:")
+}
+
+func TestSQLRefresh(t *testing.T) {
+	db, table := openFakeDB(t, []string{"key", "value"}, []driver.Value{"a", "1"})
+	s := NewStore()
+	defer s.Close()
+	s.SQLRefresh(db, "SELECT key, value FROM config", 10*time.Millisecond)
+	diffs := s.WatchDiff()
+
+	table.mut.Lock()
+	table.rows = [][]driver.Value{{"a", "2"}}
+	table.mut.Unlock()
+
+	select {
+	case d := <-diffs:
+		assert.Equal(t, []ItemChange{{Key: "a", OldValue: "1", NewValue: "2"}}, d.Modified)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification after the query result changed")
+	}
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v)
+}
+
+func TestSQLRefreshPriorityChange(t *testing.T) {
+	db, table := openFakeDB(t, []string{"key", "value", "priority"}, []driver.Value{"a", "sql", int64(1)})
+	s := NewStore()
+	defer s.Close()
+	s.InMemory("mem").Add(NewItem("a", "mem", 10))
+	s.SQLRefresh(db, "SELECT key, value, priority FROM config", 10*time.Millisecond)
+	ch := s.Watch()
+
+	table.mut.Lock()
+	table.rows = [][]driver.Value{{"a", "sql", int64(20)}}
+	table.mut.Unlock()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification after a priority change")
+	}
+	i, err := s.GetFirst("a")
+	require.NoError(t, err)
+	assert.Equal(t, "sql", i.value)
+}
@@ -0,0 +1,25 @@
+package configstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// UserConfigProvider registers a configstore provider reading {appName}/{filename} from the platform's user
+// configuration directory (see os.UserConfigDir): ~/.config on Linux, ~/Library/Application Support on macOS,
+// %AppData% on Windows. If the file does not exist, the provider is registered with no items.
+func UserConfigProvider(s *Store, appName, filename string) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		errorProvider(s, fmt.Sprintf("userconfig:%s/%s", appName, filename), err)
+		return
+	}
+	path := filepath.Join(dir, appName, filename)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		s.logInfo("configstore: no user configuration file found", "filename", path)
+		inMemoryProvider(s, buildProviderName("file", false, path))
+		return
+	}
+	fileProvider(s, path)
+}
@@ -0,0 +1,40 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserConfigProvider(t *testing.T) {
+	dir := t.TempDir()
+	switch runtime.GOOS {
+	case "windows":
+		t.Setenv("AppData", dir)
+	case "darwin", "ios", "plan9":
+		t.Skip("the user configuration directory cannot be overridden on this platform")
+	default:
+		t.Setenv("XDG_CONFIG_HOME", dir)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "myapp"), 0700))
+	filename := filepath.Join(dir, "myapp", "settings.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: theme\n  value: dark\n"), 0600))
+
+	s := NewStore()
+	UserConfigProvider(s, "myapp", "settings.yaml")
+	i, err := s.GetItem("theme")
+	require.NoError(t, err)
+	assert.Equal(t, "dark", mustValue(i))
+	assert.Equal(t, "file:"+filename, i.Source())
+
+	// a missing file is not an error
+	s = NewStore()
+	UserConfigProvider(s, "myapp", "missing.yaml")
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Empty(t, l.Items)
+}
@@ -0,0 +1,72 @@
+package configstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// XDGProvider registers a configstore provider reading the first {appName}/config.yaml file found in the XDG
+// configuration directories: $XDG_CONFIG_HOME (~/.config by default), then each directory of $XDG_CONFIG_DIRS
+// (/etc/xdg by default), as per the XDG Base Directory Specification. Nothing is registered if no file is found.
+func XDGProvider(s *Store, appName string) {
+	for _, filename := range xdgConfigPaths(appName) {
+		if _, err := os.Stat(filename); err == nil {
+			fileProvider(s, filename)
+			return
+		}
+	}
+	s.logInfo("configstore: no XDG configuration file found", "app", appName)
+}
+
+// XDGAllProvider is similar to XDGProvider, but reads all the files found.
+// For each key, the first file found (in search order) is authoritative: the later ones are only used as a fallback
+// for the keys it does not define, see FallbackProvider.
+func XDGAllProvider(s *Store, appName string) {
+	providername := fmt.Sprintf("xdg:%s", appName)
+	var layers []Provider
+	for _, filename := range xdgConfigPaths(appName) {
+		vals, err := readFile(s, providername, filename, nil)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			errorProvider(s, providername, err)
+			return
+		}
+		s.logInfo("configuration from file", "provider", providername, "filename", filename, "key_count", len(vals))
+		layers = append(layers, (&InMemoryProvider{items: vals}).Items)
+	}
+	if len(layers) == 0 {
+		s.logInfo("configstore: no XDG configuration file found", "app", appName)
+		return
+	}
+	s.RegisterProvider(providername, FallbackProvider(layers...))
+}
+
+// Returns the candidate configuration files, in search order.
+// Relative paths in the environment variables are ignored, as per the specification.
+func xdgConfigPaths(appName string) []string {
+	var dirs []string
+	if home := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(home) {
+		dirs = append(dirs, home)
+	} else if userHome, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(userHome, ".config"))
+	}
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, d := range strings.Split(configDirs, string(os.PathListSeparator)) {
+		if filepath.IsAbs(d) {
+			dirs = append(dirs, d)
+		}
+	}
+
+	paths := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		paths = append(paths, filepath.Join(d, appName, "config.yaml"))
+	}
+	return paths
+}
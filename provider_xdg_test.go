@@ -0,0 +1,67 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAppConfig(t *testing.T, dir, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "myapp"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "myapp", "config.yaml"), []byte(content), 0600))
+}
+
+func TestXDGConfigPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CONFIG_DIRS", "")
+	assert.Equal(t, []string{
+		filepath.Join(home, ".config", "myapp", "config.yaml"),
+		"/etc/xdg/myapp/config.yaml",
+	}, xdgConfigPaths("myapp"))
+
+	t.Setenv("XDG_CONFIG_HOME", "/home/user/conf")
+	t.Setenv("XDG_CONFIG_DIRS", "/etc/a:relative/b:/etc/c")
+	assert.Equal(t, []string{
+		"/home/user/conf/myapp/config.yaml",
+		"/etc/a/myapp/config.yaml",
+		"/etc/c/myapp/config.yaml",
+	}, xdgConfigPaths("myapp"))
+}
+
+func TestXDGProvider(t *testing.T) {
+	home, sys1, sys2 := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", sys1+string(os.PathListSeparator)+sys2)
+	writeAppConfig(t, sys1, "- key: db-host\n  value: sys1\n- key: region\n  value: sys1\n")
+	writeAppConfig(t, sys2, "- key: db-host\n  value: sys2\n- key: debug\n  value: \"false\"\n")
+
+	// the first file found wins
+	s := NewStore()
+	XDGProvider(s, "myapp")
+	assert.Equal(t, "sys1", mustValue(must(s.GetItem("db-host")).(Item)))
+	_, err := s.GetItem("debug")
+	assert.Error(t, err)
+
+	writeAppConfig(t, home, "- key: db-host\n  value: home\n")
+	s = NewStore()
+	XDGProvider(s, "myapp")
+	assert.Equal(t, "home", mustValue(must(s.GetItem("db-host")).(Item)))
+
+	// all the files, the first ones found take precedence
+	s = NewStore()
+	XDGAllProvider(s, "myapp")
+	assert.Equal(t, "home", mustValue(must(s.GetItem("db-host")).(Item)))
+	assert.Equal(t, "sys1", mustValue(must(s.GetItem("region")).(Item)))
+	assert.Equal(t, "false", mustValue(must(s.GetItem("debug")).(Item)))
+
+	s = NewStore()
+	XDGProvider(s, "otherapp")
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Empty(t, l.Items)
+}
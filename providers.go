@@ -1,34 +1,25 @@
 package configstore
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/ghodss/yaml"
 )
 
-// Logs functions can be overriden
-var LogErrorFunc = log.Printf
-var LogInfoFunc = log.Printf
-
 /*
 ** DEFAULT PROVIDERS IMPLEMENTATION
  */
 
-func logError(err error) {
-	if LogErrorFunc != nil {
-		LogErrorFunc("error: %v", err)
-	}
-}
-
 func errorProvider(s *Store, name string, err error) {
-	logError(err)
+	s.logError(err, "provider", name)
+	s.observeError(name, err)
 	s.RegisterProvider(name, newErrorProvider(err))
 }
 
@@ -39,22 +30,23 @@ func newErrorProvider(err error) Provider {
 }
 
 func fileProvider(s *Store, filename string) {
-	file(s, filename, false, nil)
+	file(s.ctx, s, filename, false, nil)
 }
 
 func fileRefreshProvider(s *Store, filename string) {
-	file(s, filename, true, nil)
+	file(s.ctx, s, filename, true, nil)
 }
 
 func fileCustomProvider(s *Store, filename string, fn func([]byte) ([]Item, error)) {
-	file(s, filename, false, fn)
+	file(s.ctx, s, filename, false, fn)
 }
 
 func fileCustomRefreshProvider(s *Store, filename string, fn func([]byte) ([]Item, error)) {
-	file(s, filename, true, fn)
+	file(s.ctx, s, filename, true, fn)
 }
 
-func file(s *Store, filename string, refresh bool, fn func([]byte) ([]Item, error)) {
+// Registers the provider of a file. In refresh mode, the file is watched until ctx is done.
+func file(ctx context.Context, s *Store, filename string, refresh bool, fn func([]byte) ([]Item, error)) {
 
 	if filename == "" {
 		return
@@ -62,15 +54,20 @@ func file(s *Store, filename string, refresh bool, fn func([]byte) ([]Item, erro
 
 	providername := buildProviderName("file", refresh, filename)
 
-	vals, err := readFile(filename, fn)
+	vals, err := readFile(s, providername, filename, fn)
 	if err != nil {
 		errorProvider(s, providername, err)
 		return
 	}
+	fileLoaded(ctx, s, providername, filename, vals, refresh, fn)
+}
+
+// Registers the provider of a file, given its items as read initially. See file.
+func fileLoaded(ctx context.Context, s *Store, providername, filename string, vals []Item, refresh bool,
+	fn func([]byte) ([]Item, error)) {
 	inmem := inMemoryProvider(s, providername)
-	if LogInfoFunc != nil {
-		LogInfoFunc("configuration from file: %s", filename)
-	}
+	s.logInfo("configuration from file", "provider", providername, "filename", filename, "key_count", len(vals))
+	s.observeReload(providername, len(vals))
 	inmem.Add(vals...)
 
 	if !refresh {
@@ -83,12 +80,33 @@ func file(s *Store, filename string, refresh bool, fn func([]byte) ([]Item, erro
 		return
 	}
 
+	current, _ := os.Stat(filename)
+	reload := func() {
+		vals, err := readFile(s, providername, filename, fn)
+		if err != nil {
+			s.logError(err, "provider", providername, "filename", filename)
+			s.observeError(providername, err)
+			return
+		}
+		old, _ := inmem.Items()
+		s.observeReload(providername, len(vals))
+		// both the file and its directory are watched, so a change can be reported twice
+		if reflect.DeepEqual(old.Items, vals) {
+			return
+		}
+		diff := Diff(old, ItemList{Items: vals})
+		inmem.Replace(vals...)
+		if s.revalidate() {
+			s.notifyChange(diff)
+		}
+	}
+
 	go func() {
 		defer watcher.Close()
 
 		for {
 			select {
-			case <-s.ctx.Done():
+			case <-ctx.Done():
 				return
 
 			case event, ok := <-watcher.Events:
@@ -96,23 +114,28 @@ func file(s *Store, filename string, refresh bool, fn func([]byte) ([]Item, erro
 					continue
 				}
 
-				if event.Op&fsnotify.Write != 0 {
-					vals, err := readFile(filename, fn)
-					if err != nil {
-						logError(err)
-					} else {
-						inmem.mut.Lock()
-						inmem.items = vals
-						inmem.mut.Unlock()
-						s.NotifyWatchers()
-					}
+				if filepath.Clean(event.Name) == filepath.Clean(filename) && event.Op&fsnotify.Write != 0 {
+					reload()
+					continue
 				}
+				// the file may have been replaced, either renamed over or through a symbolic link swap,
+				// e.g. the ..data link of a Kubernetes configmap mount: the new file has to be read, and watched
+				fi, err := os.Stat(filename)
+				if err != nil || (current != nil && sameFileVersion(current, fi)) {
+					continue
+				}
+				current = fi
+				if err := watcher.Add(filename); err != nil {
+					s.logError(err, "provider", providername, "filename", filename)
+				}
+				reload()
 
 			case err, ok := <-watcher.Errors:
 				if !ok {
 					continue
 				}
-				logError(err)
+				s.logError(err, "provider", providername, "filename", filename)
+				s.observeError(providername, err)
 			}
 		}
 	}()
@@ -120,6 +143,47 @@ func file(s *Store, filename string, refresh bool, fn func([]byte) ([]Item, erro
 	if err := watcher.Add(filename); err != nil {
 		errorProvider(s, providername, err)
 	}
+	if err := watcher.Add(filepath.Dir(filename)); err != nil {
+		s.logError(err, "provider", providername, "filename", filename)
+	}
+}
+
+type fileListOptions struct {
+	followDirSymlinks bool
+	filter            func(os.FileInfo) bool
+}
+
+// A FileListOption modifies the behavior of the FileList providers.
+type FileListOption func(*fileListOptions)
+
+// WithDirectorySymlinks makes the FileList providers follow the symbolic links to directories, one level deep:
+// the files of the linked directory are loaded, but not its sub-directories. Links whose name starts with a dot
+// are not followed, so that the files of a Kubernetes configmap mount are not loaded twice, once through their
+// top-level link and once through the ..data link. The files created later in a linked directory are not detected.
+func WithDirectorySymlinks() FileListOption {
+	return func(o *fileListOptions) {
+		o.followDirSymlinks = true
+	}
+}
+
+// WithFileFilter makes the FileList providers load only the files for which the filter returns true, e.g. to skip
+// the files by extension, name pattern, size or modification time. The filter is given the information of the file
+// itself (not of the link, for symbolic links), and is applied again to the files created later in refresh mode.
+func WithFileFilter(filter func(os.FileInfo) bool) FileListOption {
+	return func(o *fileListOptions) {
+		o.filter = filter
+	}
+}
+
+// FileListWithFilter registers a FileList provider loading only the files for which the filter returns true,
+// e.g. func(fi os.FileInfo) bool { return strings.HasSuffix(fi.Name(), ".yaml") }. See WithFileFilter.
+func FileListWithFilter(s *Store, dirname string, filter func(os.FileInfo) bool) {
+	fileList(s, dirname, false, WithFileFilter(filter))
+}
+
+// FileListRefreshWithFilter is similar to FileListWithFilter, with the refresh feature enabled.
+func FileListRefreshWithFilter(s *Store, dirname string, filter func(os.FileInfo) bool) {
+	fileList(s, dirname, true, WithFileFilter(filter))
 }
 
 func fileListProvider(s *Store, dirname string) {
@@ -130,10 +194,14 @@ func fileListRefreshProvider(s *Store, dirname string) {
 	fileList(s, dirname, true)
 }
 
-func fileList(s *Store, dirname string, refresh bool) {
+func fileList(s *Store, dirname string, refresh bool, opts ...FileListOption) {
 	if dirname == "" {
 		return
 	}
+	o := &fileListOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 
 	providername := buildProviderName("filelist", refresh, dirname)
 
@@ -143,49 +211,197 @@ func fileList(s *Store, dirname string, refresh bool) {
 		return
 	}
 
+	// the refresh of each file, stopped when the file is removed
+	cancels := map[string]context.CancelFunc{}
+	load := func(filename string, fi os.FileInfo) {
+		if o.filter != nil && !o.filter(fi) {
+			return
+		}
+		if !refresh {
+			fileProvider(s, filename)
+			return
+		}
+		ctx, cancel := context.WithCancel(s.ctx)
+		cancels[filename] = cancel
+		file(ctx, s, filename, true, nil)
+	}
+	// Loads a file created in the directory. It may be removed right away, e.g. if it is renamed, or be incomplete,
+	// so it is skipped if it cannot be read, until it is modified.
+	loadCreated := func(filename string, fi os.FileInfo) {
+		if o.filter != nil && !o.filter(fi) {
+			return
+		}
+		name := buildProviderName("file", true, filename)
+		vals, err := readFile(s, name, filename, nil)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				s.logError(fmt.Errorf("configstore: skipping '%s': %v", filename, err), "provider", providername)
+			}
+			return
+		}
+		ctx, cancel := context.WithCancel(s.ctx)
+		cancels[filename] = cancel
+		fileLoaded(ctx, s, name, filename, vals, true, nil)
+	}
+	unload := func(filename string) {
+		cancel, ok := cancels[filename]
+		if !ok {
+			return
+		}
+		cancel()
+		delete(cancels, filename)
+		s.UnregisterProvider(buildProviderName("file", true, filename))
+	}
 	for _, file := range files {
-		if file.IsDir() {
+		// hidden files, e.g. the temporary files of the editors and deployment tools, which are renamed over
+		// the target file once complete
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
 			continue
 		}
+		filename := filepath.Join(dirname, file.Name())
+		fi := file
 		if file.Mode()&os.ModeSymlink != 0 {
-			linkedFile, err := os.Stat(filepath.Join(dirname, file.Name()))
+			linkedFile, err := os.Stat(filename)
 			if err != nil {
-				errorProvider(s, providername, err)
-				return
+				// e.g. a dangling link, which must not prevent the other files from loading
+				s.logError(fmt.Errorf("configstore: skipping '%s': %v", filename, err), "provider", providername)
+				continue
 			}
 			if linkedFile.IsDir() {
+				if o.followDirSymlinks && !strings.HasPrefix(file.Name(), ".") {
+					linkedFileList(s, providername, filename, load)
+				}
 				continue
 			}
+			fi = linkedFile
+		}
+		load(filename, fi)
+	}
+
+	if !refresh {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		errorProvider(s, providername, err)
+		return
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					continue
+				}
+
+				if strings.HasPrefix(filepath.Base(event.Name), ".") {
+					continue
+				}
+				switch {
+				case event.Op&(fsnotify.Create|fsnotify.Write) != 0:
+					// a file renamed over a loaded one is followed by the refresh of the latter, see file;
+					// a file which could not be loaded when created is loaded again once written
+					if _, ok := cancels[event.Name]; ok {
+						continue
+					}
+					fi, err := os.Stat(event.Name)
+					if err != nil || fi.IsDir() {
+						continue
+					}
+					loadCreated(event.Name, fi)
+				case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+					unload(event.Name)
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					continue
+				}
+				s.logError(err, "provider", providername)
+			}
 		}
+	}()
+
+	if err := watcher.Add(dirname); err != nil {
+		errorProvider(s, providername, err)
+	}
+}
+
+// Reports whether two file infos describe the same version of the same file. The modification time and the size are
+// compared too, as the inode of a replaced file can be reused right away, e.g. by the next temporary file of an editor.
+func sameFileVersion(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
 
-		if refresh {
-			fileRefreshProvider(s, filepath.Join(dirname, file.Name()))
-		} else {
-			fileProvider(s, filepath.Join(dirname, file.Name()))
+// Loads the files of a linked directory, see WithDirectorySymlinks.
+func linkedFileList(s *Store, providername, dirname string, load func(string, os.FileInfo)) {
+	files, err := ioutil.ReadDir(dirname)
+	if err != nil {
+		s.logError(fmt.Errorf("configstore: skipping '%s': %v", dirname, err), "provider", providername)
+		return
+	}
+	for _, file := range files {
+		filename := filepath.Join(dirname, file.Name())
+		fi, err := os.Stat(filename)
+		if err != nil {
+			s.logError(fmt.Errorf("configstore: skipping '%s': %v", filename, err), "provider", providername)
+			continue
+		}
+		if !fi.IsDir() {
+			load(filename, fi)
 		}
 	}
 }
 
-func readFile(filename string, fn func([]byte) ([]Item, error)) ([]Item, error) {
-	vals := []Item{}
-	b, err := os.ReadFile(filename)
+func readFile(s *Store, providername, filename string, fn func([]byte) ([]Item, error)) ([]Item, error) {
+	b, err := s.readFileLimited(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	if fn != nil {
-		return fn(b)
+	if fn == nil {
+		fn = lookupDecoder(filename)
+	}
+	if fn == nil {
+		fn = s.fileDecoder(providername)
 	}
-	err = yaml.Unmarshal(b, &vals)
+	vals, err := fn(b)
 	if err != nil {
-		return nil, err
+		return nil, &ProviderParseError{Name: providername, Filename: filename, Cause: err}
 	}
 	return vals, nil
 }
 
+// Returns the decoding function of the item files, checking for unknown fields, see SetStrictDecoding.
+func (s *Store) fileDecoder(providername string) func([]byte) ([]Item, error) {
+	s.pMut.Lock()
+	strict := s.strictDecoding
+	s.pMut.Unlock()
+	return func(b []byte) ([]Item, error) {
+		vals, err := DecodeItems(DefaultFilePriority)(b)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkUnknownFields(b); err != nil {
+			if strict {
+				return nil, err
+			}
+			s.logError(fmt.Errorf("configstore: provider '%s': %v", providername, err), "provider", providername)
+		}
+		return vals, nil
+	}
+}
+
 func inMemoryProvider(s *Store, name string) *InMemoryProvider {
 	inmem := &InMemoryProvider{}
-	s.RegisterProvider(name, inmem.Items)
+	s.registerProvider(name, inmem.Items, inmem)
 	return inmem
 }
 
@@ -203,6 +419,35 @@ func (inmem *InMemoryProvider) Add(s ...Item) *InMemoryProvider {
 	return inmem
 }
 
+// Set replaces all the items sharing the given key with a single item.
+func (inmem *InMemoryProvider) Set(key, value string, priority int64) *InMemoryProvider {
+	it := NewItem(key, value, priority)
+	inmem.mut.Lock()
+	defer inmem.mut.Unlock()
+	items := make([]Item, 0, len(inmem.items)+1)
+	for _, i := range inmem.items {
+		if i.key != it.key {
+			items = append(items, i)
+		}
+	}
+	inmem.items = append(items, it)
+	return inmem
+}
+
+// Replace atomically replaces all the items of the in-memory list.
+func (inmem *InMemoryProvider) Replace(items ...Item) *InMemoryProvider {
+	items = append([]Item(nil), items...)
+	inmem.mut.Lock()
+	defer inmem.mut.Unlock()
+	inmem.items = items
+	return inmem
+}
+
+// AddSecret appends a sensitive item to the in-memory list, see NewSecretItem.
+func (inmem *InMemoryProvider) AddSecret(key, value string, priority int64) *InMemoryProvider {
+	return inmem.Add(NewSecretItem(key, value, priority))
+}
+
 // Items returns the in-memory item list. This is the function that gets called by configstore.
 func (inmem *InMemoryProvider) Items() (ItemList, error) {
 	inmem.mut.Lock()
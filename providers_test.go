@@ -0,0 +1,225 @@
+package configstore
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryProviderReplace(t *testing.T) {
+	inmem := &InMemoryProvider{}
+	inmem.Add(NewItem("a", "1", 1), NewItem("b", "1", 1))
+
+	items := []Item{NewItem("c", "1", 1)}
+	inmem.Replace(items...)
+	items[0] = NewItem("d", "1", 1)
+	l, err := inmem.Items()
+	require.NoError(t, err)
+	require.Len(t, l.Items, 1)
+	assert.Equal(t, "c", l.Items[0].Key(), "the given slice must not be retained")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				inmem.Add(NewItem("a", strconv.Itoa(n), 1))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				inmem.Replace(NewItem("b", strconv.Itoa(n), 1))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				l, _ := inmem.Items()
+				for _, it := range l.Items {
+					_ = it.Key()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestStoreGetItemListSingleflight(t *testing.T) {
+	const readers = 20
+	var calls int32
+	release := make(chan struct{})
+	s := NewStore()
+	s.RegisterProvider("slow", func() (ItemList, error) {
+		// block the first evaluation while the readers pile up
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-release
+		}
+		return ItemList{Items: []Item{NewItem("a", "1", 0)}}, nil
+	})
+
+	var started, wg sync.WaitGroup
+	results := make(chan *ItemList, readers)
+	for i := 0; i < readers; i++ {
+		started.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			l, err := s.GetItemList()
+			assert.NoError(t, err)
+			results <- l
+		}()
+	}
+	started.Wait()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 1 }, 2*time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	// the first evaluation, and a single one shared by the readers which arrived during it
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	seen := map[*ItemList]bool{}
+	for l := range results {
+		v, err := l.GetItemValue("a")
+		assert.NoError(t, err)
+		assert.Equal(t, "1", v)
+		assert.False(t, seen[l], "each caller must get its own list")
+		seen[l] = true
+	}
+
+	// sequential calls are not deduplicated
+	_, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestStoreRegisterProviderConcurrent(t *testing.T) {
+	const writers = 50
+	s := NewStore()
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_, err := s.GetItemList()
+				assert.NoError(t, err)
+			}
+		}()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.InMemory("inmem-"+strconv.Itoa(i)).Set("key", strconv.Itoa(i), 1)
+		}(i)
+	}
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	vals, err := s.GetItemValueList("key")
+	require.NoError(t, err)
+	assert.Len(t, vals, writers)
+}
+
+func TestStoreProviderMergeOrder(t *testing.T) {
+	s := NewStore()
+	// registered in an order unrelated to their names
+	for _, n := range []string{"m", "z", "a", "k"} {
+		s.InMemory(n).Set("key", n, 1)
+	}
+	for i := 0; i < 20; i++ {
+		vals, err := s.GetItemValueList("key")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m", "z", "a", "k"}, vals, "the items sharing a priority must be in registration order")
+	}
+
+	s.UnregisterProvider("m")
+	s.InMemory("m").Set("key", "m", 1)
+	vals, err := s.GetItemValueList("key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"z", "a", "k", "m"}, vals)
+}
+
+// Mimics the way Kubernetes updates a configmap volume: the files are written to a new timestamped directory,
+// then the ..data symbolic link is atomically swapped to it, and the previous directory is removed.
+// The top-level files are links to ..data/<file>, so they are never modified themselves.
+func writeConfigMap(t *testing.T, dir, generation string, files map[string]string) {
+	t.Helper()
+	data := filepath.Join(dir, "..2024_01_01_"+generation)
+	require.NoError(t, os.Mkdir(data, 0700))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(data, name), []byte(content), 0600))
+	}
+	previous, _ := os.Readlink(filepath.Join(dir, "..data"))
+	require.NoError(t, os.Symlink(filepath.Base(data), filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	for name := range files {
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			require.NoError(t, os.Symlink(filepath.Join("..data", name), link))
+		}
+	}
+	if previous != "" {
+		require.NoError(t, os.RemoveAll(filepath.Join(dir, previous)))
+	}
+}
+
+func TestFileRefreshConfigMapSwap(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigMap(t, dir, "1", map[string]string{"config.yaml": "- key: a\n  value: \"1\"\n"})
+
+	s := NewStore()
+	defer s.Close()
+	s.FileRefresh(filepath.Join(dir, "config.yaml"))
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+
+	for _, gen := range []string{"2", "3"} {
+		writeConfigMap(t, dir, gen, map[string]string{"config.yaml": "- key: a\n  value: \"" + gen + "\"\n"})
+		require.Eventually(t, func() bool {
+			v, _ := s.GetItemValue("a")
+			return v == gen
+		}, 5*time.Second, 10*time.Millisecond, "configmap update %s not detected", gen)
+	}
+}
+
+func TestFileTreeRefreshConfigMapSwap(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigMap(t, dir, "1", map[string]string{"a": "1"})
+
+	s := NewStore()
+	defer s.Close()
+	s.FileTreeRefresh(dir)
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+
+	for _, gen := range []string{"2", "3"} {
+		writeConfigMap(t, dir, gen, map[string]string{"a": gen})
+		require.Eventually(t, func() bool {
+			v, _ := s.GetItemValue("a")
+			return v == gen
+		}, 5*time.Second, 10*time.Millisecond, "configmap update %s not detected", gen)
+	}
+}
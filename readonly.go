@@ -0,0 +1,53 @@
+package configstore
+
+// ReadOnlyStore is a read-only view of a store, see Store.ReadOnly.
+// It is meant to be handed to library code, which cannot register providers nor notify watchers through it.
+type ReadOnlyStore interface {
+	Getter
+	GetAll() (*ItemList, error)
+	Keys() ([]string, error)
+}
+
+// ReadOnly returns a read-only view of the store.
+func (s *Store) ReadOnly() ReadOnlyStore {
+	return readOnlyStore{s: s}
+}
+
+// Keys returns the sorted list of the keys present in the store, each key appearing once, see ItemList.Keys.
+// This is the list of keys of the squashed configuration, e.g. to check that none was forgotten.
+func (s *Store) Keys() ([]string, error) {
+	l, err := s.GetItemList()
+	if err != nil {
+		return nil, err
+	}
+	return l.Keys(), nil
+}
+
+// A thin wrapper rather than the store itself, so that it cannot be converted back to a *Store.
+type readOnlyStore struct {
+	s *Store
+}
+
+func (r readOnlyStore) Get(key string) (Item, error) {
+	return r.s.Get(key)
+}
+
+func (r readOnlyStore) GetFirst(key string) (Item, error) {
+	return r.s.GetFirst(key)
+}
+
+func (r readOnlyStore) Filter() *ItemFilter {
+	return r.s.Filter()
+}
+
+func (r readOnlyStore) Unmarshal(key string, v interface{}) error {
+	return r.s.Unmarshal(key, v)
+}
+
+func (r readOnlyStore) GetAll() (*ItemList, error) {
+	return r.s.GetItemList()
+}
+
+func (r readOnlyStore) Keys() ([]string, error) {
+	return r.s.Keys()
+}
@@ -0,0 +1,56 @@
+package configstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreReadOnly(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Add(
+		NewItem("foo", "bar", 1),
+		NewItem("list", "a", 2),
+		NewItem("list", "b", 1),
+		NewItem("struct", `{"name": "baz"}`, 1),
+	)
+	r := s.ReadOnly()
+
+	_, ok := r.(interface{ RegisterProvider(string, Provider) })
+	assert.False(t, ok)
+	_, ok = r.(interface{ NotifyWatchers() })
+	assert.False(t, ok)
+
+	i, err := r.Get("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", mustValue(i))
+
+	i, err = r.GetFirst("list")
+	require.NoError(t, err)
+	assert.Equal(t, "a", mustValue(i))
+
+	v, err := r.Filter().Slice("list").GetItemValueList("list")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, v)
+
+	var st struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, r.Unmarshal("struct", &st))
+	assert.Equal(t, "baz", st.Name)
+
+	l, err := r.GetAll()
+	require.NoError(t, err)
+	assert.Len(t, l.Items, 4)
+
+	keys, err := r.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "list", "struct"}, keys)
+
+	// the view reflects later changes of the store
+	s.InMemory("other").Add(NewItem("new", "value", 1))
+	keys, err = r.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "list", "new", "struct"}, keys)
+}
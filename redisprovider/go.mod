@@ -0,0 +1,26 @@
+module github.com/ovh/configstore/redisprovider
+
+go 1.19
+
+replace github.com/ovh/configstore => ../
+
+require (
+	github.com/alicebob/miniredis/v2 v2.30.5
+	github.com/ovh/configstore v0.0.0-00010101000000-000000000000
+	github.com/redis/go-redis/v9 v9.0.5
+	github.com/stretchr/testify v1.8.1
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/fsnotify/fsnotify v1.5.1 // indirect
+	github.com/ghodss/yaml v1.0.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/yuin/gopher-lua v1.1.0 // indirect
+	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
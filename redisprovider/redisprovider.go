@@ -0,0 +1,315 @@
+// Package redisprovider reads configstore items from the keys of a Redis server.
+//
+//	p := redisprovider.Redis(configstore.DefaultStore, "localhost:6379", "myapp:config:*",
+//		redisprovider.WithKeyspaceNotifications())
+//	defer p.Close()
+//
+// The keys matching the pattern become items, named after the key without the literal prefix of the pattern
+// (e.g. "myapp:config:db-host" becomes "db-host"), with the value returned by GET.
+package redisprovider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ovh/configstore"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultReconnectInterval is the default delay between two attempts to reload the keys after a failure.
+const DefaultReconnectInterval = 10 * time.Second
+
+// Option modifies the behavior of the provider.
+type Option func(*Provider)
+
+// WithPriority sets the priority of the items, 0 by default.
+func WithPriority(priority int64) Option {
+	return func(p *Provider) {
+		p.priority = priority
+	}
+}
+
+// WithKeyspaceNotifications makes the provider subscribe to the keyspace notifications of the server,
+// so that the keys matching the pattern are reloaded as soon as they are set or deleted.
+// The server must have notifications enabled, e.g. with "CONFIG SET notify-keyspace-events Eg$x".
+func WithKeyspaceNotifications() Option {
+	return func(p *Provider) {
+		p.notifications = true
+	}
+}
+
+// WithReconnectInterval sets the delay between two attempts to reload the keys after a failure.
+func WithReconnectInterval(d time.Duration) Option {
+	return func(p *Provider) {
+		p.reconnectInterval = d
+	}
+}
+
+// Provider is a configstore provider serving the keys of a Redis server.
+// When the server cannot be reached, the last known items keep being served while reconnection is attempted periodically.
+type Provider struct {
+	store             *configstore.Store
+	client            *redis.Client
+	pattern           string
+	prefix            string
+	priority          int64
+	notifications     bool
+	reconnectInterval time.Duration
+
+	items map[string]string
+	err   error
+	mut   sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Redis registers on the store a provider serving the keys of the Redis server at addr, matching the glob pattern.
+func Redis(s *configstore.Store, addr, pattern string, opts ...Option) *Provider {
+	return RedisClient(s, redis.NewClient(&redis.Options{Addr: addr}), pattern, opts...)
+}
+
+// RedisClient is similar to Redis, with a preconfigured client, e.g. to control pooling and authentication.
+func RedisClient(s *configstore.Store, client *redis.Client, pattern string, opts ...Option) *Provider {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{
+		store:             s,
+		client:            client,
+		pattern:           pattern,
+		prefix:            literalPrefix(pattern),
+		reconnectInterval: DefaultReconnectInterval,
+		ctx:               ctx,
+		cancel:            cancel,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+
+	p.reload()
+	s.RegisterProvider(p.Name(), p.Items)
+	go p.reconnectLoop()
+	if p.notifications {
+		go p.watch()
+	}
+	return p
+}
+
+// Name returns the name under which the provider is registered.
+func (p *Provider) Name() string {
+	return fmt.Sprintf("redis:%s/%s", p.client.Options().Addr, p.pattern)
+}
+
+// Items returns the items loaded from the server. If it was never reached, it returns the connection error.
+func (p *Provider) Items() (configstore.ItemList, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.items == nil {
+		return configstore.ItemList{}, p.err
+	}
+	ret := configstore.ItemList{Items: make([]configstore.Item, 0, len(p.items))}
+	for k, v := range p.items {
+		ret.Items = append(ret.Items, configstore.NewItem(k, v, p.priority))
+	}
+	return ret, nil
+}
+
+// Close stops watching the server. The client is not closed.
+func (p *Provider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// Loads all the keys matching the pattern. On failure, the last known items are kept.
+func (p *Provider) reload() {
+	items, err := p.load()
+	p.mut.Lock()
+	changed := err == nil && !equal(p.items, items)
+	p.err = err
+	if err == nil {
+		p.items = items
+	}
+	p.mut.Unlock()
+
+	if err != nil {
+		logError(err)
+		return
+	}
+	if changed {
+		p.store.NotifyWatchers()
+	}
+}
+
+func (p *Provider) load() (map[string]string, error) {
+	var keys []string
+	iter := p.client.Scan(p.ctx, 0, p.pattern, 0).Iterator()
+	for iter.Next(p.ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, p.error(err)
+	}
+
+	items := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return items, nil
+	}
+	vals, err := p.client.MGet(p.ctx, keys...).Result()
+	if err != nil {
+		return nil, p.error(err)
+	}
+	for i, v := range vals {
+		// the key may have been deleted, or hold a non-string value
+		if s, ok := v.(string); ok {
+			items[strings.TrimPrefix(keys[i], p.prefix)] = s
+		}
+	}
+	return items, nil
+}
+
+// Reloads a single key, after a keyspace notification.
+func (p *Provider) reloadKey(key string) {
+	if !matchPattern(p.pattern, key) {
+		return
+	}
+	v, err := p.client.Get(p.ctx, key).Result()
+	if err != nil && err != redis.Nil {
+		p.mut.Lock()
+		p.err = p.error(err)
+		p.mut.Unlock()
+		logError(p.err)
+		return
+	}
+
+	name := strings.TrimPrefix(key, p.prefix)
+	p.mut.Lock()
+	if p.items == nil {
+		p.items = map[string]string{}
+	}
+	old, existed := p.items[name]
+	if err == redis.Nil {
+		delete(p.items, name)
+	} else {
+		p.items[name] = v
+	}
+	changed := existed != (err != redis.Nil) || old != v
+	p.mut.Unlock()
+
+	if changed {
+		p.store.NotifyWatchers()
+	}
+}
+
+// Periodically reloads all the keys while the server cannot be reached.
+func (p *Provider) reconnectLoop() {
+	t := time.NewTicker(p.reconnectInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-t.C:
+			p.mut.Lock()
+			failed := p.err != nil
+			p.mut.Unlock()
+			if failed {
+				p.reload()
+			}
+		}
+	}
+}
+
+// Subscribes to the keyspace notifications, reloading the keys which changed.
+func (p *Provider) watch() {
+	db := p.client.Options().DB
+	sub := p.client.PSubscribe(p.ctx,
+		fmt.Sprintf("__keyevent@%d__:set", db),
+		fmt.Sprintf("__keyevent@%d__:del", db),
+		fmt.Sprintf("__keyevent@%d__:expired", db),
+	)
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			p.reloadKey(msg.Payload)
+		}
+	}
+}
+
+func (p *Provider) error(err error) error {
+	return &configstore.ProviderNetworkError{Name: p.Name(), URL: "redis://" + p.client.Options().Addr, Cause: err}
+}
+
+// Returns the part of a glob pattern before its first special character.
+func literalPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+func equal(a, b map[string]string) bool {
+	if len(a) != len(b) || (a == nil) != (b == nil) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Reports whether the key matches a Redis glob pattern (*, ?, [...] and \ escapes).
+func matchPattern(pattern, key string) bool {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			re.WriteString("(?s:.*)")
+		case '?':
+			re.WriteString("(?s:.)")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				re.WriteString(regexp.QuoteMeta(pattern[i:]))
+				i = len(pattern)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^" + regexp.QuoteMeta(class[1:])
+			} else {
+				class = regexp.QuoteMeta(class)
+			}
+			re.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	ok, _ := regexp.MatchString(re.String(), key)
+	return ok
+}
+
+func logError(err error) {
+	if configstore.LogErrorFunc != nil {
+		configstore.LogErrorFunc("error: %v", err)
+	}
+}
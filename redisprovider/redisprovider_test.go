@@ -0,0 +1,108 @@
+package redisprovider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ovh/configstore"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedis(t *testing.T) {
+	srv := miniredis.RunT(t)
+	srv.Set("app:config:db-host", "localhost")
+	srv.Set("app:config:db-port", "5432")
+	srv.Set("other:key", "ignored")
+
+	s := configstore.NewStore()
+	p := Redis(s, srv.Addr(), "app:config:*", WithPriority(5), WithReconnectInterval(10*time.Millisecond))
+	defer p.Close()
+
+	i, err := s.GetItem("db-host")
+	require.NoError(t, err)
+	v, err := i.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+	assert.Equal(t, int64(5), i.Priority())
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Len(t, l.Items, 2)
+
+	// targeted reload of a single key, as done on keyspace notifications
+	ch := s.Watch()
+	srv.Set("app:config:db-port", "6543")
+	p.reloadKey("app:config:db-port")
+	<-ch
+	v, err = s.GetItemValue("db-port")
+	require.NoError(t, err)
+	assert.Equal(t, "6543", v)
+	srv.Del("app:config:db-port")
+	p.reloadKey("app:config:db-port")
+	_, err = s.GetItem("db-port")
+	assert.Error(t, err)
+
+	// the last known items are served while the server is down
+	srv.Close()
+	p.reload()
+	v, err = s.GetItemValue("db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+
+	// and reloaded once it is back
+	require.NoError(t, srv.Restart())
+	srv.Set("app:config:db-host", "db.example.com")
+	require.Eventually(t, func() bool {
+		v, err := s.GetItemValue("db-host")
+		return err == nil && v == "db.example.com"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRedisClientUnreachable(t *testing.T) {
+	s := configstore.NewStore()
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	p := RedisClient(s, client, "app:*", WithReconnectInterval(time.Hour))
+	defer p.Close()
+
+	_, err := s.GetItemList()
+	var netErr *configstore.ProviderNetworkError
+	assert.ErrorAs(t, err, &netErr)
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, key string
+		match        bool
+	}{
+		{"app:*", "app:config:db", true},
+		{"app:*", "other:db", false},
+		{"app:?b", "app:db", true},
+		{"app:[dc]b", "app:cb", true},
+		{"app:[^d]b", "app:db", false},
+		{`app:\*`, "app:*", true},
+		{`app:\*`, "app:x", false},
+		{"a.b", "axb", false},
+	} {
+		assert.Equal(t, tc.match, matchPattern(tc.pattern, tc.key), "%s %s", tc.pattern, tc.key)
+	}
+	assert.Equal(t, "app:config:", literalPrefix("app:config:*"))
+}
+
+func TestRedisKeyspaceNotifications(t *testing.T) {
+	srv := miniredis.RunT(t)
+	srv.Set("app:db-host", "localhost")
+
+	s := configstore.NewStore()
+	p := Redis(s, srv.Addr(), "app:*", WithKeyspaceNotifications())
+	defer p.Close()
+
+	// miniredis does not emit keyspace notifications, publish them by hand
+	srv.Set("app:db-host", "db.example.com")
+	require.Eventually(t, func() bool {
+		srv.Publish("__keyevent@0__:set", "app:db-host")
+		v, err := s.GetItemValue("db-host")
+		return err == nil && v == "db.example.com"
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,70 @@
+package configstore
+
+import (
+	"strings"
+)
+
+// NonEmptyValue is the default rule used by Store.Require to decide whether an item is present:
+// its value must be a non-empty string, once surrounding whitespace is trimmed.
+// An item whose value cannot be read (e.g. a failed decryption) is not present.
+func NonEmptyValue(it Item) bool {
+	v, err := it.Value()
+	return err == nil && strings.TrimSpace(v) != ""
+}
+
+// SetRequirePresence overrides the rule used by Require to decide whether an item is present, which defaults to NonEmptyValue.
+// e.g. func(Item) bool { return true } accepts any item, even an empty one.
+func (s *Store) SetRequirePresence(present func(Item) bool) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.requirePresence = present
+}
+
+// Require checks that every given key is present in the merged configuration, that is, that at least one item
+// with that key satisfies the presence rule (see NonEmptyValue and SetRequirePresence).
+// It returns a *MissingKeysError listing all the missing keys at once, so that a service can fail fast at startup
+// with a single message, e.g. "missing required config: db_host, api_key". It can also be called from a validator,
+// see RequireValidator to also reject a refreshed configuration which lost a required key.
+func (s *Store) Require(keys ...string) error {
+	l, err := s.GetItemList()
+	if err != nil {
+		return err
+	}
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	return s.requireKeys(l, keys)
+}
+
+// RequireValidator returns a validator performing the same check as Require, to be passed to AddValidator:
+// the configuration is then rejected on every load, including refreshes, while a required key is missing.
+func (s *Store) RequireValidator(keys ...string) func(ItemList) error {
+	return func(l ItemList) error {
+		// validators run with s.pMut held
+		return s.requireKeys(&l, keys)
+	}
+}
+
+// Must be called with s.pMut held.
+func (s *Store) requireKeys(l *ItemList, keys []string) error {
+	present := s.requirePresence
+	if present == nil {
+		present = NonEmptyValue
+	}
+	var missing []string
+	for _, key := range keys {
+		found := false
+		for _, it := range (&ItemFilter{}).Slice(key).Apply(l).Items {
+			if present(it) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingKeysError{Keys: missing}
+	}
+	return nil
+}
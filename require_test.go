@@ -0,0 +1,34 @@
+package configstore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRequire(t *testing.T) {
+	s := NewInMemoryStore(NewItem("db-host", "localhost", 0), NewItem("api-key", " ", 0), NewItem("port", "", 1), NewItem("port", "5432", 0))
+
+	assert.NoError(t, s.Require("DB_HOST", "port"), "a key is present if any of its items has a value")
+
+	err := s.Require("db_host", "api_key", "missing")
+	require.Error(t, err)
+	assert.Equal(t, "configstore: missing required config: api_key, missing", err.Error())
+	var missing *MissingKeysError
+	require.True(t, errors.As(err, &missing))
+	assert.Equal(t, []string{"api_key", "missing"}, missing.Keys)
+
+	s.SetRequirePresence(func(Item) bool { return true })
+	err = s.Require("db_host", "api_key", "missing")
+	require.Error(t, err)
+	assert.Equal(t, "configstore: missing required config: missing", err.Error())
+
+	// as a validator, a missing key makes the store fail fast
+	s = NewStore()
+	s.AddValidator(s.RequireValidator("db-host"))
+	s.InMemory("inmem").Add(NewItem("port", "5432", 0))
+	_, err = s.GetItemList()
+	assert.EqualError(t, err, "configstore: validation: configstore: missing required config: db-host")
+}
@@ -0,0 +1,88 @@
+package configstore
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryPolicy describes how a failing provider is retried, see RegisterProviderWithRetry.
+// The delay between two attempts starts at BaseDelay and doubles after each failure, up to MaxDelay.
+// MaxAttempts is the total number of attempts, including the initial one. Zero means retrying forever.
+type RetryPolicy struct {
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxAttempts int
+}
+
+// DefaultRetryPolicy retries forever, every second at first, and every minute at most.
+var DefaultRetryPolicy = RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+
+// Returns the delay to wait for after the given delay.
+func (p RetryPolicy) next(delay time.Duration) time.Duration {
+	delay *= 2
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// RegisterProviderWithRetry registers a provider whose items are loaded once, typically from a network-backed source.
+// If the initial load fails, the provider serves the error while the load is retried in the background
+// with an exponential backoff, as described by the policy. On the first success the loaded items are served instead,
+// and the watchers are notified. This lets an application start before its configuration source is ready.
+// Retries stop when the store is closed.
+func (s *Store) RegisterProviderWithRetry(name string, load Provider, policy RetryPolicy) {
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+
+	r := &retryProvider{}
+	r.items, r.err = load()
+	s.RegisterProvider(name, r.Items)
+	if r.err == nil {
+		s.observeReload(name, len(r.items.Items))
+		return
+	}
+	s.logError(r.err, "provider", name, "attempt", 1)
+	s.observeError(name, r.err)
+
+	go func() {
+		delay := policy.BaseDelay
+		for attempt := 2; policy.MaxAttempts <= 0 || attempt <= policy.MaxAttempts; attempt++ {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			l, err := load()
+			r.mut.Lock()
+			r.items, r.err = l, err
+			r.mut.Unlock()
+			if err == nil {
+				s.logInfo("configuration loaded after retry", "provider", name, "attempt", attempt, "key_count", len(l.Items))
+				s.observeReload(name, len(l.Items))
+				s.NotifyWatchers()
+				return
+			}
+			s.logError(err, "provider", name, "attempt", attempt)
+			s.observeError(name, err)
+			delay = policy.next(delay)
+		}
+	}()
+}
+
+// The provider registered by RegisterProviderWithRetry, serving the result of the last load attempt.
+type retryProvider struct {
+	items ItemList
+	err   error
+	mut   sync.Mutex
+}
+
+func (r *retryProvider) Items() (ItemList, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	if r.err != nil {
+		return ItemList{}, r.err
+	}
+	return ItemList{Items: append([]Item(nil), r.items.Items...)}, nil
+}
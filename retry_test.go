@@ -0,0 +1,67 @@
+package configstore
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyNext(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, 2*time.Second, p.next(time.Second))
+	assert.Equal(t, 4*time.Second, p.next(2*time.Second))
+	assert.Equal(t, 5*time.Second, p.next(4*time.Second))
+	assert.Equal(t, 16*time.Second, RetryPolicy{}.next(8*time.Second))
+}
+
+func TestStoreRegisterProviderWithRetry(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+
+	var attempts int32
+	load := func() (ItemList, error) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return ItemList{}, errors.New("connection refused")
+		}
+		return ItemList{Items: []Item{NewItem("foo", "bar", 1)}}, nil
+	}
+	s.RegisterProviderWithRetry("vault", load, RetryPolicy{BaseDelay: 20 * time.Millisecond, MaxDelay: 40 * time.Millisecond})
+	ch := s.Watch()
+
+	// the error is served until the first success
+	_, err := s.GetItemList()
+	assert.True(t, errors.As(err, new(ErrProvider)))
+
+	require.Eventually(t, func() bool {
+		v, err := s.GetItemValue("foo")
+		return err == nil && v == "bar"
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	select {
+	case <-ch:
+	default:
+		t.Fatal("no notification after a successful retry")
+	}
+}
+
+func TestStoreRegisterProviderWithRetryMaxAttempts(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+
+	var attempts int32
+	load := func() (ItemList, error) {
+		atomic.AddInt32(&attempts, 1)
+		return ItemList{}, errors.New("connection refused")
+	}
+	s.RegisterProviderWithRetry("vault", load, RetryPolicy{BaseDelay: time.Millisecond, MaxAttempts: 3})
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&attempts) == 3 }, 5*time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	_, err := s.GetItemList()
+	assert.EqualError(t, err, "configstore: provider 'vault': connection refused")
+}
@@ -0,0 +1,198 @@
+// Package s3provider reads configstore items from a YAML object stored in AWS S3, in the format of the File provider.
+//
+//	p := s3provider.S3Refresh(configstore.DefaultStore, time.Minute, "my-bucket", "config/app.yaml")
+//	defer p.Close()
+//
+// The region and credentials come from the standard AWS chain (environment, shared config, instance role...).
+package s3provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ovh/configstore"
+)
+
+// Client is the subset of the S3 client used by the provider.
+type Client interface {
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+var _ Client = (*s3.Client)(nil)
+
+// Provider is a configstore provider serving the items of an S3 object.
+type Provider struct {
+	store  *configstore.Store
+	client Client
+	bucket string
+	key    string
+
+	items []configstore.Item
+	etag  string
+	creds aws.CredentialsProvider
+	mut   sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// S3 registers on the store a provider reading the given object (static content).
+// If the object cannot be read, e.g. if it does not exist or access is denied, the provider returns an error.
+func S3(s *configstore.Store, bucket, key string) *Provider {
+	return S3Refresh(s, 0, bucket, key)
+}
+
+// S3Refresh is similar to S3, checking the object's ETag at the given interval,
+// and downloading it again only when it changed. Watchers get notified of the changes.
+func S3Refresh(s *configstore.Store, interval time.Duration, bucket, key string) *Provider {
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := &Provider{store: s, bucket: bucket, key: key, ctx: ctx, cancel: cancel}
+		s.ErrorProvider(p.Name(), fmt.Errorf("s3provider: %v", err))
+		return p
+	}
+	return S3Client(s, s3.NewFromConfig(cfg), interval, bucket, key)
+}
+
+// S3Client is similar to S3Refresh, with a preconfigured client. An interval of 0 disables the refresh.
+func S3Client(s *configstore.Store, client Client, interval time.Duration, bucket, key string) *Provider {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{store: s, client: client, bucket: bucket, key: key, ctx: ctx, cancel: cancel}
+
+	items, etag, err := p.download()
+	if err != nil {
+		s.ErrorProvider(p.Name(), err)
+		return p
+	}
+	p.items, p.etag = items, etag
+	s.RegisterProvider(p.Name(), p.Items)
+
+	if interval > 0 {
+		go p.refresh(interval)
+	}
+	return p
+}
+
+// Name returns the name under which the provider is registered.
+func (p *Provider) Name() string {
+	return fmt.Sprintf("s3:%s/%s", p.bucket, p.key)
+}
+
+// Items returns the items read from the object.
+func (p *Provider) Items() (configstore.ItemList, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return configstore.ItemList{Items: append([]configstore.Item(nil), p.items...)}, nil
+}
+
+// Close stops the refresh.
+func (p *Provider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// UpdateCredentials replaces the credentials used by the client, e.g. to rotate an expiring session token
+// in place. The object is downloaded again with the new credentials, and watchers get notified.
+// On failure, the last items are kept, and the new credentials are still used by the following refreshes.
+func (p *Provider) UpdateCredentials(creds aws.CredentialsProvider) error {
+	if p.client == nil {
+		return p.error(fmt.Errorf("no client"))
+	}
+	p.mut.Lock()
+	p.creds = creds
+	p.mut.Unlock()
+
+	items, etag, err := p.download()
+	if err != nil {
+		return err
+	}
+	p.mut.Lock()
+	p.items, p.etag = items, etag
+	p.mut.Unlock()
+	p.store.NotifyWatchers()
+	return nil
+}
+
+func (p *Provider) options() []func(*s3.Options) {
+	p.mut.Lock()
+	creds := p.creds
+	p.mut.Unlock()
+	if creds == nil {
+		return nil
+	}
+	return []func(*s3.Options){func(o *s3.Options) { o.Credentials = creds }}
+}
+
+func (p *Provider) download() ([]configstore.Item, string, error) {
+	out, err := p.client.GetObject(p.ctx, &s3.GetObjectInput{Bucket: aws.String(p.bucket), Key: aws.String(p.key)}, p.options()...)
+	if err != nil {
+		return nil, "", p.error(err)
+	}
+	defer out.Body.Close()
+	b, err := p.store.ReadAllLimited(p.url(), out.Body)
+	if tooLarge, ok := err.(*configstore.FileTooLargeError); ok {
+		return nil, "", tooLarge
+	}
+	if err != nil {
+		return nil, "", p.error(err)
+	}
+	vals, err := configstore.DecodeItems(configstore.DefaultFilePriority)(b)
+	if err != nil {
+		return nil, "", &configstore.ProviderParseError{Name: p.Name(), Filename: p.url(), Cause: err}
+	}
+	return vals, aws.ToString(out.ETag), nil
+}
+
+func (p *Provider) refresh(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-t.C:
+		}
+		if err := p.reload(); err != nil && configstore.LogErrorFunc != nil {
+			configstore.LogErrorFunc("error: %v", err)
+		}
+	}
+}
+
+// Downloads the object again if its ETag changed. On failure, the last items are kept.
+func (p *Provider) reload() error {
+	head, err := p.client.HeadObject(p.ctx, &s3.HeadObjectInput{Bucket: aws.String(p.bucket), Key: aws.String(p.key)}, p.options()...)
+	if err != nil {
+		return p.error(err)
+	}
+	p.mut.Lock()
+	unchanged := aws.ToString(head.ETag) == p.etag
+	p.mut.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	items, etag, err := p.download()
+	if err != nil {
+		return err
+	}
+	p.mut.Lock()
+	p.items, p.etag = items, etag
+	p.mut.Unlock()
+	p.store.NotifyWatchers()
+	return nil
+}
+
+func (p *Provider) url() string {
+	return fmt.Sprintf("s3://%s/%s", p.bucket, p.key)
+}
+
+func (p *Provider) error(err error) error {
+	return &configstore.ProviderNetworkError{Name: p.Name(), URL: p.url(), Cause: err}
+}
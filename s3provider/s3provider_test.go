@@ -0,0 +1,211 @@
+package s3provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	mut       sync.Mutex
+	data      []byte
+	version   int
+	downloads int
+	// when set, the requests are denied unless made with this access key
+	accessKey string
+}
+
+func (c *fakeClient) authorize(ctx context.Context, optFns []func(*s3.Options)) error {
+	if c.accessKey == "" {
+		return nil
+	}
+	o := s3.Options{}
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.Credentials != nil {
+		creds, err := o.Credentials.Retrieve(ctx)
+		if err == nil && creds.AccessKeyID == c.accessKey {
+			return nil
+		}
+	}
+	return errors.New("access denied")
+}
+
+func (c *fakeClient) put(data string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.data = []byte(data)
+	c.version++
+}
+
+func (c *fakeClient) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if err := c.authorize(ctx, optFns); err != nil {
+		return nil, err
+	}
+	if c.data == nil {
+		return nil, &types.NotFound{}
+	}
+	return &s3.HeadObjectOutput{ETag: aws.String(fmt.Sprintf(`"%d"`, c.version))}, nil
+}
+
+func (c *fakeClient) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if err := c.authorize(ctx, optFns); err != nil {
+		return nil, err
+	}
+	if c.data == nil {
+		return nil, &types.NoSuchKey{}
+	}
+	c.downloads++
+	return &s3.GetObjectOutput{
+		Body: io.NopCloser(bytes.NewReader(c.data)),
+		ETag: aws.String(fmt.Sprintf(`"%d"`, c.version)),
+	}, nil
+}
+
+func (c *fakeClient) downloadCount() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.downloads
+}
+
+func TestS3(t *testing.T) {
+	c := &fakeClient{}
+	c.put("- key: foo\n  value: bar\n")
+
+	s := configstore.NewStore()
+	p := S3Client(s, c, 0, "bucket", "app.yaml")
+	defer p.Close()
+	v, err := s.GetItemValue("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", v)
+
+	// unchanged ETag: no download
+	require.NoError(t, p.reload())
+	assert.Equal(t, 1, c.downloadCount())
+
+	ch := s.Watch()
+	c.put("- key: foo\n  value: baz\n")
+	require.NoError(t, p.reload())
+	assert.Equal(t, 2, c.downloadCount())
+	<-ch
+	v, err = s.GetItemValue("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "baz", v)
+}
+
+func TestS3FileFormat(t *testing.T) {
+	c := &fakeClient{}
+	c.put("- key: foo\n  value: bar\n- key: port\n  value: 08080\n  priority: 0\n")
+
+	s := configstore.NewStore()
+	S3Client(s, c, 0, "bucket", "app.yaml")
+	i, err := s.GetItem("foo")
+	require.NoError(t, err)
+	assert.Equal(t, int64(configstore.DefaultFilePriority), i.Priority())
+	i, err = s.GetItem("port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), i.Priority())
+	v, err := i.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "08080", v, "the scalars should be kept as written")
+}
+
+func TestS3Refresh(t *testing.T) {
+	c := &fakeClient{}
+	c.put("- key: foo\n  value: bar\n")
+
+	s := configstore.NewStore()
+	p := S3Client(s, c, 10*time.Millisecond, "bucket", "app.yaml")
+	defer p.Close()
+
+	c.put("- key: foo\n  value: baz\n")
+	require.Eventually(t, func() bool {
+		v, err := s.GetItemValue("foo")
+		return err == nil && v == "baz"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func staticCredentials(accessKey string) aws.CredentialsProvider {
+	return aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: accessKey, SecretAccessKey: "secret"}, nil
+	})
+}
+
+func TestS3UpdateCredentials(t *testing.T) {
+	c := &fakeClient{}
+	c.put("- key: foo\n  value: bar\n")
+
+	s := configstore.NewStore()
+	p := S3Client(s, c, 0, "bucket", "app.yaml")
+	defer p.Close()
+
+	// the credentials expire
+	c.mut.Lock()
+	c.accessKey = "rotated"
+	c.mut.Unlock()
+	c.put("- key: foo\n  value: baz\n")
+	var netErr *configstore.ProviderNetworkError
+	assert.True(t, errors.As(p.reload(), &netErr))
+	assert.True(t, errors.As(p.UpdateCredentials(staticCredentials("expired")), &netErr))
+	v, err := s.GetItemValue("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", v, "the last items should be kept")
+
+	ch := s.Watch()
+	require.NoError(t, p.UpdateCredentials(staticCredentials("rotated")))
+	<-ch
+	v, err = s.GetItemValue("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "baz", v)
+
+	// the refresh uses the new credentials
+	c.put("- key: foo\n  value: qux\n")
+	require.NoError(t, p.reload())
+	v, err = s.GetItemValue("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "qux", v)
+}
+
+func TestS3Errors(t *testing.T) {
+	s := configstore.NewStore()
+	S3Client(s, &fakeClient{}, 0, "bucket", "missing.yaml")
+	_, err := s.GetItemList()
+	var netErr *configstore.ProviderNetworkError
+	require.True(t, errors.As(err, &netErr))
+	assert.Equal(t, "s3://bucket/missing.yaml", netErr.URL)
+	var noSuchKey *types.NoSuchKey
+	assert.True(t, errors.As(err, &noSuchKey))
+
+	c := &fakeClient{}
+	c.put("not: [a list")
+	s = configstore.NewStore()
+	S3Client(s, c, 0, "bucket", "invalid.yaml")
+	_, err = s.GetItemList()
+	var parseErr *configstore.ProviderParseError
+	assert.True(t, errors.As(err, &parseErr))
+
+	c.put("- key: a\n  value: a value longer than the limit\n")
+	s = configstore.NewStore()
+	s.SetMaxFileSize(16)
+	S3Client(s, c, 0, "bucket", "large.yaml")
+	_, err = s.GetItemList()
+	var tooLarge *configstore.FileTooLargeError
+	assert.True(t, errors.As(err, &tooLarge), "unexpected error: %v", err)
+}
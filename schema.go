@@ -0,0 +1,157 @@
+package configstore
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+)
+
+// Schema describes the expected configuration keys, see RegisterSchema.
+type Schema struct {
+	Keys []SchemaKey `json:"keys"`
+}
+
+// SchemaKey describes an expected configuration key. All the items sharing the key are checked.
+type SchemaKey struct {
+	Key string `json:"key"`
+	// Type is the expected type of the values: string (the default), int, uint, float, bool or duration,
+	// as parsed by the matching Item.As* accessor.
+	Type string `json:"type,omitempty"`
+	// Required keys must be present, with at least one item.
+	Required bool `json:"required,omitempty"`
+	// Pattern is a regular expression the whole values must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Enum lists the allowed values.
+	Enum []string `json:"enum,omitempty"`
+}
+
+var schemaTypes = map[string]func(Item) error{
+	"":         nil,
+	"string":   nil,
+	"int":      func(it Item) error { _, err := it.AsInt(); return err },
+	"uint":     func(it Item) error { _, err := it.AsUint64(); return err },
+	"float":    func(it Item) error { _, err := it.AsFloat64(); return err },
+	"bool":     func(it Item) error { _, err := it.AsBool(); return err },
+	"duration": func(it Item) error { _, err := it.AsDuration(); return err },
+}
+
+// SchemaFromFile reads a schema from a YAML (or JSON) file, e.g.:
+//
+//	keys:
+//	- key: db-host
+//	  required: true
+//	- key: db-port
+//	  type: int
+//	- key: log-level
+//	  enum: [debug, info, error]
+//
+// Unknown types and invalid patterns are reported right away.
+func SchemaFromFile(filename string) (Schema, error) {
+	var schema Schema
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return schema, err
+	}
+	if err := yaml.Unmarshal(b, &schema); err != nil {
+		return schema, &ProviderParseError{Name: "schema", Filename: filename, Cause: err}
+	}
+	for _, k := range schema.Keys {
+		if err := k.check(); err != nil {
+			return schema, fmt.Errorf("configstore: schema '%s': %v", filename, err)
+		}
+	}
+	return schema, nil
+}
+
+func (k SchemaKey) check() error {
+	if _, ok := schemaTypes[k.Type]; !ok {
+		return fmt.Errorf("key '%s': unknown type '%s'", k.Key, k.Type)
+	}
+	if k.Pattern != "" {
+		if _, err := regexp.Compile(k.Pattern); err != nil {
+			return fmt.Errorf("key '%s': invalid pattern: %v", k.Key, err)
+		}
+	}
+	return nil
+}
+
+// RegisterSchema adds a schema to be checked by Validate. Several schemas can be registered, e.g. one per component.
+func (s *Store) RegisterSchema(schema Schema) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.schemas = append(s.schemas, schema)
+}
+
+// Validate checks the current configuration against all the registered schemas, see RegisterSchema.
+// It returns a *SchemaError listing every violation, or nil if the configuration is valid.
+// This is meant to run once at startup; see AddValidator to check invariants on every load.
+func (s *Store) Validate() error {
+	l, err := s.GetItemList()
+	if err != nil {
+		return err
+	}
+	s.pMut.Lock()
+	schemas := append([]Schema(nil), s.schemas...)
+	s.pMut.Unlock()
+
+	var violations []string
+	for _, schema := range schemas {
+		for _, k := range schema.Keys {
+			violations = append(violations, k.validate(l)...)
+		}
+	}
+	if len(violations) > 0 {
+		return &SchemaError{Violations: violations}
+	}
+	return nil
+}
+
+func (k SchemaKey) validate(l *ItemList) []string {
+	if err := k.check(); err != nil {
+		return []string{err.Error()}
+	}
+	items := (&ItemFilter{}).Slice(k.Key).Apply(l).Items
+	if len(items) == 0 {
+		if k.Required {
+			return []string{fmt.Sprintf("key '%s': required", k.Key)}
+		}
+		return nil
+	}
+
+	var pattern *regexp.Regexp
+	if k.Pattern != "" {
+		pattern = regexp.MustCompile("^(?:" + k.Pattern + ")$")
+	}
+	var violations []string
+	for _, it := range items {
+		v, err := it.Value()
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("key '%s': %v", k.Key, err))
+			continue
+		}
+		if typ := schemaTypes[k.Type]; typ != nil {
+			if err := typ(it); err != nil {
+				violations = append(violations, err.Error())
+				continue
+			}
+		}
+		if pattern != nil && !pattern.MatchString(v) {
+			violations = append(violations, fmt.Sprintf("key '%s': value %q does not match pattern '%s'", k.Key, it.printableValue(), k.Pattern))
+		}
+		if len(k.Enum) > 0 && !contains(k.Enum, v) {
+			violations = append(violations, fmt.Sprintf("key '%s': value %q is not one of %v", k.Key, it.printableValue(), k.Enum))
+		}
+	}
+	return violations
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreValidateSchema(t *testing.T) {
+	schema := Schema{Keys: []SchemaKey{
+		{Key: "db-host", Required: true, Pattern: `[a-z0-9.-]+`},
+		{Key: "db-port", Type: "int"},
+		{Key: "log-level", Enum: []string{"debug", "info", "error"}},
+		{Key: "timeout", Type: "duration"},
+	}}
+
+	s := NewInMemoryStore(WithItems(map[string]string{"db-host": "db.local", "db-port": "5432", "log-level": "info"})...)
+	s.RegisterSchema(schema)
+	assert.NoError(t, s.Validate())
+
+	s = NewInMemoryStore(WithItems(map[string]string{"db-port": "http", "log-level": "verbose", "timeout": "10s"})...)
+	s.RegisterSchema(schema)
+	err := s.Validate()
+	require.Error(t, err)
+	var schemaErr *SchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, []string{
+		"key 'db-host': required",
+		`configstore: item 'db-port': cannot parse "http" as int: strconv.ParseInt: parsing "http": invalid syntax`,
+		`key 'log-level': value "verbose" is not one of [debug info error]`,
+	}, schemaErr.Violations)
+
+	s = NewInMemoryStore(WithItems(map[string]string{"db-host": "DB_HOST!"})...)
+	s.RegisterSchema(schema)
+	assert.EqualError(t, s.Validate(), `configstore: schema validation: 1 violation(s): key 'db-host': value "DB_HOST!" does not match pattern '[a-z0-9.-]+'`)
+}
+
+func TestSchemaFromFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "schema.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(`keys:
+- key: db-host
+  required: true
+- key: db-port
+  type: int
+- key: log-level
+  enum: [debug, info, error]
+`), 0600))
+
+	schema, err := SchemaFromFile(filename)
+	require.NoError(t, err)
+	require.Len(t, schema.Keys, 3)
+	assert.Equal(t, SchemaKey{Key: "db-port", Type: "int"}, schema.Keys[1])
+
+	s := NewInMemoryStore(WithItems(map[string]string{"db-port": "5432", "log-level": "debug"})...)
+	s.RegisterSchema(schema)
+	assert.EqualError(t, s.Validate(), "configstore: schema validation: 1 violation(s): key 'db-host': required")
+
+	require.NoError(t, os.WriteFile(filename, []byte("keys:\n- key: db-port\n  type: integer\n"), 0600))
+	_, err = SchemaFromFile(filename)
+	assert.EqualError(t, err, "configstore: schema '"+filename+"': key 'db-port': unknown type 'integer'")
+}
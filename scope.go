@@ -0,0 +1,139 @@
+package configstore
+
+import (
+	"strings"
+)
+
+// Getter is the read interface shared by Store and its scoped views.
+// Accept it instead of a *Store when a component only needs to read its configuration.
+type Getter interface {
+	Get(key string) (Item, error)
+	GetFirst(key string) (Item, error)
+	Filter() *ItemFilter
+	Unmarshal(key string, v interface{}) error
+}
+
+var (
+	_ Getter = &Store{}
+	_ Getter = &ScopedStore{}
+)
+
+// ScopedStore is a view on a subset of a store's items, the ones whose key begins with a given prefix.
+// Lookups are done relative to the prefix, and providers registered through the view get their item keys prefixed.
+// The view is live: it reflects the changes of the underlying store.
+type ScopedStore struct {
+	parent *Store
+	prefix string
+}
+
+// NamespacedStore is the view returned by Namespace: a ScopedStore whose prefix is used as is, with no separator.
+type NamespacedStore = ScopedStore
+
+// WithPrefix returns a view of the store scoped to the items whose key begins with "prefix.".
+// For example, Get("host") on the view returned by WithPrefix("db") looks up the "db.host" key.
+func (s *Store) WithPrefix(prefix string) *ScopedStore {
+	return &ScopedStore{parent: s, prefix: scopePrefix("", prefix)}
+}
+
+// WithPrefix returns a nested view, scoped to the items whose key begins with "prefix." within the current scope.
+func (s *ScopedStore) WithPrefix(prefix string) *ScopedStore {
+	return &ScopedStore{parent: s.parent, prefix: scopePrefix(s.prefix, prefix)}
+}
+
+// Namespace returns a view of the store scoped to the items whose key begins with prefix, used as is:
+// GetItemValue("host") on the view returned by Namespace("db-") looks up the "db-host" key.
+// Unlike WithPrefix, no separator is added. The prefix is normalized like any key.
+func (s *Store) Namespace(prefix string) *NamespacedStore {
+	return &ScopedStore{parent: s, prefix: transformKey(prefix)}
+}
+
+func scopePrefix(base, prefix string) string {
+	prefix = strings.Trim(transformKey(prefix), ".")
+	if prefix == "" {
+		return base
+	}
+	return base + prefix + "."
+}
+
+// Prefix returns the full key prefix of the view, including the trailing dot added by WithPrefix, if any.
+func (s *ScopedStore) Prefix() string {
+	return s.prefix
+}
+
+// RegisterProvider registers a provider on the underlying store. The keys of the items it returns
+// are prefixed so that they are only visible within the scope.
+func (s *ScopedStore) RegisterProvider(name string, f Provider) {
+	prefix := s.prefix
+	s.parent.RegisterProvider(prefix+name, func() (ItemList, error) {
+		l, err := f()
+		if err != nil {
+			return l, err
+		}
+		ret := ItemList{Items: make([]Item, 0, len(l.Items))}
+		for _, it := range l.Items {
+			it.key = prefix + it.key
+			ret.Items = append(ret.Items, it)
+		}
+		return ret, nil
+	})
+}
+
+// InMemory registers an InMemoryProvider within the scope and returns it.
+// The keys of the items added to it are prefixed, see RegisterProvider.
+func (s *ScopedStore) InMemory(name string) *InMemoryProvider {
+	inmem := &InMemoryProvider{}
+	s.RegisterProvider(name, inmem.Items)
+	return inmem
+}
+
+// Filter creates a new filter object operating on the scoped items, with the prefix removed from their keys.
+func (s *ScopedStore) Filter() *ItemFilter {
+	prefix := s.prefix
+	f := s.parent.Filter()
+	f.funcs = append(f.funcs, func(l *ItemList) *ItemList {
+		ret := &ItemList{}
+		for _, it := range l.Items {
+			if strings.HasPrefix(it.key, prefix) {
+				it.key = strings.TrimPrefix(it.key, prefix)
+				ret.Items = append(ret.Items, it)
+			}
+		}
+		return ret.index()
+	})
+	return f
+}
+
+// GetItemList retrieves the scoped item list, with the prefix removed from the keys.
+func (s *ScopedStore) GetItemList() (*ItemList, error) {
+	return s.Filter().GetItemList()
+}
+
+// Get returns a single item by key, relative to the scope.
+// If 0 or >=2 items are present with that key, it will return an error.
+func (s *ScopedStore) Get(key string) (Item, error) {
+	return s.Filter().GetItem(key)
+}
+
+// GetFirst returns the item with the highest priority for that key, relative to the scope.
+func (s *ScopedStore) GetFirst(key string) (Item, error) {
+	return s.Filter().Slice(key).GetFirstItem()
+}
+
+// GetItemValue fetches the value of a single item by key, relative to the scope.
+// If 0 or >=2 items are present with that key, it will return an error.
+func (s *ScopedStore) GetItemValue(key string) (string, error) {
+	i, err := s.Get(key)
+	if err != nil {
+		return "", err
+	}
+	return i.Value()
+}
+
+// Unmarshal retrieves a single item by key, relative to the scope, then unmarshals its value (from JSON or YAML) into v.
+func (s *ScopedStore) Unmarshal(key string, v interface{}) error {
+	i, err := s.Get(key)
+	if err != nil {
+		return err
+	}
+	return i.unmarshalValue(v)
+}
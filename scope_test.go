@@ -0,0 +1,92 @@
+package configstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopedStoreIsolation(t *testing.T) {
+	s := NewStore()
+	s.InMemory("global").Add(NewItem("host", "global-host", 1))
+
+	db := s.WithPrefix("db")
+	cache := s.WithPrefix("cache")
+
+	db.InMemory("defaults").Add(NewItem("host", "db-host", 1), NewItem("port", "5432", 1))
+	cache.InMemory("defaults").Add(NewItem("host", "cache-host", 1))
+
+	v, err := mustItem(db.Get("host")).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "db-host", v)
+
+	v, err = mustItem(cache.Get("host")).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "cache-host", v)
+
+	_, err = cache.Get("port")
+	assert.IsType(t, ErrItemNotFound(""), err)
+
+	// the parent sees the prefixed keys
+	v, err = s.GetItemValue("db.host")
+	require.NoError(t, err)
+	assert.Equal(t, "db-host", v)
+
+	v, err = s.GetItemValue("host")
+	require.NoError(t, err)
+	assert.Equal(t, "global-host", v)
+
+	l, err := db.GetItemList()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"host", "port"}, l.Keys())
+}
+
+func TestScopedStoreNested(t *testing.T) {
+	s := NewStore()
+	s.InMemory("test").Add(NewItem("app.db.port", "5432", 1), NewItem("app.db.pool", `{"size": 4}`, 1))
+
+	var g Getter = s.WithPrefix("app").WithPrefix("db")
+
+	i, err := g.GetFirst("port")
+	require.NoError(t, err)
+	assert.Equal(t, "port", i.Key())
+
+	pool := struct {
+		Size int `json:"size"`
+	}{}
+	require.NoError(t, g.Unmarshal("pool", &pool))
+	assert.Equal(t, 4, pool.Size)
+}
+
+func mustItem(i Item, err error) Item {
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+func TestStoreNamespace(t *testing.T) {
+	s := NewStore()
+	inmem := s.InMemory("global").Add(NewItem("DB_HOST", "db-host", 1), NewItem("host", "global-host", 1))
+
+	db := s.Namespace("DB_")
+	assert.Equal(t, "db-", db.Prefix())
+	v, err := db.GetItemValue("host")
+	require.NoError(t, err)
+	assert.Equal(t, "db-host", v)
+
+	_, err = db.GetItemValue("port")
+	assert.IsType(t, ErrItemNotFound(""), err)
+
+	// the view reflects live changes
+	inmem.Add(NewItem("db-port", "5432", 1))
+	v, err = db.GetItemValue("port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", v)
+
+	db.InMemory("defaults").Add(NewItem("user", "admin", 1))
+	v, err = s.GetItemValue("db-user")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", v)
+}
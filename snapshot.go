@@ -0,0 +1,88 @@
+package configstore
+
+// Snapshot is an immutable copy of the items returned by every provider of a store at a given time,
+// see Store.Snapshot and Store.Restore.
+type Snapshot struct {
+	providers map[string]snapshotProvider
+}
+
+type snapshotProvider struct {
+	items []Item
+	err   error
+}
+
+// Items returns the items of the snapshot, merged from all the providers.
+// Aliases, templates and environment variables are not resolved.
+func (sn Snapshot) Items() *ItemList {
+	ret := &ItemList{}
+	for n, p := range sn.providers {
+		for _, it := range p.items {
+			it.source = n
+			ret.Items = append(ret.Items, it)
+		}
+	}
+	return ret.index()
+}
+
+// Snapshot captures the current items of all the providers, e.g. to roll back to them with Restore if a new
+// configuration proves bad. A provider failing at that time is captured with its error.
+func (s *Store) Snapshot() Snapshot {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+
+	sn := Snapshot{providers: make(map[string]snapshotProvider, len(s.providers))}
+	for _, n := range s.providerNames() {
+		p := s.providers[n]
+		l, err := s.callProvider(n, p)
+		if err != nil {
+			sn.providers[n] = snapshotProvider{err: err}
+			continue
+		}
+		sn.providers[n] = snapshotProvider{items: append([]Item(nil), l.Items...)}
+	}
+	return sn
+}
+
+// Restore atomically replaces the state of the store with the one captured by the snapshot, and notifies the watchers.
+// Providers registered after the snapshot was taken are unregistered. In-memory providers (including the file ones)
+// get their content restored, so they keep being updated by their refresh, if any; other providers are replaced
+// with static ones serving the snapshotted items.
+// As it replaces the providers, it returns a *StoreFrozenError, and leaves the store unchanged, if the store is frozen.
+func (s *Store) Restore(sn Snapshot) error {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	if s.frozen {
+		return &StoreFrozenError{}
+	}
+	defer s.NotifyWatchers()
+
+	providers := make(map[string]Provider, len(sn.providers))
+	for n, p := range sn.providers {
+		items := append([]Item(nil), p.items...)
+		if p.err != nil {
+			providers[n] = newErrorProvider(p.err)
+			delete(s.inMemory, n)
+			continue
+		}
+		if inmem, ok := s.inMemory[n]; ok {
+			providers[n] = inmem.Replace(items...).Items
+			continue
+		}
+		providers[n] = func() (ItemList, error) {
+			return ItemList{Items: items}, nil
+		}
+	}
+	for n := range s.inMemory {
+		if _, ok := sn.providers[n]; !ok {
+			delete(s.inMemory, n)
+		}
+	}
+	for n := range s.registrations {
+		if _, ok := sn.providers[n]; !ok {
+			delete(s.registrations, n)
+			s.forgetProviderCall(n)
+		}
+	}
+	s.providers = providers
+	return nil
+}
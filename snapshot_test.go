@@ -0,0 +1,84 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSnapshotRestore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("- key: file\n  value: a\n"), 0600))
+
+	s := NewStore()
+	defer s.Close()
+	s.FileRefresh(filename)
+	s.InMemory("inmem").Set("mem", "a", 1)
+	custom := "a"
+	s.RegisterProvider("custom", func() (ItemList, error) {
+		return ItemList{Items: []Item{NewItem("custom", custom, 1)}}, nil
+	})
+
+	sn := s.Snapshot()
+	v, err := sn.Items().GetItemValue("custom")
+	require.NoError(t, err)
+	assert.Equal(t, "a", v)
+
+	require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+		p.Set("mem", "b", 1)
+		return nil
+	}))
+	custom = "b"
+	s.InMemory("late").Set("late", "b", 1)
+
+	ch := s.Watch()
+	require.NoError(t, s.Restore(sn))
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("watchers not notified")
+	}
+
+	for _, key := range []string{"file", "mem", "custom"} {
+		v, err := s.GetItemValue(key)
+		require.NoError(t, err)
+		assert.Equal(t, "a", v, key)
+	}
+	_, err = s.GetItemValue("late")
+	assert.Error(t, err, "providers registered after the snapshot must be removed")
+
+	// restored providers keep working
+	require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+		p.Set("mem", "c", 1)
+		return nil
+	}))
+	v, err = s.GetItemValue("mem")
+	require.NoError(t, err)
+	assert.Equal(t, "c", v)
+
+	rewriteFile(t, filename, "- key: file\n  value: c\n")
+	require.Eventually(t, func() bool {
+		v, _ := s.GetItemValue("file")
+		return v == "c"
+	}, 5*time.Second, 10*time.Millisecond, "refresh must still update the restored provider")
+}
+
+func TestStoreRestoreFrozen(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("mem", "a", 1)
+	sn := s.Snapshot()
+	s.InMemory("late").Set("late", "b", 1)
+	s.Freeze()
+
+	err := s.Restore(sn)
+	var frozen *StoreFrozenError
+	require.True(t, errors.As(err, &frozen), "unexpected error: %v", err)
+	v, err := s.GetItemValue("late")
+	require.NoError(t, err, "the providers of a frozen store must be kept")
+	assert.Equal(t, "b", v)
+}
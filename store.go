@@ -2,23 +2,68 @@ package configstore
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
 type Store struct {
+	pendingList           *itemListCall
+	flightMut             sync.Mutex
 	providers             map[string]Provider
+	inMemory              map[string]*InMemoryProvider
+	registrations         map[string]uint64
+	registrationSeq       uint64
 	pMut                  sync.Mutex
 	allowProviderOverride bool
+	strict                bool
+	strictDecoding        bool
+	frozen                bool
+	conflictResolver      func(key string, candidates []Item) Item
+	mergeStrategy         MergeStrategy
+	requirePresence       func(Item) bool
+	schemas               []Schema
+	base64Patterns        []string
+	profile               *string
+	aliases               []*alias
+	deprecations          map[string]*deprecation
+	now                   func() time.Time
+	patches               map[string]*patch
+	envExpansion          *envExpansion
+	templates             *templateSubstitution
+	validation            validation
+	timeouts              providerTimeouts
+	interceptor           ProviderInterceptor
+	async                 asyncLoading
 
 	watchers      []chan struct{}
+	diffWatchers  []chan ConfigDiff
 	watchersMut   sync.Mutex
 	watchersNotif bool
 
+	keyWatchers     []*keyWatcher
+	keyWatchersMut  sync.Mutex
+	keyWatchersOnce sync.Once
+
+	logger structuredLogger
+	logMut sync.RWMutex
+	// set to disable the informational logs, see BindLogLevel
+	quietInfo int32
+	// see SetMaxFileSize
+	maxFileSize int64
+
+	metrics        MetricsObserver
+	metricsHandler MetricsHandler
+	metricsMut     sync.RWMutex
+
+	auditLogger AuditLogger
+	auditMut    sync.RWMutex
+
 	ctx  context.Context
 	done context.CancelFunc
 }
@@ -67,7 +112,7 @@ func (s *Store) InitFromEnvironment() {
 		arg = strings.TrimSpace(arg)
 		f := providerFactories[name]
 		if f == nil {
-			errorProvider(s, fmt.Sprintf("%s:%s", name, arg), errors.New("failed to instantiate provider factory"))
+			errorProvider(s, fmt.Sprintf("%s:%s", name, arg), &ProviderNotFoundError{Name: name})
 		} else {
 			f(s, arg)
 		}
@@ -78,14 +123,45 @@ const (
 	ProviderConflictErrorLabel = "provider-conflict-error"
 )
 
-// RegisterProvider registers a provider
+// RegisterProvider registers a provider.
+// It is safe to call concurrently, including with readers of the store and from the refresh goroutines of other
+// providers: the registration happens atomically, and is seen by the readers whose evaluation of the providers starts
+// afterwards. The items of the providers are merged in registration order, so that the items sharing a key and a priority
+// are always ordered the same way (see GetItemValueList); providers registered concurrently are ordered as they acquire
+// the store lock.
 func (s *Store) RegisterProvider(name string, f Provider) {
+	s.registerProvider(name, f, nil)
+}
+
+// Returns the names of the providers in registration order, for a deterministic merge.
+// Must be called with s.pMut held.
+func (s *Store) providerNames() []string {
+	names := make([]string, 0, len(s.providers))
+	for n := range s.providers {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := s.registrations[names[i]], s.registrations[names[j]]
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Registers a provider, keeping track of the in-memory ones for Transaction.
+func (s *Store) registerProvider(name string, f Provider, inmem *InMemoryProvider) {
 	switch name {
 	case ProviderConflictErrorLabel:
 		return
 	}
 	s.pMut.Lock()
 	defer s.pMut.Unlock()
+	if s.frozen {
+		s.logError(&StoreFrozenError{Name: name})
+		return
+	}
 	defer s.NotifyWatchers()
 	_, ok := s.providers[name]
 	if ok && !s.allowProviderOverride {
@@ -93,13 +169,59 @@ func (s *Store) RegisterProvider(name string, f Provider) {
 		return
 	}
 	s.providers[name] = f
+	s.forgetProviderCall(name)
+	if s.registrations == nil {
+		s.registrations = map[string]uint64{}
+	}
+	s.registrationSeq++
+	s.registrations[name] = s.registrationSeq
+	if inmem != nil {
+		if s.inMemory == nil {
+			s.inMemory = map[string]*InMemoryProvider{}
+		}
+		s.inMemory[name] = inmem
+	} else {
+		delete(s.inMemory, name)
+	}
+}
+
+// KeepItemPriority can be passed to RegisterProviderWithPriority to keep the priorities set by the provider.
+const KeepItemPriority int64 = -1
+
+// RegisterProviderWithPriority registers a provider, overriding the priority of every item it returns
+// with the given value. This lets you declare that everything coming from a given source wins (or loses),
+// without editing the source data. If priority is KeepItemPriority, the items keep their own priorities.
+func (s *Store) RegisterProviderWithPriority(name string, f Provider, priority int64) {
+	if priority == KeepItemPriority {
+		s.RegisterProvider(name, f)
+		return
+	}
+	s.RegisterProvider(name, func() (ItemList, error) {
+		l, err := f()
+		if err != nil {
+			return l, err
+		}
+		ret := ItemList{Items: make([]Item, 0, len(l.Items))}
+		for _, it := range l.Items {
+			it.priority = priority
+			ret.Items = append(ret.Items, it)
+		}
+		return ret, nil
+	})
 }
 
 // UnregisterProvider unregisters a provider
 func (s *Store) UnregisterProvider(name string) {
 	s.pMut.Lock()
 	defer s.pMut.Unlock()
+	if s.frozen {
+		s.logError(&StoreFrozenError{Name: name})
+		return
+	}
 	delete(s.providers, name)
+	delete(s.inMemory, name)
+	delete(s.registrations, name)
+	s.forgetProviderCall(name)
 	s.NotifyWatchers()
 }
 
@@ -113,6 +235,25 @@ func (s *Store) AllowProviderOverride() {
 	s.allowProviderOverride = true
 }
 
+// SetStrict enables or disables the strict mode.
+// In strict mode, GetItemList returns an error when a key is defined by several providers at the same priority,
+// instead of silently keeping all the conflicting items. Overriding a key with a different priority is still allowed.
+func (s *Store) SetStrict(strict bool) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.strict = strict
+}
+
+// SetStrictDecoding enables or disables the strict decoding of the item files (File, FileList, FileEncrypted, LoadFromReader, ...).
+// The fields which are not item fields (key, value, priority, tags, description), e.g. a "valeu" typo, are always reported:
+// by default they are logged and ignored, in strict decoding mode the provider fails to load instead.
+// It applies to the providers registered afterwards.
+func (s *Store) SetStrictDecoding(strict bool) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.strictDecoding = strict
+}
+
 // ErrorProvider registers a configstore provider which always returns an error.
 func (s *Store) ErrorProvider(name string, err error) {
 	errorProvider(s, name, err)
@@ -124,6 +265,7 @@ func (s *Store) File(filename string) {
 }
 
 // FileRefresh registers a configstore provider which readfs from the file given in parameter (provider watches file stat for auto refresh, watchers get notified).
+// The file is also picked up when it is replaced, e.g. renamed over, or swapped by Kubernetes through the ..data link of a configmap mount.
 func (s *Store) FileRefresh(filename string) {
 	fileRefreshProvider(s, filename)
 }
@@ -138,6 +280,13 @@ func (s *Store) FileCustomRefresh(filename string, fn func([]byte) ([]Item, erro
 	fileCustomRefreshProvider(s, filename, fn)
 }
 
+// FileEncrypted registers a configstore provider which reads from the file given in parameter (static content),
+// decrypting it with AES-GCM and the given key before decoding it. See EncryptConfig to produce such a file.
+// Decryption failures, e.g. with a wrong key, make the provider return an error.
+func (s *Store) FileEncrypted(filename string, key []byte) {
+	fileEncryptedProvider(s, filename, key)
+}
+
 // FileTree registers a configstore provider which reads from the files contained in the directory given in parameter.
 // A limited hierarchy is supported: files can either be top level (in which case the file name will be used as the item key),
 // or nested in a single sub-directory (in which case the sub-directory name will be used as item key for all the files contained in it).
@@ -156,14 +305,31 @@ func (s *Store) FileTreeRefresh(dirname string) {
 
 // FileList registers a configstore provider which reads from the files contained in the directory given in parameter.
 // The content of the files should be JSON/YAML similar to the File provider.
-func (s *Store) FileList(dirname string) {
-	fileListProvider(s, dirname)
+// Symbolic links to files are followed, dangling ones are skipped; see WithDirectorySymlinks for links to directories.
+// Hidden files, whose name starts with a dot, are skipped, e.g. the temporary files of the editors.
+func (s *Store) FileList(dirname string, opts ...FileListOption) {
+	fileList(s, dirname, false, opts...)
 }
 
 // FileListRefresh is similar to the FileList provider with the refresh feature enabled.
+// The directory is watched as well: the files created in it are loaded, and the items of the removed files are dropped.
 // Updates can be handled with the `Watch()` function.
-func (s *Store) FileListRefresh(dirname string) {
-	fileListRefreshProvider(s, dirname)
+// On network file systems, which may not report the changes, see FileListRefreshProvider, which polls the directory.
+func (s *Store) FileListRefresh(dirname string, opts ...FileListOption) {
+	fileList(s, dirname, true, opts...)
+}
+
+// SQL registers a configstore provider which runs the given query on the database (static content).
+// The query must return 'key' and 'value' columns, and optionally a 'priority' column, e.g.
+// "SELECT key, value, priority FROM config". Other columns are ignored.
+func (s *Store) SQL(db *sql.DB, query string) {
+	sqlProvider(s, db, query, 0)
+}
+
+// SQLRefresh is similar to the SQL provider, re-running the query at the given interval.
+// Watchers get notified when the result changes.
+func (s *Store) SQLRefresh(db *sql.DB, query string, interval time.Duration) {
+	sqlProvider(s, db, query, interval)
 }
 
 // InMemory registers an InMemoryProvider with a given arbitrary name and returns it.
@@ -195,14 +361,30 @@ func (s *Store) Watch() chan struct{} {
 	return newCh
 }
 
+// Stops notifying a channel returned by Watch.
+func (s *Store) unwatch(ch chan struct{}) {
+	s.watchersMut.Lock()
+	defer s.watchersMut.Unlock()
+	for i, w := range s.watchers {
+		if w == ch {
+			s.watchers = append(s.watchers[:i], s.watchers[i+1:]...)
+			return
+		}
+	}
+}
+
 // NotifyWatchers is used by providers to notify of configuration changes.
 // It unblocks all the watchers which are ranging over a watch channel.
 func (s *Store) NotifyWatchers() {
+	if s.asyncLoading() {
+		return
+	}
 	s.watchersMut.Lock()
 	if !s.watchersNotif {
 		s.watchersMut.Unlock()
 		return
 	}
+	count := len(s.watchers)
 	for _, ch := range s.watchers {
 		select {
 		case ch <- struct{}{}:
@@ -210,6 +392,7 @@ func (s *Store) NotifyWatchers() {
 		}
 	}
 	s.watchersMut.Unlock()
+	s.observeNotification(count)
 }
 
 // NotifyMute prevents configstore from notifying watchers on configuration
@@ -247,21 +430,170 @@ func (s *Store) NotifyIsMuted() bool {
 
 // GetItemList retrieves the full item list, merging the results from all providers.
 // It does NOT cache, it's the responsability of the providers to keep an in-ram representation if desired.
+// Concurrent calls are deduplicated: the callers waiting for an evaluation of the providers to start share its result,
+// so that a burst of readers, e.g. right after a watchers notification, evaluates the providers only once.
+// A call never gets the result of an evaluation which started before it, so it always sees the prior changes.
 func (s *Store) GetItemList() (*ItemList, error) {
+	s.flightMut.Lock()
+	c := s.pendingList
+	if c != nil {
+		s.flightMut.Unlock()
+		<-c.done
+		if c.err != nil {
+			return nil, c.err
+		}
+		return (&ItemList{Items: append([]Item(nil), c.list.Items...)}).index(), nil
+	}
+	c = &itemListCall{done: make(chan struct{})}
+	s.pendingList = c
+	s.flightMut.Unlock()
 
 	s.pMut.Lock()
-	defer s.pMut.Unlock()
+	// the callers arriving from now on might have changed the providers: they need another evaluation
+	s.flightMut.Lock()
+	s.pendingList = nil
+	s.flightMut.Unlock()
+	c.list, c.err = s.buildItemList()
+	s.pMut.Unlock()
+	close(c.done)
+	return c.list, c.err
+}
 
+// An evaluation of the providers shared by the concurrent GetItemList calls, see GetItemList.
+type itemListCall struct {
+	done chan struct{}
+	list *ItemList
+	err  error
+}
+
+// Must be called with s.pMut held.
+func (s *Store) buildItemList() (*ItemList, error) {
 	ret := &ItemList{}
 
-	for n, p := range s.providers {
-		l, err := p()
+	now := s.clock()
+	for _, n := range s.providerNames() {
+		p := s.providers[n]
+		l, err := s.callProvider(n, p)
 		if err != nil {
-			return nil, ErrProvider(fmt.Sprintf("configstore: provider '%s': %v", n, err))
+			return nil, ErrProvider{msg: fmt.Sprintf("configstore: provider '%s': %v", n, err), err: err}
+		}
+		for _, it := range l.Items {
+			if it.expired(now) {
+				continue
+			}
+			it.source = n
+			ret.Items = append(ret.Items, it)
 		}
-		ret.Items = append(ret.Items, l.Items...)
 	}
-	return ret.index(), nil
+	s.applyPatches(ret, now)
+	if s.mergeStrategy != nil {
+		s.mergeItems(ret)
+	}
+	if s.conflictResolver != nil {
+		resolveConflicts(ret, s.conflictResolver)
+	}
+	if s.strict {
+		if err := checkDuplicates(ret); err != nil {
+			return nil, err
+		}
+	}
+	s.warnDeprecated(ret)
+	s.resolveAliases(ret)
+	s.substituteTemplates(ret)
+	s.expandEnv(ret)
+	s.decodeBase64(ret)
+	return s.validate(ret.index())
+}
+
+// SetConflictResolver installs a function choosing the item to keep when several providers define a key
+// at the same priority (see SetStrict), e.g. to implement "a given provider wins" or "concatenate lists" policies. The candidates are sorted by
+// provider name (see Item.Source), and replaced with the returned item, whose key is forced to the conflicting key.
+// Without a resolver, which is the default, all the conflicting items are kept.
+func (s *Store) SetConflictResolver(resolver func(key string, candidates []Item) Item) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.conflictResolver = resolver
+	s.NotifyWatchers()
+}
+
+// Replaces the items sharing a key and a priority with the item chosen by the resolver.
+func resolveConflicts(l *ItemList, resolver func(key string, candidates []Item) Item) {
+	type keyPriority struct {
+		key      string
+		priority int64
+	}
+	groups := map[keyPriority][]Item{}
+	for _, it := range l.Items {
+		kp := keyPriority{it.key, it.priority}
+		groups[kp] = append(groups[kp], it)
+	}
+	items := make([]Item, 0, len(l.Items))
+	for _, it := range l.Items {
+		kp := keyPriority{it.key, it.priority}
+		candidates := groups[kp]
+		switch {
+		case candidates == nil:
+			// already resolved
+		case !multipleSources(candidates):
+			items = append(items, it)
+		default:
+			sort.SliceStable(candidates, func(i, j int) bool {
+				return candidates[i].source < candidates[j].source
+			})
+			resolved := resolver(kp.key, candidates)
+			resolved.key = kp.key
+			items = append(items, resolved)
+			groups[kp] = nil
+		}
+	}
+	l.Items = items
+}
+
+func multipleSources(items []Item) bool {
+	for _, it := range items[1:] {
+		if it.source != items[0].source {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns an error for the first key (in alphabetical order) defined by several providers at the same priority.
+func checkDuplicates(l *ItemList) error {
+	type keyPriority struct {
+		key      string
+		priority int64
+	}
+	sources := map[keyPriority]map[string]bool{}
+	for _, it := range l.Items {
+		kp := keyPriority{it.key, it.priority}
+		if sources[kp] == nil {
+			sources[kp] = map[string]bool{}
+		}
+		sources[kp][it.source] = true
+	}
+	var conflicts []keyPriority
+	for kp, srcs := range sources {
+		if len(srcs) > 1 {
+			conflicts = append(conflicts, kp)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].key != conflicts[j].key {
+			return conflicts[i].key < conflicts[j].key
+		}
+		return conflicts[i].priority > conflicts[j].priority
+	})
+	kp := conflicts[0]
+	names := make([]string, 0, len(sources[kp]))
+	for n := range sources[kp] {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return ErrDuplicateItem(fmt.Sprintf("configstore: strict mode: key '%s' defined at priority %d by multiple providers: %s", kp.key, kp.priority, strings.Join(names, ", ")))
 }
 
 // GetItem retrieves the full item list, merging the results from all providers, then returns a single item by key.
@@ -283,6 +615,16 @@ func (s *Store) GetItemValue(key string) (string, error) {
 	return i.Value()
 }
 
+// GetItemValueList fetches the full item list, merging the results from all providers, then returns the values of all the items
+// sharing that key, ordered by descending priority.
+func (s *Store) GetItemValueList(key string) ([]string, error) {
+	items, err := s.GetItemList()
+	if err != nil {
+		return []string{}, err
+	}
+	return items.GetItemValueList(key)
+}
+
 // GetItemValueBool fetches the full item list, merging the results from all providers, then returns a single item's value by key.
 func (s *Store) GetItemValueBool(key string) (bool, error) {
 	i, err := s.GetItem(key)
@@ -327,3 +669,122 @@ func (s *Store) GetItemValueDuration(key string) (time.Duration, error) {
 	}
 	return i.ValueDuration()
 }
+
+// Get retrieves the full item list, merging the results from all providers, then returns a single item by key.
+// It is equivalent to GetItem, and the read is recorded by the audit logger, see EnableAuditLog.
+func (s *Store) Get(key string) (Item, error) {
+	return s.get(key, 1)
+}
+
+// skip is the number of stack frames between the caller of get and the code reading the item, see auditRead.
+func (s *Store) get(key string, skip int) (Item, error) {
+	i, err := s.GetItem(key)
+	s.auditRead(key, i, skip+1)
+	return i, err
+}
+
+// GetFirst retrieves the full item list, merging the results from all providers, then returns the item
+// with the highest priority for that key. The read is recorded by the audit logger, see EnableAuditLog.
+func (s *Store) GetFirst(key string) (Item, error) {
+	return s.getFirst(key, 1)
+}
+
+func (s *Store) getFirst(key string, skip int) (Item, error) {
+	i, err := s.Filter().Slice(key).GetFirstItem()
+	s.auditRead(key, i, skip+1)
+	return i, err
+}
+
+// Filter creates a new empty filter object operating on this store instance.
+func (s *Store) Filter() *ItemFilter {
+	return Filter().Store(s)
+}
+
+// Unmarshal retrieves a single item by key, then unmarshals its value (from JSON or YAML) into v.
+func (s *Store) Unmarshal(key string, v interface{}) error {
+	i, err := s.Get(key)
+	if err != nil {
+		return err
+	}
+	return i.unmarshalValue(v)
+}
+
+/*
+** GETTERS WITH DEFAULT VALUE
+ */
+
+// Logs the error which made a getter fall back to its default value.
+// A missing item is the expected case and is not logged, but an item which is present and cannot be used
+// (unparseable value, ambiguous key, provider error) is.
+func (s *Store) logDefaultValue(key string, err error) {
+	if errors.Is(err, ErrNotFound) {
+		return
+	}
+	s.logError(fmt.Errorf("configstore: get '%s': using default value: %v", key, err), "key", key)
+}
+
+// GetItemValueOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved for any other reason, the error is logged and def is returned.
+func (s *Store) GetItemValueOr(key string, def string) string {
+	v, err := s.GetItemValue(key)
+	if err != nil {
+		s.logDefaultValue(key, err)
+		return def
+	}
+	return v
+}
+
+// GetItemValueBoolOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func (s *Store) GetItemValueBoolOr(key string, def bool) bool {
+	v, err := s.GetItemValueBool(key)
+	if err != nil {
+		s.logDefaultValue(key, err)
+		return def
+	}
+	return v
+}
+
+// GetItemValueFloatOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func (s *Store) GetItemValueFloatOr(key string, def float64) float64 {
+	v, err := s.GetItemValueFloat(key)
+	if err != nil {
+		s.logDefaultValue(key, err)
+		return def
+	}
+	return v
+}
+
+// GetItemValueIntOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func (s *Store) GetItemValueIntOr(key string, def int64) int64 {
+	v, err := s.GetItemValueInt(key)
+	if err != nil {
+		s.logDefaultValue(key, err)
+		return def
+	}
+	return v
+}
+
+// GetItemValueUintOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func (s *Store) GetItemValueUintOr(key string, def uint64) uint64 {
+	v, err := s.GetItemValueUint(key)
+	if err != nil {
+		s.logDefaultValue(key, err)
+		return def
+	}
+	return v
+}
+
+// GetItemValueDurationOr fetches the full item list, merging the results from all providers, then returns a single item's value by key.
+// If the item is not found, def is returned. If it cannot be retrieved or parsed, the error is logged and def is returned.
+func (s *Store) GetItemValueDurationOr(key string, def time.Duration) time.Duration {
+	v, err := s.GetItemValueDuration(key)
+	if err != nil {
+		s.logDefaultValue(key, err)
+		return def
+	}
+	return v
+}
@@ -0,0 +1,153 @@
+package configstore
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultTemplateMaxDepth is the default maximum number of nested ${config:KEY} references, see EnableTemplateSubstitution.
+	DefaultTemplateMaxDepth = 10
+)
+
+var (
+	templateRef = regexp.MustCompile(`\$\{config:([^}]*)\}`)
+	selfRef     = regexp.MustCompile(`\$\{([^}:]*)\}`)
+)
+
+type templateSubstitution struct {
+	maxDepth int
+}
+
+// A TemplateOption modifies the behavior of the template substitution, see EnableTemplateSubstitution.
+type TemplateOption func(*templateSubstitution)
+
+// WithMaxDepth sets the maximum number of nested references followed when resolving a value.
+func WithMaxDepth(depth int) TemplateOption {
+	return func(t *templateSubstitution) {
+		t.maxDepth = depth
+	}
+}
+
+// EnableTemplateSubstitution activates the substitution of references to other items in item values:
+// occurrences of ${config:OTHER_KEY} are replaced with the value of the highest priority item of that key.
+// References are resolved recursively, up to DefaultTemplateMaxDepth levels (see WithMaxDepth).
+// Circular references, references to undefined keys or exceeding the maximum depth
+// result in an error returned when accessing the item's value.
+func (s *Store) EnableTemplateSubstitution(opts ...TemplateOption) {
+	t := &templateSubstitution{maxDepth: DefaultTemplateMaxDepth}
+	for _, o := range opts {
+		o(t)
+	}
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.templates = t
+	s.NotifyWatchers()
+}
+
+// Substitutes the ${config:KEY} references in the values of the (non-indexed) item list.
+// Must be called with s.pMut held.
+func (s *Store) substituteTemplates(l *ItemList) {
+	if s.templates == nil {
+		return
+	}
+	r := &templateResolver{ref: templateRef, values: map[string]string{}, maxDepth: s.templates.maxDepth}
+	priorities := map[string]int64{}
+	for _, it := range l.Items {
+		if p, ok := priorities[it.key]; !ok || it.priority > p {
+			priorities[it.key] = it.priority
+			r.values[it.key] = it.value
+		}
+	}
+	for i := range l.Items {
+		it := &l.Items[i]
+		if it.unmarshalErr != nil || !templateRef.MatchString(it.value) {
+			continue
+		}
+		v, err := r.expand(it.value, []string{it.key})
+		if err != nil {
+			it.unmarshalErr = fmt.Errorf("configstore: item '%s': %v", it.key, err)
+			continue
+		}
+		it.value = v
+	}
+}
+
+type templateResolver struct {
+	ref      *regexp.Regexp
+	values   map[string]string
+	maxDepth int
+	// leave the references to undefined keys as is, instead of failing
+	keepUndefined bool
+}
+
+func (r *templateResolver) expand(value string, path []string) (string, error) {
+	var err error
+	ret := r.ref.ReplaceAllStringFunc(value, func(m string) string {
+		if err != nil {
+			return m
+		}
+		key := transformKey(strings.TrimSpace(r.ref.FindStringSubmatch(m)[1]))
+		if _, ok := r.values[key]; !ok && r.keepUndefined {
+			return m
+		}
+		var v string
+		v, err = r.resolve(key, path)
+		return v
+	})
+	return ret, err
+}
+
+func (r *templateResolver) resolve(key string, path []string) (string, error) {
+	chain := make([]string, len(path), len(path)+1)
+	copy(chain, path)
+	chain = append(chain, key)
+	for _, k := range path {
+		if k == key {
+			return "", fmt.Errorf("circular reference: %s", strings.Join(chain, " -> "))
+		}
+	}
+	if len(path) > r.maxDepth {
+		return "", fmt.Errorf("maximum reference depth (%d) exceeded: %s", r.maxDepth, strings.Join(chain, " -> "))
+	}
+	v, ok := r.values[key]
+	if !ok {
+		return "", fmt.Errorf("reference to undefined key '%s'", key)
+	}
+	return r.expand(v, chain)
+}
+
+// InterpolateSelf returns a copy of the item list with the ${KEY} references in the item values replaced with the value
+// of the highest priority item of that key in the list, e.g. base-url: https://${host}:${port}. References are resolved
+// recursively, up to DefaultTemplateMaxDepth levels. Unlike EnableTemplateSubstitution, which uses ${config:KEY}
+// references, the syntax is the one of the environment variables: EnableEnvExpansion would replace them first.
+// Circular references, or exceeding the maximum depth, result in an error returned when accessing the item's value.
+// In strict mode, so do the references to undefined keys, which are otherwise left as is.
+func (s *ItemList) InterpolateSelf(strict bool) *ItemList {
+	ret := &ItemList{}
+	if s == nil {
+		return ret.index()
+	}
+	r := &templateResolver{ref: selfRef, values: map[string]string{}, maxDepth: DefaultTemplateMaxDepth, keepUndefined: !strict}
+	priorities := map[string]int64{}
+	for _, it := range s.Items {
+		if p, ok := priorities[it.key]; !ok || it.priority > p {
+			priorities[it.key] = it.priority
+			r.values[it.key] = it.value
+		}
+	}
+	ret.Items = make([]Item, 0, len(s.Items))
+	for _, it := range s.Items {
+		if it.unmarshalErr == nil && selfRef.MatchString(it.value) {
+			v, err := r.expand(it.value, []string{it.key})
+			if err != nil {
+				it.unmarshalErr = fmt.Errorf("configstore: item '%s': %v", it.key, err)
+			} else {
+				it.value = v
+			}
+		}
+		ret.Items = append(ret.Items, it)
+	}
+	return ret.index()
+}
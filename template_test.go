@@ -0,0 +1,97 @@
+package configstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateSubstitution(t *testing.T) {
+	s := NewStore()
+	s.InMemory("test").Add(
+		NewItem("api-url", "${config:base-url}/api/v1", 1),
+		NewItem("base-url", "https://${config:HOST}:${config:port}", 1),
+		NewItem("host", "${config:domain}", 1),
+		NewItem("domain", "example.com", 1),
+		NewItem("port", "8080", 2),
+		NewItem("port", "80", 1),
+	)
+	s.EnableTemplateSubstitution()
+
+	v, err := s.GetItemValue("api-url")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com:8080/api/v1", v)
+
+	s.EnableTemplateSubstitution(WithMaxDepth(2))
+	_, err = s.GetItemValue("api-url")
+	assert.EqualError(t, err, "configstore: item 'api-url': maximum reference depth (2) exceeded: api-url -> base-url -> host -> domain")
+}
+
+func TestTemplateSubstitutionErrors(t *testing.T) {
+	s := NewStore()
+	s.InMemory("test").Add(
+		NewItem("a", "${config:b}", 1),
+		NewItem("b", "x${config:a}", 1),
+		NewItem("c", "${config:undefined}", 1),
+		NewItem("d", "${config:a}", 1),
+	)
+	s.EnableTemplateSubstitution()
+
+	_, err := s.GetItemValue("a")
+	assert.EqualError(t, err, "configstore: item 'a': circular reference: a -> b -> a")
+	_, err = s.GetItemValue("d")
+	assert.EqualError(t, err, "configstore: item 'd': circular reference: d -> a -> b -> a")
+	_, err = s.GetItemValue("c")
+	assert.EqualError(t, err, "configstore: item 'c': reference to undefined key 'undefined'")
+}
+
+func TestTemplateSubstitutionWithEnvExpansion(t *testing.T) {
+	t.Setenv("CONFIGSTORE_TEST_SCHEME", "https")
+
+	s := NewStore()
+	s.InMemory("test").Add(
+		NewItem("url", "${CONFIGSTORE_TEST_SCHEME}://${config:host}", 1),
+		NewItem("host", "example.com", 1),
+	)
+	s.EnableEnvExpansion(WithStrictMode())
+
+	// references are left untouched by the environment expansion alone
+	v, err := s.GetItemValue("url")
+	require.NoError(t, err)
+	assert.Equal(t, "https://${config:host}", v)
+
+	s.EnableTemplateSubstitution()
+	v, err = s.GetItemValue("url")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", v)
+}
+
+func TestItemListInterpolateSelf(t *testing.T) {
+	l := (&ItemList{Items: []Item{
+		NewItem("base-url", "https://${HOST}:${port}", 1),
+		NewItem("host", "example.com", 1),
+		NewItem("port", "8080", 2),
+		NewItem("port", "80", 1),
+		NewItem("a", "${b}", 1),
+		NewItem("b", "x${a}", 1),
+		NewItem("c", "${undefined} ${config:host}", 1),
+	}}).index()
+
+	i := l.InterpolateSelf(false)
+	v, err := i.GetItemValue("base-url")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com:8080", v, "the winning value must be used")
+	_, err = i.GetItemValue("a")
+	assert.EqualError(t, err, "configstore: item 'a': circular reference: a -> b -> a")
+	v, err = i.GetItemValue("c")
+	require.NoError(t, err)
+	assert.Equal(t, "${undefined} ${config:host}", v, "undefined references must be left as is")
+
+	_, err = l.InterpolateSelf(true).GetItemValue("c")
+	assert.EqualError(t, err, "configstore: item 'c': reference to undefined key 'undefined'")
+
+	v, err = l.GetItemValue("base-url")
+	require.NoError(t, err)
+	assert.Equal(t, "https://${HOST}:${port}", v, "the original list must not be modified")
+}
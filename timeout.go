@@ -0,0 +1,119 @@
+package configstore
+
+import (
+	"time"
+)
+
+type providerTimeouts struct {
+	global      time.Duration
+	perProvider map[string]time.Duration
+	lastGood    map[string]ItemList
+	inflight    map[string]chan providerResult
+	// the providers registered with RegisterProviderWithTimeout, whose last good items are not used
+	noFallback map[string]bool
+}
+
+type providerResult struct {
+	items ItemList
+	err   error
+}
+
+// SetProviderTimeout sets the maximum duration of a provider call while evaluating the configuration (see GetItemList).
+// A provider exceeding it is considered failed for this evaluation: its last successfully returned items
+// are used instead, or a *ProviderTimeoutError is returned if it never succeeded.
+// The slow call is not interrupted: it keeps running in the background, and its result is used by the next evaluation.
+// Zero, the default, disables the timeout. See SetProviderTimeoutFor to override it for a given provider.
+func (s *Store) SetProviderTimeout(timeout time.Duration) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.timeouts.global = timeout
+}
+
+// SetProviderTimeoutFor overrides the timeout set by SetProviderTimeout for the provider with the given name.
+// Zero disables the timeout for this provider.
+func (s *Store) SetProviderTimeoutFor(name string, timeout time.Duration) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	if s.timeouts.perProvider == nil {
+		s.timeouts.perProvider = map[string]time.Duration{}
+	}
+	s.timeouts.perProvider[name] = timeout
+}
+
+// RegisterProviderWithTimeout registers a provider whose every call is abandoned after the given timeout,
+// in which case it returns a *ProviderTimeoutError. Unlike SetProviderTimeoutFor, no previous result is used in place
+// of a timed out call. The abandoned call is not interrupted: it keeps running fn to completion in the background,
+// and the next call waits for its result rather than calling fn again.
+func (s *Store) RegisterProviderWithTimeout(name string, fn Provider, timeout time.Duration) {
+	s.pMut.Lock()
+	if s.timeouts.perProvider == nil {
+		s.timeouts.perProvider = map[string]time.Duration{}
+	}
+	s.timeouts.perProvider[name] = timeout
+	if s.timeouts.noFallback == nil {
+		s.timeouts.noFallback = map[string]bool{}
+	}
+	s.timeouts.noFallback[name] = true
+	s.pMut.Unlock()
+	s.RegisterProvider(name, fn)
+}
+
+// Forgets the last good items and the running call of a provider, when it is unregistered or replaced,
+// so that they are not used for another provider registered later with the same name.
+// Must be called with s.pMut held.
+func (s *Store) forgetProviderCall(name string) {
+	delete(s.timeouts.lastGood, name)
+	delete(s.timeouts.inflight, name)
+}
+
+// Calls the provider, applying its timeout if any.
+// Must be called with s.pMut held.
+func (s *Store) callProviderWithTimeout(name string, p Provider) (ItemList, error) {
+	t := &s.timeouts
+	timeout, ok := t.perProvider[name]
+	if !ok {
+		timeout = t.global
+	}
+	if timeout <= 0 {
+		delete(t.inflight, name)
+		return p()
+	}
+
+	// a previous call may still be running, wait for it rather than piling up calls to a slow provider
+	ch, ok := t.inflight[name]
+	if !ok {
+		ch = make(chan providerResult, 1)
+		go func() {
+			l, err := p()
+			ch <- providerResult{l, err}
+		}()
+		if t.inflight == nil {
+			t.inflight = map[string]chan providerResult{}
+		}
+		t.inflight[name] = ch
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		delete(t.inflight, name)
+		if r.err == nil {
+			if t.lastGood == nil {
+				t.lastGood = map[string]ItemList{}
+			}
+			t.lastGood[name] = r.items
+		}
+		return r.items, r.err
+	case <-timer.C:
+	}
+
+	elapsed := time.Since(start)
+	s.logError(&ProviderTimeoutError{Name: name, Elapsed: elapsed}, "provider", name)
+	s.observeTimeout(name, elapsed)
+	if l, ok := t.lastGood[name]; ok && !t.noFallback[name] {
+		return ItemList{Items: append([]Item(nil), l.Items...)}, nil
+	}
+	return ItemList{}, &ProviderTimeoutError{Name: name, Elapsed: elapsed}
+}
@@ -0,0 +1,118 @@
+package configstore
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutObserver struct {
+	testObserver
+	timeouts int32
+}
+
+func (o *timeoutObserver) ProviderTimeout(provider string, elapsed time.Duration) {
+	atomic.AddInt32(&o.timeouts, 1)
+}
+
+func TestStoreProviderTimeout(t *testing.T) {
+	s := NewStore()
+	o := &timeoutObserver{testObserver: testObserver{reloads: map[string][]int{}, errors: map[string]int{}}}
+	s.SetMetricsObserver(o)
+	s.SetProviderTimeout(20 * time.Millisecond)
+
+	var slow int32
+	s.RegisterProvider("consul", func() (ItemList, error) {
+		if atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return ItemList{Items: []Item{NewItem("foo", "bar", 1)}}, nil
+	})
+	v, err := s.GetItemValue("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", v)
+
+	// the last good items are used while the provider is slow
+	atomic.StoreInt32(&slow, 1)
+	start := time.Now()
+	v, err = s.GetItemValue("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", v)
+	assert.True(t, time.Since(start) < 150*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&o.timeouts))
+
+	// without last good items, the timeout is an error
+	s.RegisterProvider("vault", func() (ItemList, error) {
+		time.Sleep(200 * time.Millisecond)
+		return ItemList{}, nil
+	})
+	_, err = s.GetItemList()
+	var timeoutErr *ProviderTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, "vault", timeoutErr.Name)
+
+	// the timeout can be disabled per provider
+	s.SetProviderTimeoutFor("vault", 0)
+	s.SetProviderTimeoutFor("consul", 0)
+	_, err = s.GetItemList()
+	assert.NoError(t, err)
+}
+
+func TestStoreProviderTimeoutReregistered(t *testing.T) {
+	s := NewStore()
+	s.SetProviderTimeout(20 * time.Millisecond)
+	s.RegisterProvider("p", func() (ItemList, error) {
+		return ItemList{Items: []Item{NewItem("k", "old", 1)}}, nil
+	})
+	v, err := s.GetItemValue("k")
+	require.NoError(t, err)
+	assert.Equal(t, "old", v)
+
+	// the last good items of the previous provider must not stand in for a new one with the same name
+	s.UnregisterProvider("p")
+	s.RegisterProvider("p", func() (ItemList, error) {
+		time.Sleep(200 * time.Millisecond)
+		return ItemList{Items: []Item{NewItem("other", "new", 1)}}, nil
+	})
+	_, err = s.GetItemValue("k")
+	var timeoutErr *ProviderTimeoutError
+	assert.True(t, errors.As(err, &timeoutErr), "unexpected error: %v", err)
+}
+
+func TestStoreRegisterProviderWithTimeout(t *testing.T) {
+	s := NewStore()
+	s.InMemory("defaults").Add(NewItem("foo", "default", 1))
+	s.RegisterProviderWithTimeout("vault", func() (ItemList, error) {
+		time.Sleep(200 * time.Millisecond)
+		return ItemList{Items: []Item{NewItem("foo", "partial", 2)}}, nil
+	}, 20*time.Millisecond)
+
+	start := time.Now()
+	l, err := s.GetItemList()
+	assert.True(t, time.Since(start) < 150*time.Millisecond)
+	assert.Nil(t, l, "no partial items must be applied")
+	var timeoutErr *ProviderTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, "vault", timeoutErr.Name)
+	assert.True(t, timeoutErr.Elapsed >= 20*time.Millisecond)
+
+	s.RegisterProviderWithTimeout("fast", func() (ItemList, error) {
+		return ItemList{Items: []Item{NewItem("bar", "baz", 1)}}, nil
+	}, time.Second)
+	s.UnregisterProvider("vault")
+	v, err := s.GetItemValue("bar")
+	require.NoError(t, err)
+	assert.Equal(t, "baz", v)
+
+	// the timeout does not depend on the lifetime of the store
+	s.Close()
+	for i := 0; i < 100; i++ {
+		v, err := s.GetItemValue("bar")
+		require.NoError(t, err)
+		assert.Equal(t, "baz", v)
+	}
+}
@@ -0,0 +1,30 @@
+package configstore
+
+import (
+	"fmt"
+)
+
+// Transaction applies a batch of mutations to the in-memory provider with the given name (see InMemory) atomically:
+// fn mutates a copy of the provider, which replaces its content only if fn returns nil.
+// Readers never observe a partially applied batch, and watchers are notified once, after the batch is applied.
+// If fn returns an error, no mutation is applied and the error is returned.
+func (s *Store) Transaction(name string, fn func(p *InMemoryProvider) error) error {
+	s.pMut.Lock()
+	inmem, ok := s.inMemory[name]
+	s.pMut.Unlock()
+	if !ok {
+		return fmt.Errorf("configstore: transaction: no in-memory provider '%s'", name)
+	}
+
+	inmem.mut.Lock()
+	tx := &InMemoryProvider{items: append([]Item(nil), inmem.items...)}
+	if err := fn(tx); err != nil {
+		inmem.mut.Unlock()
+		return err
+	}
+	inmem.items = tx.items
+	inmem.mut.Unlock()
+
+	s.NotifyWatchers()
+	return nil
+}
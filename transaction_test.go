@@ -0,0 +1,70 @@
+package configstore
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreTransaction(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("a", "0", 1).Set("b", "0", 1)
+
+	err := s.Transaction("inmem", func(p *InMemoryProvider) error {
+		p.Set("a", "1", 1)
+		return errors.New("aborted")
+	})
+	assert.EqualError(t, err, "aborted")
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "0", v, "an aborted transaction must not be applied")
+
+	assert.Error(t, s.Transaction("unknown", func(p *InMemoryProvider) error { return nil }))
+
+	ch := s.Watch()
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			l, err := s.GetItemList()
+			if !assert.NoError(t, err) {
+				return
+			}
+			a, _ := l.GetItemValue("a")
+			b, _ := l.GetItemValue("b")
+			if !assert.Equal(t, a, b, "half-applied transaction") {
+				return
+			}
+		}
+	}()
+	for n := 1; n <= 100; n++ {
+		v := strconv.Itoa(n)
+		require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+			p.Set("a", v, 1)
+			p.Set("b", v, 1)
+			return nil
+		}))
+	}
+	close(stop)
+	wg.Wait()
+
+	v, err = s.GetItemValue("b")
+	require.NoError(t, err)
+	assert.Equal(t, "100", v)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("watchers must be notified after a transaction")
+	}
+}
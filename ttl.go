@@ -0,0 +1,40 @@
+package configstore
+
+import (
+	"time"
+)
+
+// NewItemWithTTL creates an item object which expires after the given duration, e.g. for a temporary rate limit
+// override. Once expired, the item is ignored by the store, as if its provider did not return it.
+// The store is not notified of the expiration: the watchers only see the change on the next notification.
+// See InMemoryProvider.Purge to drop the expired items.
+func NewItemWithTTL(key, value string, priority int64, ttl time.Duration) Item {
+	i := NewItem(key, value, priority)
+	i.expires = time.Now().Add(ttl)
+	return i
+}
+
+// ExpiresAt returns the expiration time of the item, see NewItemWithTTL, and false if the item does not expire.
+func (s Item) ExpiresAt() (time.Time, bool) {
+	return s.expires, !s.expires.IsZero()
+}
+
+func (s Item) expired(now time.Time) bool {
+	return !s.expires.IsZero() && !now.Before(s.expires)
+}
+
+// Purge removes the expired items from the in-memory list, and returns the number of items removed.
+func (inmem *InMemoryProvider) Purge() int {
+	now := time.Now()
+	inmem.mut.Lock()
+	defer inmem.mut.Unlock()
+	items := make([]Item, 0, len(inmem.items))
+	for _, i := range inmem.items {
+		if !i.expired(now) {
+			items = append(items, i)
+		}
+	}
+	n := len(inmem.items) - len(items)
+	inmem.items = items
+	return n
+}
@@ -0,0 +1,57 @@
+package configstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemTTL(t *testing.T) {
+	s := NewStore()
+	inmem := s.InMemory("overrides").
+		Add(NewItem("rate-limit", "10", 1), NewItemWithTTL("rate-limit", "100", 10, 50*time.Millisecond)).
+		Add(NewItemWithTTL("override", "on", 1, 50*time.Millisecond), NewItemWithTTL("flag", "on", 1, time.Hour))
+
+	i, err := s.GetFirst("rate-limit")
+	require.NoError(t, err)
+	assert.Equal(t, "100", mustValue(i))
+	_, ok := i.ExpiresAt()
+	assert.True(t, ok)
+	i, err = s.Get("override")
+	require.NoError(t, err)
+	assert.Equal(t, "on", mustValue(i))
+
+	time.Sleep(100 * time.Millisecond)
+
+	// the expired items are skipped, even before being purged
+	i, err = s.GetFirst("rate-limit")
+	require.NoError(t, err)
+	assert.Equal(t, "10", mustValue(i))
+	i, err = s.Get("rate-limit")
+	require.NoError(t, err)
+	assert.Equal(t, "10", mustValue(i))
+	_, err = s.Get("override")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	assert.Equal(t, 2, inmem.Purge())
+	l, _ := inmem.Items()
+	assert.Len(t, l.Items, 2)
+	assert.Equal(t, 0, inmem.Purge())
+
+	i, err = s.Get("flag")
+	require.NoError(t, err)
+	assert.Equal(t, "on", mustValue(i))
+}
+
+func TestItemTTLExpiredKey(t *testing.T) {
+	s := NewStore()
+	s.InMemory("overrides").Add(NewItemWithTTL("flag", "on", 1, 50*time.Millisecond))
+	now := time.Now()
+	s.now = func() time.Time { return now.Add(time.Minute) }
+
+	_, err := s.Get("flag")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
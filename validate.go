@@ -0,0 +1,72 @@
+package configstore
+
+import (
+	"fmt"
+)
+
+type validation struct {
+	validators []func(ItemList) error
+	lastValid  *ItemList
+	err        error
+}
+
+// AddValidator adds a function checking invariants on the whole configuration, e.g. that a port is within 1-65535.
+// Validators run every time the configuration is loaded, i.e. on GetItemList and after every file refresh.
+// If a validator returns an error, the new configuration is rejected: the error is logged,
+// and the last configuration that passed validation keeps being served, so that a bad edit cannot take down
+// a live service. If no configuration ever passed validation, GetItemList returns the error.
+// See ValidationError to expose the state of the validation, e.g. in a readiness probe.
+func (s *Store) AddValidator(fn func(ItemList) error) {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	s.validation.validators = append(s.validation.validators, fn)
+	s.NotifyWatchers()
+}
+
+// ValidationError returns the error of the last validation of the configuration, or nil if it was valid.
+// See AddValidator.
+func (s *Store) ValidationError() error {
+	s.pMut.Lock()
+	defer s.pMut.Unlock()
+	return s.validation.err
+}
+
+// Runs the validators on the (indexed) item list. If the list is rejected, the last valid list is returned instead.
+// Must be called with s.pMut held.
+func (s *Store) validate(l *ItemList) (*ItemList, error) {
+	v := &s.validation
+	if len(v.validators) == 0 {
+		return l, nil
+	}
+	for _, fn := range v.validators {
+		err := fn(*l)
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("configstore: validation: %v", err)
+		if v.err == nil || v.err.Error() != err.Error() {
+			s.logError(err)
+		}
+		v.err = err
+		if v.lastValid == nil {
+			return nil, err
+		}
+		return (&ItemList{Items: append([]Item(nil), v.lastValid.Items...)}).index(), nil
+	}
+	v.err = nil
+	v.lastValid = &ItemList{Items: append([]Item(nil), l.Items...)}
+	return l, nil
+}
+
+// Runs the validators right away after a reload, rather than at the next GetItemList call.
+// Returns false if the new configuration was rejected, in which case watchers should not be notified.
+func (s *Store) revalidate() bool {
+	s.pMut.Lock()
+	n := len(s.validation.validators)
+	s.pMut.Unlock()
+	if n == 0 {
+		return true
+	}
+	s.GetItemList()
+	return s.ValidationError() == nil
+}
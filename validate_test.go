@@ -0,0 +1,98 @@
+package configstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validPort(l ItemList) error {
+	i, err := Filter().Squash().Apply(&l).GetItem("port")
+	if err != nil {
+		return err
+	}
+	port, err := i.ValueInt()
+	if err != nil {
+		return err
+	}
+	if port < 1 || port > 65535 {
+		return errors.New("port must be within 1-65535")
+	}
+	return nil
+}
+
+func TestStoreValidator(t *testing.T) {
+	s := NewStore()
+	inmem := s.InMemory("inmem")
+	s.AddValidator(validPort)
+
+	// no valid configuration yet
+	_, err := s.GetItemList()
+	assert.EqualError(t, err, "configstore: validation: configstore: get 'port': no item found")
+	assert.Error(t, s.ValidationError())
+
+	inmem.Add(NewItem("port", "8080", 1))
+	v, err := s.GetItemValueInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8080), v)
+	assert.NoError(t, s.ValidationError())
+
+	// the invalid configuration is rejected, the last valid one is kept
+	inmem.Add(NewItem("port", "70000", 2))
+	v, err = s.GetItemValueInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8080), v)
+	assert.EqualError(t, s.ValidationError(), "configstore: validation: port must be within 1-65535")
+
+	// a valid override is accepted
+	inmem.Add(NewItem("port", "9090", 3))
+	i, err := s.GetFirst("port")
+	require.NoError(t, err)
+	assert.Equal(t, "9090", i.value)
+	assert.NoError(t, s.ValidationError())
+}
+
+func TestStoreValidatorFileRefresh(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+	s.AddValidator(validPort)
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(port int) {
+		require.NoError(t, os.WriteFile(filename, []byte("- key: port\n  value: \""+strconv.Itoa(port)+"\"\n"), 0600))
+	}
+	write(8080)
+	s.FileRefresh(filename)
+	v, err := s.GetItemValueInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8080), v)
+
+	ch := s.Watch()
+	write(0)
+	require.Eventually(t, func() bool { return s.ValidationError() != nil }, 5*time.Second, 10*time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("watchers must not be notified of a rejected configuration")
+	default:
+	}
+	v, err = s.GetItemValueInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8080), v)
+
+	write(9090)
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification after a valid refresh")
+	}
+	v, err = s.GetItemValueInt("port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9090), v)
+	assert.NoError(t, s.ValidationError())
+}
@@ -0,0 +1,31 @@
+package configstore
+
+import (
+	"context"
+	"errors"
+)
+
+// Wait blocks until the key is provided, e.g. by an asynchronous provider, and returns the item with the highest
+// priority for it, see GetFirst. The key is looked up again on every notification (see Watch), until the context is
+// done, in which case the context error is returned. The store failing to read its providers does not stop the wait.
+func (s *Store) Wait(ctx context.Context, key string) (Item, error) {
+	notif := s.Watch()
+	defer s.unwatch(notif)
+
+	for {
+		it, err := s.GetFirst(key)
+		if err == nil {
+			return it, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			s.logError(err, "key", key)
+		}
+		select {
+		case <-ctx.Done():
+			return Item{}, ctx.Err()
+		case <-s.ctx.Done():
+			return Item{}, s.ctx.Err()
+		case <-notif:
+		}
+	}
+}
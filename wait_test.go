@@ -0,0 +1,50 @@
+package configstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreWait(t *testing.T) {
+	s := NewStore()
+	inmem := s.InMemory("async")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		inmem.Set("db-host", "localhost", 1)
+		s.NotifyWatchers()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	it, err := s.Wait(ctx, "DB_HOST")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", mustValue(it))
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+	assert.True(t, time.Since(start) < time.Second)
+
+	// an available key is returned right away
+	it, err = s.Wait(ctx, "db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", mustValue(it))
+
+	s.watchersMut.Lock()
+	assert.Empty(t, s.watchers, "the watcher must be unregistered")
+	s.watchersMut.Unlock()
+}
+
+func TestStoreWaitCancel(t *testing.T) {
+	s := NewStore()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := s.Wait(ctx, "missing")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	s.watchersMut.Lock()
+	assert.Empty(t, s.watchers, "the watcher must be unregistered")
+	s.watchersMut.Unlock()
+}
@@ -0,0 +1,86 @@
+package configstore
+
+type keyWatcher struct {
+	key     string
+	ch      chan string
+	value   string
+	present bool
+}
+
+// WatchKey returns a channel receiving the new value of the key every time it changes, and a function to stop watching.
+// The value is the one of the item with the highest priority; an empty string is sent when the key is removed.
+// Changes are detected on every notification (see Watch), by comparing the value with the previous one.
+// Delivery never blocks: if the consumer is late, only the latest value is kept in the channel.
+func (s *Store) WatchKey(key string) (<-chan string, func()) {
+	w := &keyWatcher{key: transformKey(key), ch: make(chan string, 1)}
+	if l, err := s.GetItemList(); err == nil {
+		w.value, w.present = watchedValue(l, w.key)
+	}
+
+	s.keyWatchersMut.Lock()
+	s.keyWatchers = append(s.keyWatchers, w)
+	s.keyWatchersMut.Unlock()
+	s.keyWatchersOnce.Do(func() {
+		go s.watchKeys(s.Watch())
+	})
+
+	cancel := func() {
+		s.keyWatchersMut.Lock()
+		defer s.keyWatchersMut.Unlock()
+		for i, kw := range s.keyWatchers {
+			if kw == w {
+				s.keyWatchers = append(s.keyWatchers[:i], s.keyWatchers[i+1:]...)
+				close(w.ch)
+				return
+			}
+		}
+	}
+	return w.ch, cancel
+}
+
+// Evaluates the watched keys on every notification, delivering their changes.
+func (s *Store) watchKeys(ch chan struct{}) {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ch:
+		}
+		l, err := s.GetItemList()
+		if err != nil {
+			continue
+		}
+		s.keyWatchersMut.Lock()
+		for _, w := range s.keyWatchers {
+			v, present := watchedValue(l, w.key)
+			if v == w.value && present == w.present {
+				continue
+			}
+			w.value, w.present = v, present
+			// coalesce: replace the pending value, if any
+			select {
+			case w.ch <- v:
+			default:
+				select {
+				case <-w.ch:
+				default:
+				}
+				w.ch <- v
+			}
+		}
+		s.keyWatchersMut.Unlock()
+	}
+}
+
+// Returns the value of the item with the highest priority for the key.
+func watchedValue(l *ItemList, key string) (string, bool) {
+	items := l.indexed[key]
+	if len(items) == 0 {
+		return "", false
+	}
+	v, err := items[0].Value()
+	if err != nil {
+		return "", false
+	}
+	return v, true
+}
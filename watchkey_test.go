@@ -0,0 +1,77 @@
+package configstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiveKey(t *testing.T, ch <-chan string) string {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("no value received")
+		return ""
+	}
+}
+
+func TestStoreWatchKey(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("a", "1", 1).Set("b", "1", 1)
+
+	ch, cancel := s.WatchKey("A")
+	defer cancel()
+
+	require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+		p.Set("b", "2", 1)
+		return nil
+	}))
+	require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+		p.Set("a", "2", 1)
+		return nil
+	}))
+	assert.Equal(t, "2", receiveKey(t, ch))
+
+	s.InMemory("other").Set("a", "3", 10)
+	assert.Equal(t, "3", receiveKey(t, ch))
+
+	s.UnregisterProvider("inmem")
+	s.UnregisterProvider("other")
+	assert.Equal(t, "", receiveKey(t, ch), "removal must be delivered")
+
+	select {
+	case v := <-ch:
+		t.Fatalf("unexpected value %q", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStoreWatchKeyCoalesce(t *testing.T) {
+	s := NewStore()
+	s.InMemory("inmem").Set("a", "0", 1)
+
+	ch, cancel := s.WatchKey("a")
+	for _, v := range []string{"1", "2", "3"} {
+		v := v
+		require.NoError(t, s.Transaction("inmem", func(p *InMemoryProvider) error {
+			p.Set("a", v, 1)
+			return nil
+		}))
+	}
+	require.Eventually(t, func() bool {
+		select {
+		case v := <-ch:
+			return v == "3"
+		default:
+			return false
+		}
+	}, 2*time.Second, 10*time.Millisecond, "the latest value must be delivered")
+
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok, "the channel must be closed by cancel")
+}
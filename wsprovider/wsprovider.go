@@ -0,0 +1,176 @@
+// Package wsprovider reads configstore items from the frames pushed on a WebSocket endpoint.
+// Each frame, text or binary, is decoded with a codec into the full item set, which replaces the previous one.
+//
+//	p := wsprovider.WebSocketProvider(configstore.DefaultStore, "wss://config.example.com/watch", decode,
+//		wsprovider.WithHeader(http.Header{"Authorization": []string{"Bearer " + token}}))
+//	defer p.Close()
+package wsprovider
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/ovh/configstore"
+)
+
+const (
+	// DefaultBaseBackoff is the default delay before the first reconnection attempt.
+	DefaultBaseBackoff = time.Second
+	// DefaultMaxBackoff is the default maximum delay between two reconnection attempts.
+	DefaultMaxBackoff = time.Minute
+)
+
+// WSOption modifies the behavior of the provider.
+type WSOption func(*Provider)
+
+// WithHeader sets the HTTP headers sent with the handshake, e.g. for authentication.
+func WithHeader(h http.Header) WSOption {
+	return func(p *Provider) {
+		p.header = h
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to wss:// endpoints.
+func WithTLSConfig(c *tls.Config) WSOption {
+	return func(p *Provider) {
+		p.dialer.TLSClientConfig = c
+	}
+}
+
+// WithBackoff sets the delay before reconnecting after a disconnection: it starts at base, and doubles after each
+// consecutive failure, up to max.
+func WithBackoff(base, max time.Duration) WSOption {
+	return func(p *Provider) {
+		p.baseBackoff = base
+		p.maxBackoff = max
+	}
+}
+
+// Provider is a configstore provider serving the items decoded from the last frame received on a WebSocket endpoint.
+// When disconnected, the last items keep being served while reconnection is attempted.
+type Provider struct {
+	url         string
+	codec       func([]byte) ([]configstore.Item, error)
+	header      http.Header
+	dialer      websocket.Dialer
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	items chan []configstore.Item
+	stop  func()
+
+	conn *websocket.Conn
+	mut  sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// WebSocketProvider registers on the store a provider connecting to the WebSocket endpoint at url,
+// and decoding each frame received with codec. The connection is established in the background:
+// the provider has no items until the first frame is received.
+func WebSocketProvider(s *configstore.Store, url string, codec func([]byte) ([]configstore.Item, error), opts ...WSOption) *Provider {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{
+		url:         url,
+		codec:       codec,
+		dialer:      *websocket.DefaultDialer,
+		baseBackoff: DefaultBaseBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		items:       make(chan []configstore.Item),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	p.stop = configstore.ChanProvider(s, p.Name(), p.items)
+	go p.run()
+	return p
+}
+
+// Name returns the name under which the provider is registered.
+func (p *Provider) Name() string {
+	return fmt.Sprintf("websocket:%s", p.url)
+}
+
+// Close disconnects from the endpoint. The provider keeps serving the last items.
+func (p *Provider) Close() error {
+	p.cancel()
+	p.stop()
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.conn != nil {
+		return p.conn.Close()
+	}
+	return nil
+}
+
+// Connects to the endpoint and reads its frames, reconnecting with an exponential backoff.
+func (p *Provider) run() {
+	delay := p.baseBackoff
+	for {
+		err := p.read(func() { delay = p.baseBackoff })
+		if p.ctx.Err() != nil {
+			return
+		}
+		logError(&configstore.ProviderNetworkError{Name: p.Name(), URL: p.url, Cause: err})
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if p.maxBackoff > 0 && delay > p.maxBackoff {
+			delay = p.maxBackoff
+		}
+	}
+}
+
+// Connects to the endpoint, and applies the frames received until the connection fails.
+// connected is called once the connection is established.
+func (p *Provider) read(connected func()) error {
+	conn, _, err := p.dialer.DialContext(p.ctx, p.url, p.header)
+	if err != nil {
+		return err
+	}
+	p.mut.Lock()
+	if p.ctx.Err() != nil {
+		p.mut.Unlock()
+		conn.Close()
+		return p.ctx.Err()
+	}
+	p.conn = conn
+	p.mut.Unlock()
+	defer conn.Close()
+	connected()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		items, err := p.codec(data)
+		if err != nil {
+			// a bad frame does not break the connection
+			logError(fmt.Errorf("wsprovider: %s: cannot decode frame: %v", p.url, err))
+			continue
+		}
+		select {
+		case p.items <- items:
+		case <-p.ctx.Done():
+			return p.ctx.Err()
+		}
+	}
+}
+
+func logError(err error) {
+	if configstore.LogErrorFunc != nil {
+		configstore.LogErrorFunc("error: %v", err)
+	}
+}
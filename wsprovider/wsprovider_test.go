@@ -0,0 +1,132 @@
+package wsprovider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(b []byte) ([]configstore.Item, error) {
+	var items []configstore.Item
+	err := json.Unmarshal(b, &items)
+	return items, err
+}
+
+// The fake endpoint: each connection sends the frames pushed on the channel, and is closed when nil is pushed.
+type fakeServer struct {
+	frames chan []byte
+	conns  chan http.Header
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	f.conns <- r.Header
+	for frame := range f.frames {
+		if frame == nil {
+			return
+		}
+		typ := websocket.TextMessage
+		if strings.HasPrefix(string(frame), " ") {
+			typ = websocket.BinaryMessage
+		}
+		if err := conn.WriteMessage(typ, frame); err != nil {
+			return
+		}
+	}
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{frames: make(chan []byte), conns: make(chan http.Header, 10)}
+}
+
+func expectValue(t *testing.T, s *configstore.Store, w chan struct{}, key, value string) {
+	t.Helper()
+	select {
+	case <-w:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchers not notified")
+	}
+	v, err := s.GetItemValue(key)
+	require.NoError(t, err)
+	assert.Equal(t, value, v)
+}
+
+func TestWebSocketProvider(t *testing.T) {
+	f := newFakeServer()
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s := configstore.NewStore()
+	defer s.Close()
+	w := s.Watch()
+	p := WebSocketProvider(s, "ws"+strings.TrimPrefix(srv.URL, "http"), decode,
+		WithHeader(http.Header{"Authorization": []string{"Bearer token"}}),
+		WithBackoff(10*time.Millisecond, 50*time.Millisecond))
+	defer p.Close()
+	<-w // registration
+
+	h := <-f.conns
+	assert.Equal(t, "Bearer token", h.Get("Authorization"))
+
+	f.frames <- []byte(`[{"key": "a", "value": "1"}, {"key": "b", "value": "1"}]`)
+	expectValue(t, s, w, "a", "1")
+	f.frames <- []byte(` [{"key": "a", "value": "2"}]`)
+	expectValue(t, s, w, "a", "2")
+	_, err := s.GetItemValue("b")
+	assert.Error(t, err, "each frame must replace the whole item set")
+
+	f.frames <- []byte(`not json`)
+
+	// reconnection after a disconnection
+	f.frames <- nil
+	select {
+	case <-f.conns:
+	case <-time.After(2 * time.Second):
+		t.Fatal("not reconnected")
+	}
+	v, err := s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "2", v, "the last items must be kept while reconnecting")
+	f.frames <- []byte(`[{"key": "a", "value": "3"}]`)
+	expectValue(t, s, w, "a", "3")
+
+	require.NoError(t, p.Close())
+	select {
+	case f.frames <- []byte(`[{"key": "a", "value": "4"}]`):
+	case <-time.After(100 * time.Millisecond):
+	}
+	time.Sleep(50 * time.Millisecond)
+	v, err = s.GetItemValue("a")
+	require.NoError(t, err)
+	assert.Equal(t, "3", v, "frames received after Close must be ignored")
+}
+
+func TestWebSocketProviderTLS(t *testing.T) {
+	f := newFakeServer()
+	srv := httptest.NewTLSServer(f)
+	defer srv.Close()
+
+	s := configstore.NewStore()
+	defer s.Close()
+	w := s.Watch()
+	p := WebSocketProvider(s, "wss"+strings.TrimPrefix(srv.URL, "https"), decode,
+		WithTLSConfig(srv.Client().Transport.(*http.Transport).TLSClientConfig))
+	defer p.Close()
+	<-w
+
+	<-f.conns
+	f.frames <- []byte(`[{"key": "a", "value": "1"}]`)
+	expectValue(t, s, w, "a", "1")
+}
@@ -0,0 +1,324 @@
+// Package zkprovider reads configstore items from a ZooKeeper znode tree.
+//
+//	p, err := zkprovider.ZooKeeperProvider(configstore.DefaultStore, []string{"zk1:2181", "zk2:2181"}, "/config/myapp",
+//		zkprovider.WithAuth("digest", []byte("user:password")))
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer p.Close()
+//
+// Each leaf node of the tree becomes an item, named after its path relative to the root
+// (e.g. /config/myapp/db/host becomes "db/host"), with the node data as value.
+// The nodes are watched: data updates, and created or deleted nodes, are applied as they happen.
+package zkprovider
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/ovh/configstore"
+)
+
+const (
+	// DefaultSessionTimeout is the default timeout of the ZooKeeper session.
+	DefaultSessionTimeout = 10 * time.Second
+	// RetryInterval is the delay between two attempts to read the tree after a failure.
+	RetryInterval = 10 * time.Second
+)
+
+// Client is the subset of *zk.Conn used by the provider.
+type Client interface {
+	Children(path string) ([]string, *zk.Stat, error)
+	ChildrenW(path string) ([]string, *zk.Stat, <-chan zk.Event, error)
+	Get(path string) ([]byte, *zk.Stat, error)
+	GetW(path string) ([]byte, *zk.Stat, <-chan zk.Event, error)
+}
+
+var _ Client = &zk.Conn{}
+
+// ZKOption modifies the behavior of the provider.
+type ZKOption func(*Provider)
+
+// WithSessionTimeout sets the timeout of the ZooKeeper session, DefaultSessionTimeout by default.
+// It is ignored by ZooKeeperClient.
+func WithSessionTimeout(d time.Duration) ZKOption {
+	return func(p *Provider) {
+		p.sessionTimeout = d
+	}
+}
+
+// WithAuth authenticates the session with the given scheme and credentials, e.g. "digest" and "user:password".
+// It is ignored by ZooKeeperClient.
+func WithAuth(scheme string, credentials []byte) ZKOption {
+	return func(p *Provider) {
+		p.authScheme = scheme
+		p.authCredentials = credentials
+	}
+}
+
+// Provider is a configstore provider serving the leaf nodes of a ZooKeeper znode tree.
+// When the tree cannot be read, the last known items keep being served while reading is attempted periodically.
+type Provider struct {
+	store           *configstore.Store
+	client          Client
+	conn            *zk.Conn
+	root            string
+	sessionTimeout  time.Duration
+	authScheme      string
+	authCredentials []byte
+
+	items   map[string]string
+	err     error
+	missing bool
+	mut     sync.Mutex
+
+	// the paths with a pending watch, on their children and on their data
+	childWatches map[string]bool
+	dataWatches  map[string]bool
+	watchMut     sync.Mutex
+
+	trigger chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// ZooKeeperProvider connects to the ZooKeeper servers, and registers on the store a provider serving
+// the leaf nodes of the tree rooted at rootPath. An error is returned if the connection cannot be set up.
+func ZooKeeperProvider(s *configstore.Store, servers []string, rootPath string, opts ...ZKOption) (*Provider, error) {
+	p := newProvider(s, rootPath, opts)
+	conn, _, err := zk.Connect(servers, p.sessionTimeout, zk.WithLogInfo(false))
+	if err != nil {
+		return nil, p.error(err)
+	}
+	if p.authScheme != "" {
+		if err := conn.AddAuth(p.authScheme, p.authCredentials); err != nil {
+			conn.Close()
+			return nil, p.error(err)
+		}
+	}
+	p.conn = conn
+	p.client = conn
+	p.start()
+	return p, nil
+}
+
+// ZooKeeperClient is similar to ZooKeeperProvider, with an established connection, which is not closed by Close.
+func ZooKeeperClient(s *configstore.Store, client Client, rootPath string, opts ...ZKOption) *Provider {
+	p := newProvider(s, rootPath, opts)
+	p.client = client
+	p.start()
+	return p
+}
+
+func newProvider(s *configstore.Store, rootPath string, opts []ZKOption) *Provider {
+	p := &Provider{
+		store:          s,
+		root:           path.Clean("/" + rootPath),
+		sessionTimeout: DefaultSessionTimeout,
+		childWatches:   map[string]bool{},
+		dataWatches:    map[string]bool{},
+		trigger:        make(chan struct{}, 1),
+		done:           make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+func (p *Provider) start() {
+	p.reload()
+	p.store.RegisterProvider(p.Name(), p.Items)
+	go p.watch()
+}
+
+// Name returns the name under which the provider is registered.
+func (p *Provider) Name() string {
+	return fmt.Sprintf("zookeeper:%s", p.root)
+}
+
+// Items returns the items of the leaf nodes. If the tree was never read, it returns the error.
+func (p *Provider) Items() (configstore.ItemList, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.items == nil {
+		return configstore.ItemList{}, p.err
+	}
+	ret := configstore.ItemList{Items: make([]configstore.Item, 0, len(p.items))}
+	for k, v := range p.items {
+		ret.Items = append(ret.Items, configstore.NewItem(k, v, 0))
+	}
+	return ret, nil
+}
+
+// Close stops watching the tree, and closes the connection opened by ZooKeeperProvider.
+// The provider keeps serving the last items.
+func (p *Provider) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+		if p.conn != nil {
+			p.conn.Close()
+		}
+	})
+	return nil
+}
+
+// Reloads the tree whenever a watch fires, and periodically while it cannot be read.
+func (p *Provider) watch() {
+	t := time.NewTicker(RetryInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-p.trigger:
+			p.reload()
+		case <-t.C:
+			p.mut.Lock()
+			failed := p.err != nil || p.missing
+			p.mut.Unlock()
+			if failed {
+				p.reload()
+			}
+		}
+	}
+}
+
+// Reads the whole tree. On failure, the last known items are kept.
+// A missing root node cannot be watched: it is served as an empty tree, and read again periodically.
+func (p *Provider) reload() {
+	items := map[string]string{}
+	err := p.walk(p.root, items)
+	missing := err == zk.ErrNoNode
+	if missing {
+		err = nil
+	}
+	p.mut.Lock()
+	changed := err == nil && !equal(p.items, items)
+	p.err = err
+	p.missing = missing
+	if err == nil {
+		p.items = items
+	}
+	p.mut.Unlock()
+
+	if err != nil {
+		logError(p.error(err))
+		return
+	}
+	if changed {
+		p.store.NotifyWatchers()
+	}
+}
+
+func (p *Provider) walk(node string, items map[string]string) error {
+	children, err := p.children(node)
+	if err == zk.ErrNoNode && node != p.root {
+		// deleted in the meantime, its parent's watch fired
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		if node == p.root {
+			return nil
+		}
+		data, err := p.data(node)
+		if err == zk.ErrNoNode {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		items[strings.TrimPrefix(node, p.root+"/")] = string(data)
+		return nil
+	}
+	for _, c := range children {
+		if err := p.walk(path.Join(node, c), items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lists the children of the node, watching them if it is not already.
+func (p *Provider) children(node string) ([]string, error) {
+	if p.watching(p.childWatches, node) {
+		children, _, err := p.client.Children(node)
+		return children, err
+	}
+	children, _, ch, err := p.client.ChildrenW(node)
+	if err != nil {
+		return nil, err
+	}
+	p.addWatch(p.childWatches, node, ch)
+	return children, nil
+}
+
+// Reads the data of the node, watching it if it is not already.
+func (p *Provider) data(node string) ([]byte, error) {
+	if p.watching(p.dataWatches, node) {
+		data, _, err := p.client.Get(node)
+		return data, err
+	}
+	data, _, ch, err := p.client.GetW(node)
+	if err != nil {
+		return nil, err
+	}
+	p.addWatch(p.dataWatches, node, ch)
+	return data, nil
+}
+
+func (p *Provider) watching(watches map[string]bool, node string) bool {
+	p.watchMut.Lock()
+	defer p.watchMut.Unlock()
+	return watches[node]
+}
+
+// Records a pending watch. ZooKeeper watches fire once: when it does, the tree is reloaded, setting a new one.
+func (p *Provider) addWatch(watches map[string]bool, node string, ch <-chan zk.Event) {
+	p.watchMut.Lock()
+	watches[node] = true
+	p.watchMut.Unlock()
+	go func() {
+		select {
+		case <-p.done:
+			return
+		case <-ch:
+		}
+		p.watchMut.Lock()
+		delete(watches, node)
+		p.watchMut.Unlock()
+		select {
+		case p.trigger <- struct{}{}:
+		default:
+		}
+	}()
+}
+
+func (p *Provider) error(err error) error {
+	return &configstore.ProviderNetworkError{Name: p.Name(), URL: "zookeeper://" + p.root, Cause: err}
+}
+
+func equal(a, b map[string]string) bool {
+	if len(a) != len(b) || (a == nil) != (b == nil) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+func logError(err error) {
+	if configstore.LogErrorFunc != nil {
+		configstore.LogErrorFunc("error: %v", err)
+	}
+}
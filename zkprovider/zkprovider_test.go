@@ -0,0 +1,191 @@
+package zkprovider
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	"github.com/ovh/configstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// An in-memory znode tree, with one-shot watches like ZooKeeper.
+type fakeClient struct {
+	nodes         map[string][]byte
+	childWatches  map[string][]chan zk.Event
+	dataWatches   map[string][]chan zk.Event
+	childWatchers int
+	mut           sync.Mutex
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		nodes:        map[string][]byte{"/": nil},
+		childWatches: map[string][]chan zk.Event{},
+		dataWatches:  map[string][]chan zk.Event{},
+	}
+}
+
+func (f *fakeClient) Children(p string) ([]string, *zk.Stat, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if _, ok := f.nodes[p]; !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	var children []string
+	for n := range f.nodes {
+		if n != "/" && path.Dir(n) == p {
+			children = append(children, path.Base(n))
+		}
+	}
+	sort.Strings(children)
+	return children, &zk.Stat{}, nil
+}
+
+func (f *fakeClient) ChildrenW(p string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	children, stat, err := f.Children(p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ch := make(chan zk.Event, 1)
+	f.mut.Lock()
+	f.childWatches[p] = append(f.childWatches[p], ch)
+	f.childWatchers++
+	f.mut.Unlock()
+	return children, stat, ch, nil
+}
+
+func (f *fakeClient) Get(p string) ([]byte, *zk.Stat, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	data, ok := f.nodes[p]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	return data, &zk.Stat{}, nil
+}
+
+func (f *fakeClient) GetW(p string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+	data, stat, err := f.Get(p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ch := make(chan zk.Event, 1)
+	f.mut.Lock()
+	f.dataWatches[p] = append(f.dataWatches[p], ch)
+	f.mut.Unlock()
+	return data, stat, ch, nil
+}
+
+// Must be called with f.mut held.
+func (f *fakeClient) fire(watches map[string][]chan zk.Event, p string, typ zk.EventType) {
+	for _, ch := range watches[p] {
+		ch <- zk.Event{Type: typ, Path: p}
+	}
+	delete(watches, p)
+}
+
+func (f *fakeClient) set(p, data string) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if _, ok := f.nodes[p]; ok {
+		f.nodes[p] = []byte(data)
+		f.fire(f.dataWatches, p, zk.EventNodeDataChanged)
+		return
+	}
+	for n := p; n != "/"; n = path.Dir(n) {
+		if _, ok := f.nodes[n]; !ok {
+			f.nodes[n] = nil
+			f.fire(f.childWatches, path.Dir(n), zk.EventNodeChildrenChanged)
+		}
+	}
+	f.nodes[p] = []byte(data)
+}
+
+func (f *fakeClient) delete(p string) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	for n := range f.nodes {
+		if n == p || strings.HasPrefix(n, p+"/") {
+			delete(f.nodes, n)
+			f.fire(f.dataWatches, n, zk.EventNodeDeleted)
+			f.fire(f.childWatches, n, zk.EventNodeDeleted)
+		}
+	}
+	f.fire(f.childWatches, path.Dir(p), zk.EventNodeChildrenChanged)
+}
+
+func TestZooKeeperProvider(t *testing.T) {
+	f := newFakeClient()
+	f.set("/config/app/db/host", "localhost")
+	f.set("/config/app/db/port", "5432")
+	f.set("/config/app/name", "app")
+	f.set("/config/other/name", "other")
+
+	s := configstore.NewStore()
+	defer s.Close()
+	p := ZooKeeperClient(s, f, "/config/app")
+	defer p.Close()
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 3, l.Len())
+	v, err := l.GetItemValue("db/host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v)
+	_, err = l.GetItem("db")
+	assert.Error(t, err, "only leaf nodes are items")
+
+	expect := func(key, value string) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			v, err := s.GetItemValue(key)
+			if value == "" {
+				return err != nil
+			}
+			return err == nil && v == value
+		}, 2*time.Second, 5*time.Millisecond, "%s: expected %q", key, value)
+	}
+
+	f.set("/config/app/db/host", "remote")
+	expect("db/host", "remote")
+	f.set("/config/app/cache/host", "memcached")
+	expect("cache/host", "memcached")
+	f.set("/config/app/db/host", "remote2")
+	expect("db/host", "remote2")
+	f.delete("/config/app/db/port")
+	expect("db/port", "")
+	f.delete("/config/app/cache")
+	expect("cache/host", "")
+
+	// watches are not duplicated for nodes which did not change
+	f.mut.Lock()
+	watchers := f.childWatchers
+	f.mut.Unlock()
+	assert.Less(t, watchers, 12)
+
+	require.NoError(t, p.Close())
+	f.set("/config/app/db/host", "closed")
+	time.Sleep(20 * time.Millisecond)
+	v, err = s.GetItemValue("db/host")
+	require.NoError(t, err)
+	assert.Equal(t, "remote2", v, "updates after Close must be ignored")
+}
+
+func TestZooKeeperProviderMissingRoot(t *testing.T) {
+	f := newFakeClient()
+	s := configstore.NewStore()
+	defer s.Close()
+	p := ZooKeeperClient(s, f, "config/app")
+	defer p.Close()
+	assert.Equal(t, "zookeeper:/config/app", p.Name())
+
+	l, err := s.GetItemList()
+	require.NoError(t, err)
+	assert.Equal(t, 0, l.Len())
+}